@@ -19,7 +19,7 @@ import (
 var CmdDoor43MetadataGenerate = cli.Command{
 	Name:        "generate-door43-metadata",
 	Usage:       "Generate Door43 Metadata",
-	Description: "This is a command for generating door43 metadata, making sure all valid repos have metadata in the door43_metadata table.",
+	Description: "This is a command for generating door43 metadata, making sure all valid repos have metadata in the door43_metadata table and that it has a sortable version.",
 	Action:      runDoor43MetadataGenerate,
 }
 
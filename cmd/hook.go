@@ -239,7 +239,9 @@ Gitea or set your environment appropriately.`, "")
 				statusCode, msg := private.HookPreReceive(ctx, username, reponame, hookOptions)
 				switch statusCode {
 				case http.StatusOK:
-					// no-op
+					/*** DCS Customizations ***/
+					hookPrintUserMsg(msg)
+					/*** END DCS Customizations ***/
 				case http.StatusInternalServerError:
 					return fail("Internal Server Error", msg)
 				default:
@@ -266,6 +268,10 @@ Gitea or set your environment appropriately.`, "")
 
 		statusCode, msg := private.HookPreReceive(ctx, username, reponame, hookOptions)
 		switch statusCode {
+		/*** DCS Customizations ***/
+		case http.StatusOK:
+			hookPrintUserMsg(msg)
+		/*** END DCS Customizations ***/
 		case http.StatusInternalServerError:
 			return fail("Internal Server Error", msg)
 		case http.StatusForbidden:
@@ -431,6 +437,22 @@ Gitea or set your environment appropriately.`, "")
 	return nil
 }
 
+/*** DCS Customizations ***/
+
+// hookPrintUserMsg prints a message for the user of a push, such as a warning
+func hookPrintUserMsg(msg string) {
+	if msg == "" {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "")
+	for _, line := range strings.Split(strings.TrimRight(msg, "\n"), "\n") {
+		fmt.Fprintln(os.Stderr, "Gitea:", line)
+	}
+	fmt.Fprintln(os.Stderr, "")
+}
+
+/*** END DCS Customizations ***/
+
 func hookPrintResults(results []private.HookPostReceiveBranchResult) {
 	for _, res := range results {
 		if !res.Message {
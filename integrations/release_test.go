@@ -209,3 +209,25 @@ func TestViewTagsList(t *testing.T) {
 
 	assert.EqualValues(t, []string{"v1.0", "delete-tag", "v1.1"}, tagNames)
 }
+
+/*** DCS Customizations - Tests for regenerating release artifacts ***/
+
+func TestRegenerateReleaseArtifacts(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/releases/artifacts?id=1", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/releases"),
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1/releases", test.RedirectURL(resp))
+
+	// Only the admins of the instance may regenerate the artifacts of a release
+	session = loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/releases/artifacts?id=1", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/releases"),
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+/*** END DCS Customizations ***/
@@ -21,6 +21,9 @@ import (
 	_ "code.gitea.io/gitea/modules/markup/csv"
 	_ "code.gitea.io/gitea/modules/markup/markdown"
 	_ "code.gitea.io/gitea/modules/markup/orgmode"
+	/*** DCS Customizations ***/
+	_ "code.gitea.io/gitea/modules/markup/yaml"
+	/*** END DCS Customizations ***/
 
 	"github.com/urfave/cli"
 )
@@ -30,6 +30,9 @@ type Attachment struct {
 	DownloadCount int64              `xorm:"DEFAULT 0"`
 	Size          int64              `xorm:"DEFAULT 0"`
 	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	/*** DCS Customizations ***/
+	GeneratedFrom string `xorm:"VARCHAR(40)"` // commit a release artifact was generated from, empty if uploaded
+	/*** END DCS Customizations ***/
 }
 
 // IncreaseDownloadCount is update download count + 1
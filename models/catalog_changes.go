@@ -0,0 +1,91 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// CatalogChangesOptions holds the options of the listing of the production releases of the catalog in the
+// order they last changed
+type CatalogChangesOptions struct {
+	Languages []string
+	Subjects  []string
+	// From and Until bound the times the releases last changed, if not 0
+	From  timeutil.TimeStamp
+	Until timeutil.TimeStamp
+	// AfterUnix and AfterID are the change time and the ID of the last release of the previous page
+	AfterUnix timeutil.TimeStamp
+	AfterID   int64
+	PageSize  int
+}
+
+// catalogChangesCond is the condition of the production releases of the public repos matching the options,
+// whatever the page
+func catalogChangesCond(opts *CatalogChangesOptions) builder.Cond {
+	cond := builder.NewCond().And(
+		builder.Gt{"`door43_metadata`.release_id": 0},
+		builder.Eq{"`door43_metadata`.stage": StageProd},
+		GetLanguageCond(opts.Languages),
+		GetSubjectCond(opts.Subjects),
+		builder.Eq{"`repository`.is_private": false},
+		builder.Eq{"`repository`.is_archived": false})
+	if opts.From > 0 {
+		cond = cond.And(builder.Gte{"`door43_metadata`.updated_unix": opts.From})
+	}
+	if opts.Until > 0 {
+		cond = cond.And(builder.Lte{"`door43_metadata`.updated_unix": opts.Until})
+	}
+	return cond
+}
+
+// GetCatalogChanges returns a page of the production releases of the public repos in the catalog in the order
+// they last changed, those that changed at the same time by ID, with the number of them on all the pages
+func GetCatalogChanges(opts *CatalogChangesOptions) (Door43MetadataList, int64, error) {
+	cond := catalogChangesCond(opts)
+	count, err := x.Table("door43_metadata").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Where(cond).
+		Count(new(Door43Metadata))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	afterCond := builder.Or(
+		builder.Gt{"`door43_metadata`.updated_unix": opts.AfterUnix},
+		builder.And(
+			builder.Eq{"`door43_metadata`.updated_unix": opts.AfterUnix},
+			builder.Gt{"`door43_metadata`.id": opts.AfterID}))
+	sess := x.Table("door43_metadata").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Where(cond.And(afterCond)).
+		OrderBy("`door43_metadata`.updated_unix ASC, `door43_metadata`.id ASC")
+	if opts.PageSize > 0 {
+		sess.Limit(opts.PageSize)
+	}
+	dms := make(Door43MetadataList, 0, opts.PageSize)
+	if err := sess.Select("`door43_metadata`.*").Find(&dms); err != nil {
+		return nil, 0, err
+	}
+	return dms, count, dms.LoadAttributes()
+}
+
+// GetCatalogEarliestChange returns the time the production release of the public repos in the catalog that
+// changed the longest ago last changed, 0 if there are none
+func GetCatalogEarliestChange() (timeutil.TimeStamp, error) {
+	dm := new(Door43Metadata)
+	has, err := x.Table("door43_metadata").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Where(catalogChangesCond(&CatalogChangesOptions{})).
+		OrderBy("`door43_metadata`.updated_unix ASC").
+		Select("`door43_metadata`.*").
+		Get(dm)
+	if err != nil || !has {
+		return 0, err
+	}
+	return dm.UpdatedUnix, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogChanges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	// The table has no fixtures to be reset to
+	defer func() {
+		_, err := x.Where("id > 0").Delete(new(Door43Metadata))
+		assert.NoError(t, err)
+	}()
+
+	metadata := func(lang, subject string) *map[string]interface{} {
+		return &map[string]interface{}{
+			"dublin_core": map[string]interface{}{"language": map[string]interface{}{"identifier": lang}, "subject": subject},
+		}
+	}
+	dms := []*Door43Metadata{
+		{ID: 1, RepoID: 1, ReleaseID: 1, MetadataVersion: "rc0.2", Metadata: metadata("en", "Bible"), Stage: StageProd, BranchOrTag: "v1.1", UpdatedUnix: 300},
+		{ID: 2, RepoID: 1, ReleaseID: 3, MetadataVersion: "rc0.2", Metadata: metadata("en", "Bible"), Stage: StageProd, BranchOrTag: "delete-tag", UpdatedUnix: 100},
+		{ID: 3, RepoID: 1, ReleaseID: 5, MetadataVersion: "rc0.2", Metadata: metadata("fr", "Open Bible Stories"), Stage: StageProd, BranchOrTag: "v1.0", UpdatedUnix: 100},
+		// Neither pre-releases nor default branches are harvested
+		{ID: 4, RepoID: 1, ReleaseID: 4, MetadataVersion: "rc0.2", Metadata: metadata("en", "Bible"), Stage: StagePreProd, BranchOrTag: "draft-release", UpdatedUnix: 100},
+		{ID: 5, RepoID: 1, ReleaseID: 0, MetadataVersion: "rc0.2", Metadata: metadata("en", "Bible"), Stage: StageLatest, BranchOrTag: "master", UpdatedUnix: 100},
+	}
+	for _, dm := range dms {
+		_, err := x.NoAutoTime().Insert(dm)
+		assert.NoError(t, err)
+	}
+
+	ids := func(dms Door43MetadataList) []int64 {
+		ids := make([]int64, 0, len(dms))
+		for _, dm := range dms {
+			ids = append(ids, dm.ID)
+		}
+		return ids
+	}
+
+	page, count, err := GetCatalogChanges(&CatalogChangesOptions{PageSize: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Equal(t, []int64{2, 3}, ids(page))
+	assert.NotNil(t, page[0].Repo)
+
+	page, count, err = GetCatalogChanges(&CatalogChangesOptions{AfterUnix: 100, AfterID: 3, PageSize: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Equal(t, []int64{1}, ids(page))
+
+	page, count, err = GetCatalogChanges(&CatalogChangesOptions{Languages: []string{"en"}, From: 200})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Equal(t, []int64{1}, ids(page))
+
+	page, count, err = GetCatalogChanges(&CatalogChangesOptions{Subjects: []string{"open bible stories"}, Until: 200})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Equal(t, []int64{3}, ids(page))
+
+	earliest, err := GetCatalogEarliestChange()
+	assert.NoError(t, err)
+	assert.Equal(t, timeutil.TimeStamp(100), earliest)
+}
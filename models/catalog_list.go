@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"strings"
 
+	"code.gitea.io/gitea/modules/dcs"
+
 	"xorm.io/builder"
+	"xorm.io/xorm"
 )
 
 //CatalogOrderBy is used to sort the result
@@ -26,6 +29,8 @@ const (
 	CatalogOrderBySubjectReverse  CatalogOrderBy = "JSON_EXTRACT(`door43_metadata`.metadata, '$.dublin_core.subject') DESC"
 	CatalogOrderByTag             CatalogOrderBy = "CAST(TRIM(LEADING 'v' FROM `release`.tag_name) AS unsigned) ASC, `door43_metadata`.branch_or_tag ASC, `door43_metadata`.release_date_unix ASC"
 	CatalogOrderByTagReverse      CatalogOrderBy = "CAST(TRIM(LEADING 'v' FROM `release`.tag_name) AS unsigned) DESC, `door43_metadata`.branch_or_tag DESC, `door43_metadata`.release_date_unix DESC"
+	CatalogOrderByVersion         CatalogOrderBy = "`door43_metadata`.sortable_version ASC, `door43_metadata`.release_date_unix ASC"
+	CatalogOrderByVersionReverse  CatalogOrderBy = "`door43_metadata`.sortable_version DESC, `door43_metadata`.release_date_unix DESC"
 	CatalogOrderByLangCode        CatalogOrderBy = "JSON_EXTRACT(`door43_metadata`.metadata, '$.dublin_core.language.identifier') ASC"
 	CatalogOrderByLangCodeReverse CatalogOrderBy = "JSON_EXTRACT(`door43_metadata`.metadata, '$.dublin_core.language.identifier') DESC"
 	CatalogOrderByOldest          CatalogOrderBy = "`door43_metadata`.release_date_unix ASC"
@@ -103,6 +108,7 @@ type SearchCatalogOptions struct {
 	Subjects        []string
 	CheckingLevels  []string
 	Books           []string
+	Versions        dcs.VersionConstraints
 	IncludeHistory  bool
 	IncludeMetadata bool
 	ShowIngredients bool
@@ -139,6 +145,7 @@ func SearchCatalogCondition(opts *SearchCatalogOptions) builder.Cond {
 		GetLanguageCond(opts.Languages),
 		GetCheckingLevelCond(opts.CheckingLevels),
 		GetTagCond(opts.Tags),
+		GetVersionCond(opts.Versions),
 		repoCond,
 		ownerCond,
 		stageCond,
@@ -172,30 +179,10 @@ func SearchCatalogByCondition(opts *SearchCatalogOptions, cond builder.Cond, loa
 
 	dms := make(Door43MetadataList, 0, opts.PageSize)
 
-	releaseInfoInner, err := builder.Select("`door43_metadata`.repo_id", "COUNT(*) AS release_count", "MAX(`door43_metadata`.release_date_unix) AS latest_unix").
-		From("door43_metadata").
-		GroupBy("`door43_metadata`.repo_id").
-		Where(GetStageCond(opts.Stage)).
-		ToBoundSQL()
-	if err != nil {
-		return nil, 0, err
-	}
-
-	releaseInfoOuter, err := builder.Select("`door43_metadata`.repo_id", "MAX(release_count) AS release_count", "MAX(latest_unix) AS latest_unix", "MIN(stage) AS latest_stage").
-		From("door43_metadata").
-		Join("INNER", "("+releaseInfoInner+") release_info_inner", "`release_info_inner`.repo_id = `door43_metadata`.repo_id AND `door43_metadata`.release_date_unix = `release_info_inner`.latest_unix").
-		GroupBy("`door43_metadata`.repo_id").
-		ToBoundSQL()
-	if err != nil {
+	if err := joinCatalogTables(sess, opts); err != nil {
 		return nil, 0, err
 	}
-
-	sess.
-		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
-		Join("INNER", "user", "`repository`.owner_id = `user`.id").
-		Join("LEFT", "release", "`release`.id = `door43_metadata`.release_id").
-		Join("INNER", "("+releaseInfoOuter+") release_info", "release_info.repo_id = `door43_metadata`.repo_id").
-		Where(cond)
+	sess.Where(cond)
 
 	for _, orderBy := range opts.OrderBy {
 		sess.OrderBy(orderBy.String())
@@ -218,6 +205,36 @@ func SearchCatalogByCondition(opts *SearchCatalogOptions, cond builder.Cond, loa
 	return dms, count, nil
 }
 
+// joinCatalogTables joins the tables that the conditions of SearchCatalogCondition refer to, including the
+// release count, latest release date and latest stage of each repo
+func joinCatalogTables(sess *xorm.Session, opts *SearchCatalogOptions) error {
+	releaseInfoInner, err := builder.Select("`door43_metadata`.repo_id", "COUNT(*) AS release_count", "MAX(`door43_metadata`.release_date_unix) AS latest_unix").
+		From("door43_metadata").
+		GroupBy("`door43_metadata`.repo_id").
+		Where(builder.And(GetStageCond(opts.Stage), GetVersionCond(opts.Versions))).
+		ToBoundSQL()
+	if err != nil {
+		return err
+	}
+
+	releaseInfoOuter, err := builder.Select("`door43_metadata`.repo_id", "MAX(release_count) AS release_count", "MAX(latest_unix) AS latest_unix", "MIN(stage) AS latest_stage").
+		From("door43_metadata").
+		Join("INNER", "("+releaseInfoInner+") release_info_inner", "`release_info_inner`.repo_id = `door43_metadata`.repo_id AND `door43_metadata`.release_date_unix = `release_info_inner`.latest_unix").
+		Where(GetVersionCond(opts.Versions)).
+		GroupBy("`door43_metadata`.repo_id").
+		ToBoundSQL()
+	if err != nil {
+		return err
+	}
+
+	sess.
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Join("INNER", "user", "`repository`.owner_id = `user`.id").
+		Join("LEFT", "release", "`release`.id = `door43_metadata`.release_id").
+		Join("INNER", "("+releaseInfoOuter+") release_info", "release_info.repo_id = `door43_metadata`.repo_id")
+	return nil
+}
+
 // SplitAtCommaNotInString split s at commas, ignoring commas in strings.
 func SplitAtCommaNotInString(s string, requireSpaceAfterComma bool) []string {
 	var res []string
@@ -317,6 +334,39 @@ func GetTagCond(tags []string) builder.Cond {
 	return tagCond
 }
 
+// GetVersionCond gets the condition that the entry's version satisfies all of the given constraints
+func GetVersionCond(constraints dcs.VersionConstraints) builder.Cond {
+	var versionCond = builder.NewCond()
+	if len(constraints) == 0 {
+		return versionCond
+	}
+	versionCond = versionCond.And(builder.Neq{"`door43_metadata`.sortable_version": ""})
+	for _, c := range constraints {
+		sortable := dcs.GetSortableVersion(c.Version)
+		switch c.Operator {
+		case "=":
+			versionCond = versionCond.And(builder.Eq{"`door43_metadata`.sortable_version": sortable})
+		case "!=":
+			versionCond = versionCond.And(builder.Neq{"`door43_metadata`.sortable_version": sortable})
+		case ">":
+			versionCond = versionCond.And(builder.Gt{"`door43_metadata`.sortable_version": sortable})
+		case ">=":
+			versionCond = versionCond.And(builder.Gte{"`door43_metadata`.sortable_version": sortable})
+		case "<":
+			versionCond = versionCond.And(builder.Lt{"`door43_metadata`.sortable_version": sortable})
+		case "<=":
+			versionCond = versionCond.And(builder.Lte{"`door43_metadata`.sortable_version": sortable})
+		case "~>", "^":
+			lower, upper := c.Range()
+			versionCond = versionCond.And(builder.Gte{"`door43_metadata`.sortable_version": lower})
+			if upper != "" {
+				versionCond = versionCond.And(builder.Lt{"`door43_metadata`.sortable_version": upper})
+			}
+		}
+	}
+	return versionCond
+}
+
 // GetRepoCond gets the repo condition
 func GetRepoCond(repos []string) builder.Cond {
 	var repoCond = builder.NewCond()
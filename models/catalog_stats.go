@@ -0,0 +1,221 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Expressions of the fields of the metadata that the catalog statistics are grouped by
+const (
+	catalogStatsLanguageExpr      = "LOWER(REPLACE(JSON_EXTRACT(`door43_metadata`.metadata, '$.dublin_core.language.identifier'), '\"', ''))"
+	catalogStatsSubjectExpr       = "REPLACE(JSON_EXTRACT(`door43_metadata`.metadata, '$.dublin_core.subject'), '\"', '')"
+	catalogStatsCheckingLevelExpr = "REPLACE(JSON_EXTRACT(`door43_metadata`.metadata, '$.checking.checking_level'), '\"', '')"
+)
+
+// Kinds of catalog statistics
+const (
+	CatalogStatsKindTotal         = "total"
+	CatalogStatsKindLanguage      = "language"
+	CatalogStatsKindSubject       = "subject"
+	CatalogStatsKindCheckingLevel = "checking_level"
+)
+
+// catalogStatsExprs are the expressions each kind of catalog statistics is grouped by
+var catalogStatsExprs = map[string]string{
+	CatalogStatsKindTotal:         "",
+	CatalogStatsKindLanguage:      catalogStatsLanguageExpr,
+	CatalogStatsKindSubject:       catalogStatsSubjectExpr,
+	CatalogStatsKindCheckingLevel: catalogStatsCheckingLevelExpr,
+}
+
+// CatalogStatsCount is the number of catalog entries with a value of a field, such as the language en
+type CatalogStatsCount struct {
+	Value string
+	Count int64
+}
+
+// CatalogStats are the statistics of the latest catalog entries of the repos at a stage
+type CatalogStats struct {
+	Stage          Stage
+	Total          int64
+	Languages      []*CatalogStatsCount
+	Subjects       []*CatalogStatsCount
+	CheckingLevels []*CatalogStatsCount
+	// ReleasesPerMonth is the number of releases of each month, e.g. 2021-03, oldest first
+	ReleasesPerMonth []*CatalogStatsCount
+	// Stale is the latest entries released before StaleBefore, oldest first
+	Stale       Door43MetadataList
+	StaleCount  int64
+	StaleBefore timeutil.TimeStamp
+}
+
+// CatalogStatsOptions holds the options of the catalog statistics
+type CatalogStatsOptions struct {
+	Stage Stage
+	// Months is the number of months, including the current one, of the releases per month
+	Months int
+	// StaleAfter is how long after its latest release an entry is stale
+	StaleAfter time.Duration
+	// StalePageSize is the maximum number of stale entries to load, all of them being counted
+	StalePageSize int
+}
+
+// CatalogStatsSnapshot is a catalog statistic as recorded at a given time, for following the catalog over time
+type CatalogStatsSnapshot struct {
+	ID          int64              `xorm:"pk autoincr"`
+	Kind        string             `xorm:"INDEX NOT NULL"`
+	Value       string             `xorm:"INDEX"`
+	Count       int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// countCatalogBy counts the latest catalog entries of the repos at a stage by the value of an expression,
+// or all of them if the expression is empty
+func countCatalogBy(stage Stage, expr string) ([]*CatalogStatsCount, error) {
+	opts := &SearchCatalogOptions{Stage: stage}
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := joinCatalogTables(sess, opts); err != nil {
+		return nil, err
+	}
+	sess.Table("door43_metadata").Where(SearchCatalogCondition(opts))
+
+	counts := make([]*CatalogStatsCount, 0, 10)
+	if expr == "" {
+		count, err := sess.Count(new(Door43Metadata))
+		if err != nil {
+			return nil, err
+		}
+		return append(counts, &CatalogStatsCount{Count: count}), nil
+	}
+	return counts, sess.
+		Select(expr + " AS value, COUNT(DISTINCT `door43_metadata`.repo_id) AS count").
+		GroupBy(expr).
+		OrderBy("count DESC, value ASC").
+		Find(&counts)
+}
+
+// countReleasesPerMonth counts the releases at a stage of the public repos for each of the last months
+func countReleasesPerMonth(stage Stage, months int) ([]*CatalogStatsCount, error) {
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, now.Location())
+
+	var dates []int64
+	if err := x.Table("door43_metadata").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Where(builder.And(
+			builder.Gt{"`door43_metadata`.release_id": 0},
+			GetStageCond(stage),
+			builder.Gte{"`door43_metadata`.release_date_unix": first.Unix()},
+			builder.Eq{"`repository`.is_private": false},
+			builder.Eq{"`repository`.is_archived": false})).
+		Cols("`door43_metadata`.release_date_unix").
+		Find(&dates); err != nil {
+		return nil, err
+	}
+
+	counts := make([]*CatalogStatsCount, months)
+	index := make(map[string]*CatalogStatsCount, months)
+	for i := range counts {
+		month := first.AddDate(0, i, 0).Format("2006-01")
+		counts[i] = &CatalogStatsCount{Value: month}
+		index[month] = counts[i]
+	}
+	for _, date := range dates {
+		if count, ok := index[time.Unix(date, 0).In(now.Location()).Format("2006-01")]; ok {
+			count.Count++
+		}
+	}
+	return counts, nil
+}
+
+// GetCatalogCounts returns the number of repos whose latest catalog entry at a stage has each language, subject
+// or checking level, the most common first, or their total number for CatalogStatsKindTotal
+func GetCatalogCounts(stage Stage, kind string) ([]*CatalogStatsCount, error) {
+	expr, ok := catalogStatsExprs[kind]
+	if !ok {
+		return nil, fmt.Errorf("invalid catalog stats kind: %s", kind)
+	}
+	return countCatalogBy(stage, expr)
+}
+
+// GetCatalogStats returns the statistics of the latest catalog entries of the public repos at a stage
+func GetCatalogStats(opts *CatalogStatsOptions) (*CatalogStats, error) {
+	if opts.Months <= 0 {
+		opts.Months = 12
+	}
+	stats := &CatalogStats{
+		Stage:       opts.Stage,
+		StaleBefore: timeutil.TimeStamp(time.Now().Add(-opts.StaleAfter).Unix()),
+	}
+
+	total, err := countCatalogBy(opts.Stage, "")
+	if err != nil {
+		return nil, fmt.Errorf("count total: %v", err)
+	}
+	stats.Total = total[0].Count
+	if stats.Languages, err = countCatalogBy(opts.Stage, catalogStatsLanguageExpr); err != nil {
+		return nil, fmt.Errorf("count languages: %v", err)
+	}
+	if stats.Subjects, err = countCatalogBy(opts.Stage, catalogStatsSubjectExpr); err != nil {
+		return nil, fmt.Errorf("count subjects: %v", err)
+	}
+	if stats.CheckingLevels, err = countCatalogBy(opts.Stage, catalogStatsCheckingLevelExpr); err != nil {
+		return nil, fmt.Errorf("count checking levels: %v", err)
+	}
+	if stats.ReleasesPerMonth, err = countReleasesPerMonth(opts.Stage, opts.Months); err != nil {
+		return nil, fmt.Errorf("count releases per month: %v", err)
+	}
+
+	// Only releases get stale, the default branch of a repo is always up to date
+	staleOpts := &SearchCatalogOptions{
+		ListOptions: ListOptions{Page: 1, PageSize: opts.StalePageSize},
+		Stage:       opts.Stage,
+		OrderBy:     []CatalogOrderBy{CatalogOrderByOldest},
+	}
+	staleCond := SearchCatalogCondition(staleOpts).
+		And(builder.Gt{"`door43_metadata`.release_id": 0}).
+		And(builder.Lt{"`door43_metadata`.release_date_unix": stats.StaleBefore})
+	if stats.Stale, stats.StaleCount, err = SearchCatalogByCondition(staleOpts, staleCond, true); err != nil {
+		return nil, fmt.Errorf("search stale: %v", err)
+	}
+	return stats, nil
+}
+
+// RecordCatalogStats records the number of the latest production catalog entries, in total and by language,
+// subject and checking level
+func RecordCatalogStats() error {
+	var snapshots []*CatalogStatsSnapshot
+	for kind, expr := range catalogStatsExprs {
+		counts, err := countCatalogBy(StageProd, expr)
+		if err != nil {
+			return fmt.Errorf("count %s: %v", kind, err)
+		}
+		for _, count := range counts {
+			snapshots = append(snapshots, &CatalogStatsSnapshot{Kind: kind, Value: count.Value, Count: count.Count})
+		}
+	}
+	_, err := x.Insert(&snapshots)
+	return err
+}
+
+// GetCatalogStatsHistory returns the recorded statistics of a kind since a time, oldest first,
+// only those of the value if it is given
+func GetCatalogStatsHistory(kind, value string, since timeutil.TimeStamp) ([]*CatalogStatsSnapshot, error) {
+	cond := builder.NewCond().
+		And(builder.Eq{"kind": kind}).
+		And(builder.Gte{"created_unix": since})
+	if value != "" {
+		cond = cond.And(builder.Eq{"value": value})
+	}
+	snapshots := make([]*CatalogStatsSnapshot, 0, 10)
+	return snapshots, x.Where(cond).Asc("created_unix", "id").Find(&snapshots)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	// Neither table has fixtures to be reset to
+	defer func() {
+		_, err := x.Where("id > 0").Delete(new(Door43Metadata))
+		assert.NoError(t, err)
+		_, err = x.Where("id > 0").Delete(new(CatalogStatsSnapshot))
+		assert.NoError(t, err)
+	}()
+
+	metadata := func(lang, subject, checkingLevel string) *map[string]interface{} {
+		return &map[string]interface{}{
+			"dublin_core": map[string]interface{}{"language": map[string]interface{}{"identifier": lang}, "subject": subject},
+			"checking":    map[string]interface{}{"checking_level": checkingLevel},
+		}
+	}
+	recent := timeutil.TimeStamp(time.Now().AddDate(0, 0, -1).Unix())
+	old := timeutil.TimeStamp(time.Now().AddDate(-3, 0, 0).Unix())
+	dms := []*Door43Metadata{
+		{RepoID: 1, ReleaseID: 1, MetadataVersion: "rc0.2", Metadata: metadata("en", "Bible", "3"), Stage: StageProd, BranchOrTag: "v1.1", ReleaseDateUnix: old},
+		{RepoID: 50, ReleaseID: 0, MetadataVersion: "rc0.2", Metadata: metadata("fr", "Bible", "1"), Stage: StageLatest, BranchOrTag: "master", ReleaseDateUnix: recent},
+		// Private repos aren't in the catalog
+		{RepoID: 2, ReleaseID: 0, MetadataVersion: "rc0.2", Metadata: metadata("en", "Translation Notes", "1"), Stage: StageLatest, BranchOrTag: "master", ReleaseDateUnix: recent},
+	}
+	for _, dm := range dms {
+		_, err := x.Insert(dm)
+		assert.NoError(t, err)
+	}
+
+	stats, err := GetCatalogStats(&CatalogStatsOptions{Stage: StageProd, Months: 3, StaleAfter: 365 * 24 * time.Hour, StalePageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Total)
+	assert.Equal(t, []*CatalogStatsCount{{Value: "en", Count: 1}}, stats.Languages)
+	assert.Equal(t, []*CatalogStatsCount{{Value: "Bible", Count: 1}}, stats.Subjects)
+	assert.Equal(t, []*CatalogStatsCount{{Value: "3", Count: 1}}, stats.CheckingLevels)
+	assert.Len(t, stats.ReleasesPerMonth, 3)
+	assert.Equal(t, time.Now().Format("2006-01"), stats.ReleasesPerMonth[2].Value)
+	assert.EqualValues(t, 1, stats.StaleCount)
+	if assert.Len(t, stats.Stale, 1) {
+		assert.EqualValues(t, 1, stats.Stale[0].RepoID)
+	}
+
+	stats, err = GetCatalogStats(&CatalogStatsOptions{Stage: StageLatest, StaleAfter: 365 * 24 * time.Hour})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, stats.Total)
+	assert.Equal(t, []*CatalogStatsCount{{Value: "en", Count: 1}, {Value: "fr", Count: 1}}, stats.Languages)
+	assert.Equal(t, []*CatalogStatsCount{{Value: "Bible", Count: 2}}, stats.Subjects)
+	assert.Len(t, stats.ReleasesPerMonth, 12)
+
+	counts, err := GetCatalogCounts(StageLatest, CatalogStatsKindSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, stats.Subjects, counts)
+	_, err = GetCatalogCounts(StageLatest, "book")
+	assert.Error(t, err)
+
+	assert.NoError(t, RecordCatalogStats())
+	history, err := GetCatalogStatsHistory(CatalogStatsKindLanguage, "en", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.EqualValues(t, 1, history[0].Count)
+	}
+
+	user30 := AssertExistsAndLoadBean(t, &User{ID: 30}).(*User)
+	assert.Equal(t, []string{"fr"}, user30.GetRepoLanguages())
+	assert.Equal(t, []string{"Bible"}, user30.GetRepoSubjects())
+}
@@ -7,8 +7,10 @@ package models
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -29,9 +31,14 @@ type Door43Metadata struct {
 	Metadata        *map[string]interface{} `xorm:"JSON NOT NULL"`
 	Stage           Stage                   `xorm:"NOT NULL"`
 	BranchOrTag     string                  `xorm:"NOT NULL"`
+	SortableVersion string                  `xorm:"INDEX"`
 	ReleaseDateUnix timeutil.TimeStamp      `xorm:"NOT NULL"`
 	CreatedUnix     timeutil.TimeStamp      `xorm:"INDEX created NOT NULL"`
 	UpdatedUnix     timeutil.TimeStamp      `xorm:"INDEX updated"`
+	// Warnings are the inconsistencies of the metadata, e.g. with the name of the repository or the release tag
+	Warnings []string `xorm:"JSON"`
+	// BookProgresses are the progresses of the books of a door43 metadata that is not saved, e.g. a preview
+	BookProgresses BookProgressList `xorm:"-"`
 }
 
 // GetRepo gets the repo associated with the door43 metadata entry
@@ -173,6 +180,38 @@ func (dm *Door43Metadata) GetMetadataAPIContentsURL() string {
 	return fmt.Sprintf("%s/contents/manifest.yaml?ref=%s", dm.Repo.APIURL(), dm.BranchOrTag)
 }
 
+// HasChecksums returns whether the files of the metadata have checksums, only those of a release that is not a
+// draft having them, computed when first needed
+func (dm *Door43Metadata) HasChecksums() bool {
+	return dm.ReleaseID > 0 && dm.Stage != StageDraft
+}
+
+// GetChecksumsURL gets the url to the SHA-256 checksums of the files of the release, empty if it has none
+func (dm *Door43Metadata) GetChecksumsURL() string {
+	if !dm.HasChecksums() {
+		return ""
+	}
+	return fmt.Sprintf("%s/checksums", dm.APIURLV5())
+}
+
+// GetChecksumsSignatureURL gets the url to the signature of the checksums of the release, empty if it has none
+// or the instance does not sign
+func (dm *Door43Metadata) GetChecksumsSignatureURL() string {
+	if !dm.HasChecksums() || setting.Repository.Signing.SigningKey == "none" {
+		return ""
+	}
+	return fmt.Sprintf("%s/checksums.asc", dm.APIURLV5())
+}
+
+// GetSigningKeyURL gets the url to the public key the checksums of the release are signed with, empty if
+// they are not signed
+func (dm *Door43Metadata) GetSigningKeyURL() string {
+	if dm.GetChecksumsSignatureURL() == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/signing-key.gpg", dm.Repo.APIURL())
+}
+
 // GetBooks get the books of the resource
 func (dm *Door43Metadata) GetBooks() []string {
 	var books []string
@@ -372,6 +411,31 @@ func getDoor43MetadataByRepoIDAndReleaseID(e Engine, repoID, releaseID int64) (*
 	return dm, err
 }
 
+// GetDoor43MetadataByRepoIDAndVersion returns the metadata of a given repo ID with the highest version that
+// satisfies the given version constraints and is at or below the given stage.
+func GetDoor43MetadataByRepoIDAndVersion(repoID int64, stage Stage, constraints dcs.VersionConstraints) (*Door43Metadata, error) {
+	return getDoor43MetadataByRepoIDAndVersion(x, repoID, stage, constraints)
+}
+
+func getDoor43MetadataByRepoIDAndVersion(e Engine, repoID int64, stage Stage, constraints dcs.VersionConstraints) (*Door43Metadata, error) {
+	var cond = builder.NewCond().
+		And(builder.Eq{"`door43_metadata`.repo_id": repoID}).
+		And(builder.Neq{"`door43_metadata`.sortable_version": ""}).
+		And(GetStageCond(stage)).
+		And(GetVersionCond(constraints))
+
+	dm := &Door43Metadata{}
+	has, err := e.Where(cond).
+		Desc("`door43_metadata`.sortable_version", "`door43_metadata`.release_date_unix").
+		Get(dm)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDoor43MetadataNotExist{0, repoID, 0}
+	}
+	return dm, nil
+}
+
 // GetDoor43MetadataByRepoIDAndStage returns the metadata of a given repo ID and stage.
 func GetDoor43MetadataByRepoIDAndStage(repoID int64, stage Stage) (*Door43Metadata, error) {
 	return getDoor43MetadataByRepoIDAndStage(x, repoID, stage)
@@ -428,6 +492,12 @@ func DeleteDoor43MetadataByID(id int64) error {
 // DeleteDoor43Metadata deletes a metadata from database by given ID.
 func DeleteDoor43Metadata(dm *Door43Metadata) error {
 	id, err := x.Delete(dm)
+	if err == nil {
+		err = DeleteBookProgresses(dm.RepoID, dm.ReleaseID)
+	}
+	if err == nil {
+		err = deleteDoor43MetadataChecksums(x, builder.Eq{"door43_metadata_id": dm.ID})
+	}
 	if id > 0 && dm.ReleaseID > 0 {
 		if err := dm.LoadAttributes(); err != nil {
 			return err
@@ -447,12 +517,24 @@ func DeleteDoor43MetadataByRelease(release *Release) error {
 		}
 		return nil
 	}
-	_, err = x.ID(dm.ID).Delete(dm)
-	return err
+	if _, err = x.ID(dm.ID).Delete(dm); err != nil {
+		return err
+	}
+	if err := deleteDoor43MetadataChecksums(x, builder.Eq{"door43_metadata_id": dm.ID}); err != nil {
+		return err
+	}
+	return DeleteBookProgresses(dm.RepoID, dm.ReleaseID)
 }
 
 // DeleteAllDoor43MetadatasByRepoID deletes all metadatas from database for a repo by given repo ID.
 func DeleteAllDoor43MetadatasByRepoID(repoID int64) (int64, error) {
+	if _, err := x.Delete(&BookProgress{RepoID: repoID}); err != nil {
+		return 0, err
+	}
+	if err := deleteDoor43MetadataChecksums(x, builder.In("door43_metadata_id",
+		builder.Select("id").From("door43_metadata").Where(builder.Eq{"repo_id": repoID}))); err != nil {
+		return 0, err
+	}
 	return x.Delete(Door43Metadata{RepoID: repoID})
 }
 
@@ -613,6 +695,22 @@ func (err ErrInvalidRelease) Error() string {
 	return fmt.Sprintf("metadata release id is not valid [release_id: %d]", err.ReleaseID)
 }
 
+// ErrInconsistentManifest represents a "InconsistentManifest" kind of error.
+type ErrInconsistentManifest struct {
+	TagName string
+	Issues  []string
+}
+
+// IsErrInconsistentManifest checks if an error is a ErrInconsistentManifest.
+func IsErrInconsistentManifest(err error) bool {
+	_, ok := err.(ErrInconsistentManifest)
+	return ok
+}
+
+func (err ErrInconsistentManifest) Error() string {
+	return fmt.Sprintf("manifest.yaml is not consistent with the repo name and release [tag_name: %s]: %s", err.TagName, strings.Join(err.Issues, "; "))
+}
+
 /*** END Error Structs & Functions ***/
 
 /*** Stage ***/
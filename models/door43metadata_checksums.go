@@ -0,0 +1,59 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Door43MetadataChecksums are the SHA-256 checksums of the files of the commit of a door43 metadata's release in
+// the format of sha256sum, signed with the ASCII armored detached Signature by the SigningKey if the instance has
+// a signing key. They are kept apart from the metadata so as not to be loaded with every catalog entry.
+type Door43MetadataChecksums struct {
+	ID               int64  `xorm:"pk autoincr"`
+	Door43MetadataID int64  `xorm:"UNIQUE NOT NULL"`
+	CommitSHA        string `xorm:"VARCHAR(40) NOT NULL"`
+	Checksums        string `xorm:"TEXT NOT NULL"`
+	Signature        string `xorm:"TEXT"`
+	SigningKey       string
+	CreatedUnix      timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix      timeutil.TimeStamp `xorm:"updated"`
+}
+
+// GetDoor43MetadataChecksums returns the checksums of a door43 metadata, nil if they have not been computed
+func GetDoor43MetadataChecksums(dmID int64) (*Door43MetadataChecksums, error) {
+	dmc := &Door43MetadataChecksums{}
+	has, err := x.Where("door43_metadata_id = ?", dmID).Get(dmc)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return dmc, nil
+}
+
+// SaveDoor43MetadataChecksums inserts the checksums of a door43 metadata, or replaces those it has
+func SaveDoor43MetadataChecksums(dmc *Door43MetadataChecksums) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Where("door43_metadata_id = ?", dmc.Door43MetadataID).Delete(new(Door43MetadataChecksums)); err != nil {
+		return err
+	}
+	dmc.ID = 0
+	if _, err := sess.Insert(dmc); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func deleteDoor43MetadataChecksums(e Engine, cond builder.Cond) error {
+	_, err := e.Where(cond).Delete(new(Door43MetadataChecksums))
+	return err
+}
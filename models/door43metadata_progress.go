@@ -0,0 +1,112 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// BookProgress is the translation progress of a book of a repository's release or default branch (ReleaseID = 0):
+// the number of verses of its USFM file with text, of the verses of the book in the versification
+type BookProgress struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"INDEX UNIQUE(s) NOT NULL"`
+	ReleaseID   int64              `xorm:"INDEX UNIQUE(s)"`
+	Book        string             `xorm:"UNIQUE(s) NOT NULL"`
+	Path        string             `xorm:"NOT NULL"`
+	Verses      int                `xorm:"NOT NULL DEFAULT 0"`
+	TotalVerses int                `xorm:"NOT NULL DEFAULT 0"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// Percent returns the percentage of the verses of the book with text, rounded down
+func (bp *BookProgress) Percent() int {
+	if bp.TotalVerses <= 0 {
+		return 0
+	}
+	return bp.Verses * 100 / bp.TotalVerses
+}
+
+// BookName returns the name of the book, e.g. Titus
+func (bp *BookProgress) BookName() string {
+	if book := dcs.GetBook(bp.Book); book != nil {
+		return book.Name
+	}
+	return strings.ToUpper(bp.Book)
+}
+
+// BookProgressList is a list of the progress of books
+type BookProgressList []*BookProgress
+
+// Total returns the progress of all the books of the list together
+func (bps BookProgressList) Total() *BookProgress {
+	total := &BookProgress{}
+	for _, bp := range bps {
+		total.Verses += bp.Verses
+		total.TotalVerses += bp.TotalVerses
+	}
+	return total
+}
+
+// GetBookProgresses returns the progress of the books of a release (0 = default branch) of a repo,
+// in the order of the Bible
+func GetBookProgresses(repoID, releaseID int64) (BookProgressList, error) {
+	bps := make(BookProgressList, 0, 10)
+	if err := x.Where("repo_id = ? AND release_id = ?", repoID, releaseID).Find(&bps); err != nil {
+		return nil, err
+	}
+	bookSort := func(bp *BookProgress) int {
+		if book := dcs.GetBook(bp.Book); book != nil {
+			return book.Sort
+		}
+		return len(dcs.Books) + 1
+	}
+	sort.SliceStable(bps, func(i, j int) bool {
+		return bookSort(bps[i]) < bookSort(bps[j])
+	})
+	return bps, nil
+}
+
+// GetBookProgresses returns the progress of the books of the release or default branch of the metadata, or the
+// progresses it was given if it is not saved
+func (dm *Door43Metadata) GetBookProgresses() (BookProgressList, error) {
+	if dm.BookProgresses != nil {
+		return dm.BookProgresses, nil
+	}
+	return GetBookProgresses(dm.RepoID, dm.ReleaseID)
+}
+
+// UpdateBookProgresses replaces the progress of the books of a release (0 = default branch) of a repo
+func UpdateBookProgresses(repoID, releaseID int64, bps []*BookProgress) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if _, err := sess.Where("repo_id = ? AND release_id = ?", repoID, releaseID).Delete(new(BookProgress)); err != nil {
+		return err
+	}
+	for _, bp := range bps {
+		bp.ID = 0
+		bp.RepoID = repoID
+		bp.ReleaseID = releaseID
+	}
+	if len(bps) > 0 {
+		if _, err := sess.Insert(&bps); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// DeleteBookProgresses deletes the progress of the books of a release (0 = default branch) of a repo
+func DeleteBookProgresses(repoID, releaseID int64) error {
+	_, err := x.Where("repo_id = ? AND release_id = ?", repoID, releaseID).Delete(new(BookProgress))
+	return err
+}
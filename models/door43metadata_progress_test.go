@@ -0,0 +1,58 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookProgresses(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	// The table has no fixtures to be reset to
+	defer func() {
+		_, err := x.Where("id > 0").Delete(new(BookProgress))
+		assert.NoError(t, err)
+	}()
+
+	assert.NoError(t, UpdateBookProgresses(1, 0, []*BookProgress{
+		{Book: "tit", Path: "57-TIT.usfm", Verses: 23, TotalVerses: 46},
+		{Book: "gen", Path: "01-GEN.usfm", Verses: 0, TotalVerses: 1533},
+	}))
+	assert.NoError(t, UpdateBookProgresses(1, 3, []*BookProgress{
+		{Book: "tit", Path: "57-TIT.usfm", Verses: 46, TotalVerses: 46},
+	}))
+
+	bps, err := GetBookProgresses(1, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, bps, 2) {
+		assert.Equal(t, "gen", bps[0].Book)
+		assert.Equal(t, "Titus", bps[1].BookName())
+		assert.Equal(t, 50, bps[1].Percent())
+	}
+	total := bps.Total()
+	assert.Equal(t, 23, total.Verses)
+	assert.Equal(t, 1579, total.TotalVerses)
+	assert.Equal(t, 1, total.Percent())
+
+	// Updating a ref replaces all of its books, leaving the other refs as they are
+	assert.NoError(t, UpdateBookProgresses(1, 0, []*BookProgress{
+		{Book: "tit", Path: "57-TIT.usfm", Verses: 30, TotalVerses: 46},
+	}))
+	bps, err = GetBookProgresses(1, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, bps, 1) {
+		assert.Equal(t, 30, bps[0].Verses)
+	}
+
+	assert.NoError(t, DeleteBookProgresses(1, 0))
+	bps, err = GetBookProgresses(1, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, bps)
+	bps, err = GetBookProgresses(1, 3)
+	assert.NoError(t, err)
+	assert.Len(t, bps, 1)
+}
@@ -129,6 +129,12 @@ func init() {
 		new(LanguageStat),
 		new(EmailHash),
 		new(Door43Metadata),
+		new(Door43MetadataChecksums),
+		new(ScrubRules),
+		new(ScrubLog),
+		new(SensitiveData),
+		new(CatalogStatsSnapshot),
+		new(BookProgress),
 		new(UserRedirect),
 		new(Project),
 		new(ProjectBoard),
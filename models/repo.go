@@ -248,8 +248,9 @@ type Repository struct {
 	Avatar string `xorm:"VARCHAR(64)"`
 
 	/*** DCS Customizations ***/
-	Metadata *map[string]interface{} `xorm:-`
-	/*** DCS Customizations ***/
+	CatalogMetadata    map[Stage]*Door43Metadata `xorm:"-"` // latest catalog entry of each stage, see LoadCatalogMetadata()
+	SensitiveDataCheck SensitiveDataCheckType    `xorm:"NOT NULL DEFAULT 0"`
+	/*** END DCS Customizations ***/
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -1529,6 +1530,19 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	/*** DCS Customizations ***/
+	if _, err := sess.Delete(&SensitiveData{RepoID: repoID}); err != nil {
+		return fmt.Errorf("delete sensitive data: %v", err)
+	}
+	if _, err := sess.Delete(&BookProgress{RepoID: repoID}); err != nil {
+		return fmt.Errorf("delete book progress: %v", err)
+	}
+	if err := deleteDoor43MetadataChecksums(sess, builder.In("door43_metadata_id",
+		builder.Select("id").From("door43_metadata").Where(builder.Eq{"repo_id": repoID}))); err != nil {
+		return fmt.Errorf("delete door43 metadata checksums: %v", err)
+	}
+	/*** END DCS Customizations ***/
+
 	// Delete Labels and related objects
 	if err := deleteLabelsByRepoID(sess, repoID); err != nil {
 		return err
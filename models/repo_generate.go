@@ -28,6 +28,12 @@ type GenerateRepoOptions struct {
 	Webhooks    bool
 	Avatar      bool
 	IssueLabels bool
+	/*** DCS Customizations ***/
+	// Ref is the branch or tag of the template repo to generate the git content from, its default branch if empty
+	Ref string
+	// ContentTransform, if set, changes the git content in its temporary directory before it is committed
+	ContentTransform func(tmpDir string) error
+	/*** END DCS Customizations ***/
 }
 
 // IsValid checks whether at least one option is chosen for generation
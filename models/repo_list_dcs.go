@@ -0,0 +1,93 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"xorm.io/builder"
+)
+
+// LoadCatalogMetadata loads the latest catalog entry of each stage into CatalogMetadata for all the repos
+// in the list with a single query. The StageLatest entry is the repo's default branch.
+func (repos RepositoryList) LoadCatalogMetadata() error {
+	return repos.loadCatalogMetadata(x)
+}
+
+func (repos RepositoryList) loadCatalogMetadata(e Engine) error {
+	if len(repos) == 0 {
+		return nil
+	}
+
+	repoIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		repo.CatalogMetadata = make(map[Stage]*Door43Metadata)
+		repoIDs = append(repoIDs, repo.ID)
+	}
+
+	latest, err := builder.Select("`door43_metadata`.repo_id", "`door43_metadata`.stage", "MAX(`door43_metadata`.created_unix) AS latest_unix").
+		From("door43_metadata").
+		Where(builder.In("`door43_metadata`.repo_id", repoIDs)).
+		GroupBy("`door43_metadata`.repo_id, `door43_metadata`.stage").
+		ToBoundSQL()
+	if err != nil {
+		return err
+	}
+
+	dms := make([]*Door43Metadata, 0, len(repos))
+	if err := e.
+		Join("INNER", "("+latest+") latest_dm", "`latest_dm`.repo_id = `door43_metadata`.repo_id AND `latest_dm`.stage = `door43_metadata`.stage AND `latest_dm`.latest_unix = `door43_metadata`.created_unix").
+		Asc("`door43_metadata`.id").
+		Find(&dms); err != nil {
+		return fmt.Errorf("find catalog metadata: %v", err)
+	}
+
+	repoMap := make(map[int64]*Repository, len(repos))
+	for _, repo := range repos {
+		repoMap[repo.ID] = repo
+	}
+	for _, dm := range dms {
+		if repo, ok := repoMap[dm.RepoID]; ok {
+			// Ties on created_unix go to the last one created
+			dm.Repo = repo
+			repo.CatalogMetadata[dm.Stage] = dm
+		}
+	}
+	return nil
+}
+
+// LoadCatalogMetadata loads the latest catalog entry of each stage of the repo if not already loaded
+func (repo *Repository) LoadCatalogMetadata() error {
+	if repo.CatalogMetadata != nil {
+		return nil
+	}
+	return RepositoryList{repo}.LoadCatalogMetadata()
+}
+
+// GetCatalogStageMetadata returns the repo's latest catalog entry for the given stage, nil if none.
+// A draft is only returned if it is newer than the production and pre-production releases, and a
+// pre-production release only if it is newer than the production release, the same as the catalog does.
+func (repo *Repository) GetCatalogStageMetadata(stage Stage) *Door43Metadata {
+	if repo.CatalogMetadata == nil {
+		return nil
+	}
+	prod := repo.CatalogMetadata[StageProd]
+	preprod := repo.CatalogMetadata[StagePreProd]
+	switch stage {
+	case StagePreProd:
+		if preprod != nil && prod != nil && prod.ReleaseDateUnix >= preprod.ReleaseDateUnix {
+			return nil
+		}
+		return preprod
+	case StageDraft:
+		draft := repo.CatalogMetadata[StageDraft]
+		if draft != nil && ((prod != nil && prod.ReleaseDateUnix >= draft.ReleaseDateUnix) ||
+			(preprod != nil && preprod.ReleaseDateUnix >= draft.ReleaseDateUnix)) {
+			return nil
+		}
+		return draft
+	}
+	return repo.CatalogMetadata[stage]
+}
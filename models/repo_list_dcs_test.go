@@ -0,0 +1,42 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryList_LoadCatalogMetadata(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo2 := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	metadata := &map[string]interface{}{"dublin_core": map[string]interface{}{"identifier": "tn"}}
+	dms := []*Door43Metadata{
+		{RepoID: 1, ReleaseID: 0, MetadataVersion: "rc0.2", Metadata: metadata, Stage: StageLatest, BranchOrTag: "master", ReleaseDateUnix: 100},
+		{RepoID: 1, ReleaseID: 1, MetadataVersion: "rc0.2", Metadata: metadata, Stage: StageProd, BranchOrTag: "v1", ReleaseDateUnix: 200},
+		{RepoID: 1, ReleaseID: 2, MetadataVersion: "rc0.2", Metadata: metadata, Stage: StagePreProd, BranchOrTag: "v2-rc", ReleaseDateUnix: 150},
+	}
+	for _, dm := range dms {
+		_, err := x.Insert(dm)
+		assert.NoError(t, err)
+	}
+
+	repos := RepositoryList{repo1, repo2}
+	assert.NoError(t, repos.LoadCatalogMetadata())
+
+	assert.Len(t, repo1.CatalogMetadata, 3)
+	assert.Equal(t, "master", repo1.GetCatalogStageMetadata(StageLatest).BranchOrTag)
+	assert.Equal(t, "v1", repo1.GetCatalogStageMetadata(StageProd).BranchOrTag)
+	// The pre-production release is older than the production release
+	assert.Nil(t, repo1.GetCatalogStageMetadata(StagePreProd))
+	assert.Nil(t, repo1.GetCatalogStageMetadata(StageDraft))
+
+	assert.NotNil(t, repo2.CatalogMetadata)
+	assert.Empty(t, repo2.CatalogMetadata)
+	assert.Nil(t, repo2.GetCatalogStageMetadata(StageLatest))
+}
@@ -104,6 +104,28 @@ func PublicSigningKey(repoPath string) (string, error) {
 	return content, nil
 }
 
+/*** DCS Customizations - Signed release checksums ***/
+
+// SignDetached signs content with the signing key of the repository within the provided directory, returning
+// the ASCII armored detached signature and the key ID, both empty if there is no signing key
+func SignDetached(repoPath, content string) (string, string, error) {
+	signingKey, _ := SigningKey(repoPath)
+	if signingKey == "" {
+		return "", "", nil
+	}
+
+	signature, stderr, err := process.GetManager().ExecDirEnvStdIn(-1, repoPath,
+		"gpg --detach-sign --armor", nil, strings.NewReader(content),
+		"gpg", "--batch", "--detach-sign", "--armor", "--local-user", signingKey)
+	if err != nil {
+		log.Error("Unable to sign with signing key in %s: %s, %s, %v", repoPath, signingKey, stderr, err)
+		return "", "", err
+	}
+	return signature, signingKey, nil
+}
+
+/*** END DCS Customizations ***/
+
 // SignInitialCommit determines if we should sign the initial commit to this repository
 func SignInitialCommit(repoPath string, u *User) (bool, string, *git.Signature, error) {
 	rules := signingModeFromStrings(setting.Repository.Signing.InitialCommit)
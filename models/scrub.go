@@ -0,0 +1,117 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ScrubRules are an organization's rules for scrubbing sensitive data from its repos.
+// Empty values fall back to the instance's rules in the [dcs.scrubber] config section.
+type ScrubRules struct {
+	ID             int64              `xorm:"pk autoincr"`
+	OrgID          int64              `xorm:"UNIQUE NOT NULL"`
+	Files          []string           `xorm:"TEXT JSON"`
+	Fields         []string           `xorm:"TEXT JSON"`
+	CommitterName  string             `xorm:"VARCHAR(255)"`
+	CommitterEmail string             `xorm:"VARCHAR(255)"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// IsEmpty returns true if the rules do not override any of the instance's rules
+func (rules *ScrubRules) IsEmpty() bool {
+	return len(rules.Files) == 0 && len(rules.Fields) == 0 && rules.CommitterName == "" && rules.CommitterEmail == ""
+}
+
+// GetScrubRulesByOrgID returns the scrub rules of an organization, empty rules if it has none
+func GetScrubRulesByOrgID(orgID int64) (*ScrubRules, error) {
+	return getScrubRulesByOrgID(x, orgID)
+}
+
+func getScrubRulesByOrgID(e Engine, orgID int64) (*ScrubRules, error) {
+	rules := &ScrubRules{OrgID: orgID}
+	if _, err := e.Get(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpdateScrubRules inserts or updates the scrub rules of an organization, deleting them if they are empty
+func UpdateScrubRules(rules *ScrubRules) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	existing, err := getScrubRulesByOrgID(sess, rules.OrgID)
+	if err != nil {
+		return err
+	}
+	if rules.IsEmpty() {
+		if existing.ID > 0 {
+			if _, err := sess.ID(existing.ID).Delete(new(ScrubRules)); err != nil {
+				return err
+			}
+		}
+		return sess.Commit()
+	}
+
+	if existing.ID > 0 {
+		rules.ID = existing.ID
+		if _, err := sess.ID(rules.ID).Cols("files", "fields", "committer_name", "committer_email").Update(rules); err != nil {
+			return err
+		}
+	} else if _, err := sess.Insert(rules); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// ScrubLog is the audit log entry of a scrub of a repo's sensitive data
+type ScrubLog struct {
+	ID             int64              `xorm:"pk autoincr"`
+	RepoID         int64              `xorm:"INDEX NOT NULL"`
+	DoerID         int64              `xorm:"INDEX NOT NULL"`
+	Doer           *User              `xorm:"-"`
+	Refs           []string           `xorm:"TEXT JSON"` // the branches and tags that were rewritten
+	Files          []string           `xorm:"TEXT JSON"` // ref:path of each file that was scrubbed
+	CommitterName  string             `xorm:"VARCHAR(255)"`
+	CommitterEmail string             `xorm:"VARCHAR(255)"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LoadDoer loads the user who did the scrub
+func (l *ScrubLog) LoadDoer() error {
+	if l.Doer != nil {
+		return nil
+	}
+	doer, err := GetUserByID(l.DoerID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		doer = NewGhostUser()
+	}
+	l.Doer = doer
+	return nil
+}
+
+// InsertScrubLog records a scrub in the audit log
+func InsertScrubLog(l *ScrubLog) error {
+	_, err := x.Insert(l)
+	return err
+}
+
+// GetScrubLogsByRepoID returns the audit log of the scrubs of a repo, newest first
+func GetScrubLogsByRepoID(repoID int64, listOptions ListOptions) ([]*ScrubLog, error) {
+	sess := x.Where("repo_id = ?", repoID).Desc("created_unix", "id")
+	if listOptions.Page > 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	logs := make([]*ScrubLog, 0, listOptions.PageSize)
+	return logs, sess.Find(&logs)
+}
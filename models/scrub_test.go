@@ -0,0 +1,50 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateScrubRules(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	rules, err := GetScrubRulesByOrgID(3)
+	assert.NoError(t, err)
+	assert.True(t, rules.IsEmpty())
+
+	assert.NoError(t, UpdateScrubRules(&ScrubRules{OrgID: 3, Fields: []string{"dublin_core.contributor"}}))
+	rules, err = GetScrubRulesByOrgID(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dublin_core.contributor"}, rules.Fields)
+	assert.Empty(t, rules.Files)
+
+	assert.NoError(t, UpdateScrubRules(&ScrubRules{OrgID: 3, CommitterName: "Scrubbed"}))
+	rules, err = GetScrubRulesByOrgID(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Scrubbed", rules.CommitterName)
+	assert.Empty(t, rules.Fields)
+
+	// Empty rules are deleted so the instance's rules are used
+	assert.NoError(t, UpdateScrubRules(&ScrubRules{OrgID: 3}))
+	AssertNotExistsBean(t, &ScrubRules{OrgID: 3})
+}
+
+func TestGetScrubLogsByRepoID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, InsertScrubLog(&ScrubLog{RepoID: 1, DoerID: 2, Refs: []string{"refs/heads/master"}}))
+	assert.NoError(t, InsertScrubLog(&ScrubLog{RepoID: 1, DoerID: 2, Refs: []string{"refs/heads/master", "refs/tags/v1"}, Files: []string{"master:manifest.json"}}))
+
+	logs, err := GetScrubLogsByRepoID(1, ListOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, logs, 2) {
+		assert.Equal(t, []string{"master:manifest.json"}, logs[0].Files)
+		assert.NoError(t, logs[0].LoadDoer())
+		assert.EqualValues(t, 2, logs[0].Doer.ID)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// SensitiveDataCheckType defines how pushes to a repository are checked for sensitive data
+type SensitiveDataCheckType int
+
+// kinds of SensitiveDataCheck
+const (
+	DefaultSensitiveDataCheck SensitiveDataCheckType = iota // the instance's default check
+	OffSensitiveDataCheck                                   // pushes are not checked
+	WarnSensitiveDataCheck                                  // pushes with sensitive data are accepted with a warning
+	RejectSensitiveDataCheck                                // pushes with sensitive data are rejected
+)
+
+// String converts a SensitiveDataCheckType to a string
+func (t SensitiveDataCheckType) String() string {
+	switch t {
+	case OffSensitiveDataCheck:
+		return "off"
+	case WarnSensitiveDataCheck:
+		return "warn"
+	case RejectSensitiveDataCheck:
+		return "reject"
+	}
+	return "default"
+}
+
+// ToSensitiveDataCheck converts a string to a SensitiveDataCheckType
+func ToSensitiveDataCheck(check string) SensitiveDataCheckType {
+	switch strings.ToLower(strings.TrimSpace(check)) {
+	case "off":
+		return OffSensitiveDataCheck
+	case "warn":
+		return WarnSensitiveDataCheck
+	case "reject":
+		return RejectSensitiveDataCheck
+	}
+	return DefaultSensitiveDataCheck
+}
+
+// GetSensitiveDataCheck returns the check of pushes to the repo for sensitive data, or the instance's default check
+func (repo *Repository) GetSensitiveDataCheck() SensitiveDataCheckType {
+	check := repo.SensitiveDataCheck
+	if check == DefaultSensitiveDataCheck {
+		check = ToSensitiveDataCheck(setting.DCS.Scrubber.PushCheck)
+		if check == DefaultSensitiveDataCheck {
+			return WarnSensitiveDataCheck
+		}
+	}
+	return check
+}
+
+// SensitiveData is the sensitive data found in the files of a branch or tag of a repo when they were pushed
+type SensitiveData struct {
+	ID          int64               `xorm:"pk autoincr"`
+	RepoID      int64               `xorm:"UNIQUE(s) NOT NULL"`
+	Repo        *Repository         `xorm:"-"`
+	RefName     string              `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"` // full name, e.g. refs/heads/master
+	CommitID    string              `xorm:"VARCHAR(40)"`
+	Files       map[string][]string `xorm:"TEXT JSON"` // path of each file and what was found in it
+	UpdatedUnix timeutil.TimeStamp  `xorm:"INDEX updated"`
+}
+
+// LoadRepo loads the repo of the sensitive data
+func (d *SensitiveData) LoadRepo() error {
+	if d.Repo != nil {
+		return nil
+	}
+	repo, err := GetRepositoryByID(d.RepoID)
+	if err != nil {
+		return err
+	}
+	d.Repo = repo
+	return nil
+}
+
+// RefShortName returns the short name of the branch or tag
+func (d *SensitiveData) RefShortName() string {
+	return git.RefEndName(d.RefName)
+}
+
+// Paths returns the paths of the files with sensitive data, sorted
+func (d *SensitiveData) Paths() []string {
+	paths := make([]string, 0, len(d.Files))
+	for p := range d.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// UpdateSensitiveData updates the sensitive data of a ref of a repo with the files that a push checked:
+// those of the found files are replaced and the other checked files are cleared.
+// The record of the ref is deleted once none of its files have sensitive data.
+func UpdateSensitiveData(repoID int64, refName, commitID string, checked []string, found map[string][]string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	data := &SensitiveData{RepoID: repoID, RefName: refName}
+	has, err := sess.Get(data)
+	if err != nil {
+		return err
+	}
+	if data.Files == nil {
+		data.Files = make(map[string][]string)
+	}
+	for _, p := range checked {
+		delete(data.Files, p)
+	}
+	for p, matches := range found {
+		data.Files[p] = matches
+	}
+	data.CommitID = commitID
+
+	switch {
+	case len(data.Files) == 0 && has:
+		_, err = sess.ID(data.ID).Delete(new(SensitiveData))
+	case len(data.Files) == 0:
+	case has:
+		_, err = sess.ID(data.ID).Cols("commit_id", "files").Update(data)
+	default:
+		_, err = sess.Insert(data)
+	}
+	if err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteSensitiveData deletes the sensitive data of a ref of a repo, e.g. once the ref is deleted
+func DeleteSensitiveData(repoID int64, refName string) error {
+	_, err := x.Delete(&SensitiveData{RepoID: repoID, RefName: refName})
+	return err
+}
+
+// DeleteSensitiveDataByRepoID deletes the sensitive data of all refs of a repo, e.g. once it is scrubbed
+func DeleteSensitiveDataByRepoID(repoID int64) error {
+	_, err := x.Delete(&SensitiveData{RepoID: repoID})
+	return err
+}
+
+// GetSensitiveDataByRepoID returns the sensitive data of the refs of a repo
+func GetSensitiveDataByRepoID(repoID int64) ([]*SensitiveData, error) {
+	list := make([]*SensitiveData, 0, 5)
+	return list, x.Where("repo_id = ?", repoID).Asc("ref_name").Find(&list)
+}
+
+// FindSensitiveData returns the sensitive data of the refs of all repos, most recently found first, and their count
+func FindSensitiveData(listOptions ListOptions) ([]*SensitiveData, int64, error) {
+	sess := x.Desc("updated_unix", "id")
+	if listOptions.Page > 0 {
+		sess = listOptions.setSessionPagination(sess)
+	}
+	list := make([]*SensitiveData, 0, listOptions.PageSize)
+	count, err := sess.FindAndCount(&list)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, data := range list {
+		if err := data.LoadRepo(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return list, count, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_GetSensitiveDataCheck(t *testing.T) {
+	defer func(check string) { setting.DCS.Scrubber.PushCheck = check }(setting.DCS.Scrubber.PushCheck)
+
+	setting.DCS.Scrubber.PushCheck = "reject"
+	assert.Equal(t, RejectSensitiveDataCheck, (&Repository{}).GetSensitiveDataCheck())
+	assert.Equal(t, OffSensitiveDataCheck, (&Repository{SensitiveDataCheck: OffSensitiveDataCheck}).GetSensitiveDataCheck())
+
+	setting.DCS.Scrubber.PushCheck = ""
+	assert.Equal(t, WarnSensitiveDataCheck, (&Repository{}).GetSensitiveDataCheck())
+	assert.Equal(t, DefaultSensitiveDataCheck, ToSensitiveDataCheck("unknown"))
+}
+
+func TestUpdateSensitiveData(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, UpdateSensitiveData(1, "refs/heads/master", "a", []string{"manifest.yaml", "README.md"}, map[string][]string{
+		"manifest.yaml": {"dublin_core.contributor"},
+	}))
+	assert.NoError(t, UpdateSensitiveData(1, "refs/tags/v1", "b", []string{"01-GEN.usfm"}, map[string][]string{
+		"01-GEN.usfm": {"line 2: email"},
+	}))
+	// Nothing is recorded for a clean ref
+	assert.NoError(t, UpdateSensitiveData(2, "refs/heads/master", "c", []string{"manifest.yaml"}, nil))
+
+	list, count, err := FindSensitiveData(ListOptions{Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Equal(t, "repo1", list[0].Repo.Name)
+
+	// Files that are checked again replace what was found in them
+	assert.NoError(t, UpdateSensitiveData(1, "refs/heads/master", "d", []string{"project.json"}, map[string][]string{
+		"project.json": {"translators"},
+	}))
+	list, err = GetSensitiveDataByRepoID(1)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "master", list[0].RefShortName())
+	assert.Equal(t, "d", list[0].CommitID)
+	assert.Equal(t, []string{"manifest.yaml", "project.json"}, list[0].Paths())
+
+	// and the ref is cleared once all its files are clean
+	assert.NoError(t, UpdateSensitiveData(1, "refs/heads/master", "e", []string{"manifest.yaml", "project.json"}, nil))
+	list, err = GetSensitiveDataByRepoID(1)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "refs/tags/v1", list[0].RefName)
+
+	assert.NoError(t, DeleteSensitiveDataByRepoID(1))
+	list, err = GetSensitiveDataByRepoID(1)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -157,6 +157,9 @@ type User struct {
 	MembersIsPublic           map[int64]bool      `xorm:"-"`
 	Visibility                structs.VisibleType `xorm:"NOT NULL DEFAULT 0"`
 	RepoAdminChangeTeamAccess bool                `xorm:"NOT NULL DEFAULT false"`
+	/*** DCS Customizations ***/
+	BlockInconsistentReleases bool `xorm:"NOT NULL DEFAULT false"`
+	/*** END DCS Customizations ***/
 
 	// Preferences
 	DiffViewStyle       string `xorm:"NOT NULL DEFAULT ''"`
@@ -10,6 +10,8 @@ import (
 
 	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
 )
 
 func contains(strings []string, str string) bool {
@@ -21,25 +23,52 @@ func contains(strings []string, str string) bool {
 	return false
 }
 
+// publicRepoCond is the condition of the user's public repos
+func (u *User) publicRepoCond() builder.Cond {
+	return builder.Eq{"`repository`.owner_id": u.ID, "`repository`.is_private": false}
+}
+
+// getDefaultBranchMetadataValues gets the distinct values of an expression of the default branch metadata of the user's public repos
+func (u *User) getDefaultBranchMetadataValues(expr string) ([]string, error) {
+	var values []string
+	return values, x.Table("door43_metadata").
+		Join("INNER", "repository", "`repository`.id = `door43_metadata`.repo_id").
+		Where(builder.And(u.publicRepoCond(), builder.Eq{"`door43_metadata`.release_id": 0})).
+		Select("DISTINCT " + expr).
+		Find(&values)
+}
+
+// getRepoNames gets the lower names of the user's public repos, only those without default branch metadata if withoutMetadata
+func (u *User) getRepoNames(withoutMetadata bool) ([]string, error) {
+	var names []string
+	sess := x.Table("repository")
+	cond := u.publicRepoCond()
+	if withoutMetadata {
+		sess.Join("LEFT", "door43_metadata", "`door43_metadata`.repo_id = `repository`.id AND `door43_metadata`.release_id = 0")
+		cond = builder.And(cond, builder.IsNull{"`door43_metadata`.id"})
+	}
+	return names, sess.Where(cond).Cols("`repository`.lower_name").Find(&names)
+}
+
 // GetRepoLanguages gets the languages of the user's repos and returns alphabetized list
 func (u *User) GetRepoLanguages() []string {
 	var languages []string
-	if repos, _, err := GetUserRepositories(&SearchRepoOptions{Actor: u, Private: false, ListOptions: ListOptions{PageSize: 0}}); err != nil {
-		log.Error("Error GetUserRepositories: %v", err)
-	} else {
-		for _, repo := range repos {
-			lang := dcs.GetLanguageFromRepoName(repo.LowerName)
-			if lang != "" && !contains(languages, lang) {
-				languages = append(languages, lang)
-			}
-			if dm, err := repo.GetDefaultBranchMetadata(); err != nil {
-				log.Error("Error GetDefaultBranchMetadata: %v", err)
-			} else if dm != nil {
-				lang = (*dm.Metadata)["dublin_core"].(map[string]interface{})["language"].(map[string]interface{})["identifier"].(string)
-				if lang != "" && !contains(languages, lang) {
-					languages = append(languages, lang)
-				}
-			}
+	names, err := u.getRepoNames(false)
+	if err != nil {
+		log.Error("Error getRepoNames: %v", err)
+	}
+	for _, name := range names {
+		if lang := dcs.GetLanguageFromRepoName(name); lang != "" && !contains(languages, lang) {
+			languages = append(languages, lang)
+		}
+	}
+	metadataLanguages, err := u.getDefaultBranchMetadataValues(catalogStatsLanguageExpr)
+	if err != nil {
+		log.Error("Error getDefaultBranchMetadataValues: %v", err)
+	}
+	for _, lang := range metadataLanguages {
+		if lang != "" && !contains(languages, lang) {
+			languages = append(languages, lang)
 		}
 	}
 	sort.SliceStable(languages, func(i, j int) bool { return strings.ToLower(languages[i]) < strings.ToLower(languages[j]) })
@@ -49,20 +78,23 @@ func (u *User) GetRepoLanguages() []string {
 // GetRepoSubjects gets the subjects of the user's repos and returns alphabetized list
 func (u *User) GetRepoSubjects() []string {
 	var subjects []string
-	if repos, _, err := GetUserRepositories(&SearchRepoOptions{Actor: u, Private: false, ListOptions: ListOptions{PageSize: 0}}); err != nil {
-		log.Error("Error GetUserRepositories: %v", err)
-	} else {
-		for _, repo := range repos {
-			if dm, err := repo.GetDefaultBranchMetadata(); err != nil {
-				log.Error("Error GetDefaultBranchMetadata: %v", err)
-			} else if dm != nil {
-				subject := (*dm.Metadata)["dublin_core"].(map[string]interface{})["subject"].(string)
-				if subject != "" && !contains(subjects, subject) {
-					subjects = append(subjects, subject)
-				}
-			} else if subject := dcs.GetSubjectFromRepoName(repo.LowerName); subject != "" && !contains(subjects, subject) {
-				subjects = append(subjects, subject)
-			}
+	metadataSubjects, err := u.getDefaultBranchMetadataValues(catalogStatsSubjectExpr)
+	if err != nil {
+		log.Error("Error getDefaultBranchMetadataValues: %v", err)
+	}
+	for _, subject := range metadataSubjects {
+		if subject != "" && !contains(subjects, subject) {
+			subjects = append(subjects, subject)
+		}
+	}
+	// The repos without metadata have the subject of their name
+	names, err := u.getRepoNames(true)
+	if err != nil {
+		log.Error("Error getRepoNames: %v", err)
+	}
+	for _, name := range names {
+		if subject := dcs.GetSubjectFromRepoName(name); subject != "" && !contains(subjects, subject) {
+			subjects = append(subjects, subject)
 		}
 	}
 	sort.SliceStable(subjects, func(i, j int) bool { return strings.ToLower(subjects[i]) < strings.ToLower(subjects[j]) })
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"strings"
 
+	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/options"
@@ -76,6 +77,23 @@ func ValidateManifestFile(entry *git.TreeEntry) string {
 	return StringifyManifestValidationResults(result)
 }
 
+// ValidateManifestFileConsistency checks that a manifest file agrees with the repo name and the tag (if not empty)
+// and returns the inconsistencies as a string
+func ValidateManifestFileConsistency(repoName, tagName string, entry *git.TreeEntry) string {
+	if entry == nil {
+		return ""
+	}
+	manifest, err := ReadYAMLFromBlob(entry.Blob())
+	if err != nil {
+		return ""
+	}
+	issues := dcs.CheckManifestConsistency(repoName, tagName, manifest)
+	if len(issues) == 0 {
+		return ""
+	}
+	return " * " + strings.Join(issues, ";\n * ")
+}
+
 // StringifyManifestValidationResults returns the errors and a string
 func StringifyManifestValidationResults(result *gojsonschema.Result) string {
 	return StringifyValidationErrors(result)
@@ -146,10 +164,17 @@ func GetRC020Schema() ([]byte, error) {
 
 // ReadYAMLFromBlob reads a yaml file from a blob and unmarshals it
 func ReadYAMLFromBlob(blob *git.Blob) (*map[string]interface{}, error) {
+	result, _, err := ReadYAMLAndContentFromBlob(blob)
+	return result, err
+}
+
+// ReadYAMLAndContentFromBlob reads a yaml file from a blob and unmarshals it, also returning its content,
+// e.g. to keep its formatting when saving it changed
+func ReadYAMLAndContentFromBlob(blob *git.Blob) (*map[string]interface{}, []byte, error) {
 	dataRc, err := blob.DataAsync()
 	if err != nil {
 		log.Warn("DataAsync Error: %v\n", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer dataRc.Close()
 	content, _ := ioutil.ReadAll(dataRc)
@@ -157,9 +182,9 @@ func ReadYAMLFromBlob(blob *git.Blob) (*map[string]interface{}, error) {
 	var result *map[string]interface{}
 	if err := yaml.Unmarshal(content, &result); err != nil {
 		log.Error("yaml.Unmarshal: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
-	return result, nil
+	return result, content, nil
 }
 
 // ValidateJSONFromBlob reads a json file from a blob and unmarshals it returning any errors
@@ -0,0 +1,54 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+func toCatalogStatsCounts(counts []*models.CatalogStatsCount) []*api.CatalogStatsCount {
+	result := make([]*api.CatalogStatsCount, len(counts))
+	for i, count := range counts {
+		result[i] = &api.CatalogStatsCount{Value: count.Value, Count: count.Count}
+	}
+	return result
+}
+
+// ToCatalogStatsV5 converts models.CatalogStats to api.CatalogStatsV5, the stale entries being seen by the doer
+func ToCatalogStatsV5(stats *models.CatalogStats, doer *models.User) (*api.CatalogStatsV5, error) {
+	result := &api.CatalogStatsV5{
+		Stage:            stats.Stage.String(),
+		Total:            stats.Total,
+		Languages:        toCatalogStatsCounts(stats.Languages),
+		Subjects:         toCatalogStatsCounts(stats.Subjects),
+		CheckingLevels:   toCatalogStatsCounts(stats.CheckingLevels),
+		ReleasesPerMonth: toCatalogStatsCounts(stats.ReleasesPerMonth),
+		StaleBefore:      stats.StaleBefore.AsTime(),
+		StaleCount:       stats.StaleCount,
+		Stale:            make([]*api.Door43MetadataV5, 0, len(stats.Stale)),
+	}
+	for _, dm := range stats.Stale {
+		accessMode, err := models.AccessLevel(doer, dm.Repo)
+		if err != nil {
+			return nil, err
+		}
+		if entry := ToDoor43MetadataV5(dm, accessMode); entry != nil {
+			entry.Ingredients = nil
+			result.Stale = append(result.Stale, entry)
+		}
+	}
+	return result, nil
+}
+
+// ToCatalogStatsSnapshot converts models.CatalogStatsSnapshot to api.CatalogStatsSnapshot
+func ToCatalogStatsSnapshot(snapshot *models.CatalogStatsSnapshot) *api.CatalogStatsSnapshot {
+	return &api.CatalogStatsSnapshot{
+		Kind:  snapshot.Kind,
+		Value: snapshot.Value,
+		Count: snapshot.Count,
+		Date:  snapshot.CreatedUnix.AsTime(),
+	}
+}
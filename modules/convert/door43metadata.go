@@ -5,7 +5,10 @@
 package convert
 
 import (
+	"strings"
+
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/door43metadata"
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 )
@@ -77,6 +80,83 @@ func ToDoor43MetadataV5(dm *models.Door43Metadata, mode models.AccessMode) *api.
 		MetadataURL:            dm.GetMetadataURL(),
 		MetadataJSONURL:        dm.GetMetadataJSONURL(),
 		MetadataAPIContentsURL: dm.GetMetadataAPIContentsURL(),
-		Ingredients:            (*dm.Metadata)["projects"].([]interface{}),
+		Ingredients:            toIngredientsV5(dm),
+		ChecksumsURL:           dm.GetChecksumsURL(),
+		ChecksumsSignatureURL:  dm.GetChecksumsSignatureURL(),
+		SigningKeyURL:          dm.GetSigningKeyURL(),
+		Warnings:               dm.Warnings,
+	}
+}
+
+// ToBookProgress converts a models.BookProgress to api.BookProgress
+func ToBookProgress(bp *models.BookProgress) *api.BookProgress {
+	return &api.BookProgress{
+		Verses:      bp.Verses,
+		TotalVerses: bp.TotalVerses,
+		Percent:     bp.Percent(),
+	}
+}
+
+// toIngredientsV5 returns the projects of the metadata, each of the books with a translation progress having it
+// as progress. The projects of the metadata themselves are left as is.
+func toIngredientsV5(dm *models.Door43Metadata) []interface{} {
+	projects, _ := (*dm.Metadata)["projects"].([]interface{})
+	bps, err := dm.GetBookProgresses()
+	if err != nil {
+		log.Error("GetBookProgresses: %v", err)
+		return projects
+	}
+	if len(bps) == 0 {
+		return projects
+	}
+	byBook := make(map[string]*models.BookProgress, len(bps))
+	for _, bp := range bps {
+		byBook[bp.Book] = bp
+	}
+
+	ingredients := make([]interface{}, 0, len(projects))
+	for _, p := range projects {
+		project, ok := p.(map[string]interface{})
+		identifier, _ := project["identifier"].(string)
+		bp, hasProgress := byBook[strings.ToLower(identifier)]
+		if !ok || !hasProgress {
+			ingredients = append(ingredients, p)
+			continue
+		}
+		ingredient := make(map[string]interface{}, len(project)+1)
+		for key, value := range project {
+			ingredient[key] = value
+		}
+		ingredient["progress"] = ToBookProgress(bp)
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients
+}
+
+// ToCatalogPreviewV5 converts a door43metadata.Preview to api.CatalogPreviewV5
+func ToCatalogPreviewV5(preview *door43metadata.Preview, mode models.AccessMode) *api.CatalogPreviewV5 {
+	result := &api.CatalogPreviewV5{
+		Ref:       preview.Ref,
+		CommitSHA: preview.CommitID,
+		Valid:     preview.Valid(),
+		Metadata:  preview.Manifest,
+		Errors:    append([]string{}, preview.Errors...),
+		Warnings:  append([]string{}, preview.Warnings...),
+		Changes:   make([]*api.CatalogMetadataChangeV5, 0, len(preview.Changes)),
+	}
+	if preview.Door43Metadata != nil {
+		result.Entry = ToDoor43MetadataV5(preview.Door43Metadata, mode)
+	}
+	if preview.Prod != nil {
+		result.Prod = ToDoor43MetadataV5(preview.Prod, mode)
+	}
+	for _, change := range preview.Changes {
+		result.Changes = append(result.Changes, &api.CatalogMetadataChangeV5{
+			Path:    change.Path,
+			Type:    change.Kind(),
+			Prod:    change.Old,
+			Preview: change.New,
+		})
 	}
+	return result
 }
@@ -5,6 +5,8 @@
 package convert
 
 import (
+	"fmt"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/log"
@@ -96,27 +98,20 @@ func innerToRepo(repo *models.Repository, mode models.AccessMode, isParent bool)
 	numReleases, _ := models.GetReleaseCountByRepoID(repo.ID, models.FindReleasesOptions{IncludeDrafts: false, IncludeTags: false})
 
 	/*** DCS Customizations ***/
-
-	// TODO: Load in Repository's LoadAttributes() function and save to repo.Metadata
-	metadata, err := models.GetDoor43MetadataByRepoIDAndReleaseID(repo.ID, 0)
-	if err != nil && !models.IsErrDoor43MetadataNotExist(err) {
-		log.Error("GetDoor43MetadataByRepoIDAndReleaseID: %v", err)
+	if err := repo.LoadCatalogMetadata(); err != nil {
+		log.Error("LoadCatalogMetadata: %v", err)
 	}
-	// if metadata == nil {
-	// 	metadata, err = repo.GetLatestPreProdCatalogMetadata()
-	// 	if err != nil {
-	// 		log.Error("GetLatestPreProdCatalogMetadata: %v", err)
-	// 	}
-	// }
 
 	var language, title, subject, checkingLevel string
 	var books []string
-	if metadata != nil {
-		language = (*metadata.Metadata)["dublin_core"].(map[string]interface{})["language"].(map[string]interface{})["identifier"].(string)
-		title = (*metadata.Metadata)["dublin_core"].(map[string]interface{})["title"].(string)
-		subject = (*metadata.Metadata)["dublin_core"].(map[string]interface{})["subject"].(string)
+	if metadata := repo.GetCatalogStageMetadata(models.StageLatest); metadata != nil && metadata.Metadata != nil {
+		language = dcs.GetDublinCoreString(metadata.Metadata, "language", "identifier")
+		title = dcs.GetDublinCoreString(metadata.Metadata, "title")
+		subject = dcs.GetDublinCoreString(metadata.Metadata, "subject")
 		books = metadata.GetBooks()
-		checkingLevel = (*metadata.Metadata)["checking"].(map[string]interface{})["checking_level"].(string)
+		if checking, ok := (*metadata.Metadata)["checking"].(map[string]interface{}); ok && checking["checking_level"] != nil {
+			checkingLevel = fmt.Sprint(checking["checking_level"])
+		}
 	} else {
 		language = dcs.GetLanguageFromRepoName(repo.LowerName)
 		subject = dcs.GetSubjectFromRepoName(repo.LowerName)
@@ -178,6 +173,7 @@ func innerToRepo(repo *models.Repository, mode models.AccessMode, isParent bool)
 		Subject:                   subject,
 		Books:                     books,
 		CheckingLevel:             checkingLevel,
+		Catalog:                   toCatalogStages(repo),
 		Internal:                  !repo.IsPrivate && repo.Owner.Visibility == api.VisibleTypePrivate,
 		MirrorInterval:            mirrorInterval,
 	}
@@ -6,84 +6,36 @@ package convert
 
 import (
 	"code.gitea.io/gitea/models"
-	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
-// ToRepoDCS adds some fields for DCS Customizations
-func ToRepoDCS(repo *models.Repository, mode models.AccessMode) *api.Repository {
-	apiRepo := ToRepo(repo, mode)
-
+// toCatalogStages converts the repo's latest catalog entries of each stage, which must already be loaded
+// with LoadCatalogMetadata(), to the api.CatalogStages of a repo
+func toCatalogStages(repo *models.Repository) *api.CatalogStages {
 	catalog := &api.CatalogStages{}
-	prod, err := models.GetDoor43MetadataByRepoIDAndStage(repo.ID, models.StageProd)
-	if err != nil {
-		log.Error("GetDoor43MetadataByRepoIDAndStage: %v", err)
+	if prod := repo.GetCatalogStageMetadata(models.StageProd); prod != nil {
+		catalog.Production = toCatalogStage(prod)
 	}
-	preprod, err := models.GetDoor43MetadataByRepoIDAndStage(repo.ID, models.StagePreProd)
-	if err != nil {
-		log.Error("GetDoor43MetadataByRepoIDAndStage: %v", err)
+	if preprod := repo.GetCatalogStageMetadata(models.StagePreProd); preprod != nil {
+		catalog.PreProduction = toCatalogStage(preprod)
 	}
-	draft, err := models.GetDoor43MetadataByRepoIDAndStage(repo.ID, models.StageDraft)
-	if err != nil {
-		log.Error("GetDoor43MetadataByRepoIDAndStage: %v", err)
+	if draft := repo.GetCatalogStageMetadata(models.StageDraft); draft != nil {
+		catalog.Draft = toCatalogStage(draft)
 	}
-	latest, err := models.GetDoor43MetadataByRepoIDAndStage(repo.ID, models.StageLatest)
-	if err != nil {
-		log.Error("GetDoor43MetadataByRepoIDAndStage: %v", err)
+	if latest := repo.GetCatalogStageMetadata(models.StageLatest); latest != nil {
+		catalog.Latest = toCatalogStage(latest)
+		catalog.Latest.ReleaseURL = nil
 	}
+	return catalog
+}
 
-	if draft != nil && ((prod != nil && prod.ReleaseDateUnix >= draft.ReleaseDateUnix) ||
-		(preprod != nil && preprod.ReleaseDateUnix >= draft.ReleaseDateUnix)) {
-		draft = nil
-	}
-	if prod != nil && preprod != nil && prod.ReleaseDateUnix >= preprod.ReleaseDateUnix {
-		preprod = nil
-	}
-	if prod != nil {
-		prod.Repo = repo
-		url := prod.GetReleaseURL()
-		catalog.Production = &api.CatalogStage{
-			Tag:        prod.BranchOrTag,
-			ReleaseURL: &url,
-			Released:   prod.GetReleaseDateTime(),
-			ZipballURL: prod.GetZipballURL(),
-			TarballURL: prod.GetTarballURL(),
-		}
-	}
-	if preprod != nil {
-		preprod.Repo = repo
-		url := preprod.GetReleaseURL()
-		catalog.PreProduction = &api.CatalogStage{
-			Tag:        preprod.BranchOrTag,
-			ReleaseURL: &url,
-			Released:   preprod.GetReleaseDateTime(),
-			ZipballURL: preprod.GetZipballURL(),
-			TarballURL: preprod.GetTarballURL(),
-		}
+func toCatalogStage(dm *models.Door43Metadata) *api.CatalogStage {
+	url := dm.GetReleaseURL()
+	return &api.CatalogStage{
+		Tag:        dm.BranchOrTag,
+		ReleaseURL: &url,
+		Released:   dm.GetReleaseDateTime(),
+		ZipballURL: dm.GetZipballURL(),
+		TarballURL: dm.GetTarballURL(),
 	}
-	if draft != nil {
-		draft.Repo = repo
-		url := draft.GetReleaseURL()
-		catalog.Draft = &api.CatalogStage{
-			Tag:        draft.BranchOrTag,
-			ReleaseURL: &url,
-			Released:   draft.GetReleaseDateTime(),
-			ZipballURL: draft.GetZipballURL(),
-			TarballURL: draft.GetTarballURL(),
-		}
-	}
-	if latest != nil {
-		latest.Repo = repo
-		catalog.Latest = &api.CatalogStage{
-			Tag:        latest.BranchOrTag,
-			ReleaseURL: nil,
-			Released:   latest.GetReleaseDateTime(),
-			ZipballURL: latest.GetZipballURL(),
-			TarballURL: latest.GetTarballURL(),
-		}
-	}
-
-	apiRepo.Catalog = catalog
-
-	return apiRepo
 }
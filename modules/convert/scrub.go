@@ -0,0 +1,33 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToScrubRules converts the scrub rules of an organization to API format
+func ToScrubRules(rules *models.ScrubRules) *api.ScrubRules {
+	return &api.ScrubRules{
+		Files:          rules.Files,
+		Fields:         rules.Fields,
+		CommitterName:  rules.CommitterName,
+		CommitterEmail: rules.CommitterEmail,
+	}
+}
+
+// ToScrubLog converts a scrub audit log entry to API format
+func ToScrubLog(l *models.ScrubLog) *api.ScrubLog {
+	return &api.ScrubLog{
+		ID:             l.ID,
+		Doer:           ToUser(l.Doer, nil),
+		Refs:           l.Refs,
+		Files:          l.Files,
+		CommitterName:  l.CommitterName,
+		CommitterEmail: l.CommitterEmail,
+		Created:        l.CreatedUnix.AsTime(),
+	}
+}
@@ -37,6 +37,16 @@ func registerUpdateDoor43MetadataTask() {
 	})
 }
 
+func registerRecordCatalogStatsTask() {
+	RegisterTaskFatal("record_catalog_stats", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return models.RecordCatalogStats()
+	})
+}
+
 func registerRepoHealthCheck() {
 	type RepoHealthCheckConfig struct {
 		BaseConfig
@@ -144,6 +154,7 @@ func initBasicTasks() {
 	registerSyncExternalUsers()
 	registerDeletedBranchesCleanup()
 	registerUpdateDoor43MetadataTask()
+	registerRecordCatalogStatsTask()
 	if !setting.Repository.DisableMigrations {
 		registerUpdateMigrationPosterID()
 	}
@@ -10,6 +10,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/scrubber"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -131,6 +132,19 @@ func registerDeleteOldActions() {
 	})
 }
 
+/*** DCS Customizations ***/
+func registerScanSensitiveData() {
+	RegisterTaskFatal("scan_sensitive_data", &BaseConfig{
+		Enabled:    false,
+		RunAtStart: false,
+		Schedule:   "@every 168h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return scrubber.ScanSensitiveData(ctx)
+	})
+}
+
+/*** END DCS Customizations ***/
+
 func initExtendedTasks() {
 	registerDeleteInactiveUsers()
 	registerDeleteRepositoryArchives()
@@ -142,4 +156,7 @@ func initExtendedTasks() {
 	registerDeleteMissingRepositories()
 	registerRemoveRandomAvatars()
 	registerDeleteOldActions()
+	/*** DCS Customizations ***/
+	registerScanSensitiveData()
+	/*** END DCS Customizations ***/
 }
@@ -0,0 +1,127 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Testaments of the books of the Bible
+const (
+	TestamentOld = "ot"
+	TestamentNew = "nt"
+)
+
+// Book is a book of the Bible as used in the projects of a manifest
+type Book struct {
+	ID        string
+	Name      string
+	Sort      int
+	Testament string
+}
+
+// Books are the books of the Bible in canonical order
+var Books = []*Book{
+	{ID: "gen", Name: "Genesis", Sort: 1, Testament: TestamentOld},
+	{ID: "exo", Name: "Exodus", Sort: 2, Testament: TestamentOld},
+	{ID: "lev", Name: "Leviticus", Sort: 3, Testament: TestamentOld},
+	{ID: "num", Name: "Numbers", Sort: 4, Testament: TestamentOld},
+	{ID: "deu", Name: "Deuteronomy", Sort: 5, Testament: TestamentOld},
+	{ID: "jos", Name: "Joshua", Sort: 6, Testament: TestamentOld},
+	{ID: "jdg", Name: "Judges", Sort: 7, Testament: TestamentOld},
+	{ID: "rut", Name: "Ruth", Sort: 8, Testament: TestamentOld},
+	{ID: "1sa", Name: "1 Samuel", Sort: 9, Testament: TestamentOld},
+	{ID: "2sa", Name: "2 Samuel", Sort: 10, Testament: TestamentOld},
+	{ID: "1ki", Name: "1 Kings", Sort: 11, Testament: TestamentOld},
+	{ID: "2ki", Name: "2 Kings", Sort: 12, Testament: TestamentOld},
+	{ID: "1ch", Name: "1 Chronicles", Sort: 13, Testament: TestamentOld},
+	{ID: "2ch", Name: "2 Chronicles", Sort: 14, Testament: TestamentOld},
+	{ID: "ezr", Name: "Ezra", Sort: 15, Testament: TestamentOld},
+	{ID: "neh", Name: "Nehemiah", Sort: 16, Testament: TestamentOld},
+	{ID: "est", Name: "Esther", Sort: 17, Testament: TestamentOld},
+	{ID: "job", Name: "Job", Sort: 18, Testament: TestamentOld},
+	{ID: "psa", Name: "Psalms", Sort: 19, Testament: TestamentOld},
+	{ID: "pro", Name: "Proverbs", Sort: 20, Testament: TestamentOld},
+	{ID: "ecc", Name: "Ecclesiastes", Sort: 21, Testament: TestamentOld},
+	{ID: "sng", Name: "Song of Solomon", Sort: 22, Testament: TestamentOld},
+	{ID: "isa", Name: "Isaiah", Sort: 23, Testament: TestamentOld},
+	{ID: "jer", Name: "Jeremiah", Sort: 24, Testament: TestamentOld},
+	{ID: "lam", Name: "Lamentations", Sort: 25, Testament: TestamentOld},
+	{ID: "ezk", Name: "Ezekiel", Sort: 26, Testament: TestamentOld},
+	{ID: "dan", Name: "Daniel", Sort: 27, Testament: TestamentOld},
+	{ID: "hos", Name: "Hosea", Sort: 28, Testament: TestamentOld},
+	{ID: "jol", Name: "Joel", Sort: 29, Testament: TestamentOld},
+	{ID: "amo", Name: "Amos", Sort: 30, Testament: TestamentOld},
+	{ID: "oba", Name: "Obadiah", Sort: 31, Testament: TestamentOld},
+	{ID: "jon", Name: "Jonah", Sort: 32, Testament: TestamentOld},
+	{ID: "mic", Name: "Micah", Sort: 33, Testament: TestamentOld},
+	{ID: "nam", Name: "Nahum", Sort: 34, Testament: TestamentOld},
+	{ID: "hab", Name: "Habakkuk", Sort: 35, Testament: TestamentOld},
+	{ID: "zep", Name: "Zephaniah", Sort: 36, Testament: TestamentOld},
+	{ID: "hag", Name: "Haggai", Sort: 37, Testament: TestamentOld},
+	{ID: "zec", Name: "Zechariah", Sort: 38, Testament: TestamentOld},
+	{ID: "mal", Name: "Malachi", Sort: 39, Testament: TestamentOld},
+	{ID: "mat", Name: "Matthew", Sort: 40, Testament: TestamentNew},
+	{ID: "mrk", Name: "Mark", Sort: 41, Testament: TestamentNew},
+	{ID: "luk", Name: "Luke", Sort: 42, Testament: TestamentNew},
+	{ID: "jhn", Name: "John", Sort: 43, Testament: TestamentNew},
+	{ID: "act", Name: "Acts", Sort: 44, Testament: TestamentNew},
+	{ID: "rom", Name: "Romans", Sort: 45, Testament: TestamentNew},
+	{ID: "1co", Name: "1 Corinthians", Sort: 46, Testament: TestamentNew},
+	{ID: "2co", Name: "2 Corinthians", Sort: 47, Testament: TestamentNew},
+	{ID: "gal", Name: "Galatians", Sort: 48, Testament: TestamentNew},
+	{ID: "eph", Name: "Ephesians", Sort: 49, Testament: TestamentNew},
+	{ID: "php", Name: "Philippians", Sort: 50, Testament: TestamentNew},
+	{ID: "col", Name: "Colossians", Sort: 51, Testament: TestamentNew},
+	{ID: "1th", Name: "1 Thessalonians", Sort: 52, Testament: TestamentNew},
+	{ID: "2th", Name: "2 Thessalonians", Sort: 53, Testament: TestamentNew},
+	{ID: "1ti", Name: "1 Timothy", Sort: 54, Testament: TestamentNew},
+	{ID: "2ti", Name: "2 Timothy", Sort: 55, Testament: TestamentNew},
+	{ID: "tit", Name: "Titus", Sort: 56, Testament: TestamentNew},
+	{ID: "phm", Name: "Philemon", Sort: 57, Testament: TestamentNew},
+	{ID: "heb", Name: "Hebrews", Sort: 58, Testament: TestamentNew},
+	{ID: "jas", Name: "James", Sort: 59, Testament: TestamentNew},
+	{ID: "1pe", Name: "1 Peter", Sort: 60, Testament: TestamentNew},
+	{ID: "2pe", Name: "2 Peter", Sort: 61, Testament: TestamentNew},
+	{ID: "1jn", Name: "1 John", Sort: 62, Testament: TestamentNew},
+	{ID: "2jn", Name: "2 John", Sort: 63, Testament: TestamentNew},
+	{ID: "3jn", Name: "3 John", Sort: 64, Testament: TestamentNew},
+	{ID: "jud", Name: "Jude", Sort: 65, Testament: TestamentNew},
+	{ID: "rev", Name: "Revelation", Sort: 66, Testament: TestamentNew},
+}
+
+var booksByID = func() map[string]*Book {
+	byID := make(map[string]*Book, len(Books))
+	for _, book := range Books {
+		byID[book.ID] = book
+	}
+	return byID
+}()
+
+// GetBook returns the book with the given identifier, case insensitive, or nil if it is not a book of the Bible
+func GetBook(id string) *Book {
+	return booksByID[strings.ToLower(id)]
+}
+
+// IsValidBook returns true if the identifier is a book of the Bible
+func IsValidBook(id string) bool {
+	return GetBook(id) != nil
+}
+
+// Category returns the manifest project category of the book, "bible-ot" or "bible-nt"
+func (b *Book) Category() string {
+	return "bible-" + b.Testament
+}
+
+// UsfmFileName returns the name of the USFM file of the book in a resource container, e.g. 01-GEN.usfm or 41-MAT.usfm,
+// the books of the New Testament being numbered from 41
+func (b *Book) UsfmFileName() string {
+	number := b.Sort
+	if b.Testament == TestamentNew {
+		number++
+	}
+	return fmt.Sprintf("%02d-%s.usfm", number, strings.ToUpper(b.ID))
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ChecksumsFile is the name of the file listing the SHA-256 checksums of the files of a release
+const ChecksumsFile = "checksums.sha256"
+
+// FormatChecksums formats the SHA-256 checksums of files by path in the format of sha256sum, sorted by path,
+// so that the files can be checked with `sha256sum -c`
+func FormatChecksums(checksums map[string]string) string {
+	paths := make([]string, 0, len(checksums))
+	for p := range checksums {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var sb strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&sb, "%s  %s\n", checksums[p], p)
+	}
+	return sb.String()
+}
+
+// ParseChecksums parses SHA-256 checksums in the format of sha256sum into the checksums by path
+func ParseChecksums(content string) (map[string]string, error) {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		// The path follows the checksum and either two spaces or a space and an asterisk (binary mode)
+		if len(text) < 67 || (text[64:66] != "  " && text[64:66] != " *") {
+			return nil, fmt.Errorf("line %d is not a SHA-256 checksum", line)
+		}
+		sum := strings.ToLower(text[:64])
+		if _, err := hex.DecodeString(sum); err != nil {
+			return nil, fmt.Errorf("line %d is not a SHA-256 checksum", line)
+		}
+		checksums[text[66:]] = sum
+	}
+	return checksums, scanner.Err()
+}
+
+// ChecksumsVerification is the result of the verification of files against checksums
+type ChecksumsVerification struct {
+	// Matched are the paths of the files whose checksums match
+	Matched []string
+	// Mismatched are the paths of the files whose checksums do not match
+	Mismatched []string
+	// Missing are the paths of the checksums without a file
+	Missing []string
+	// Extra are the paths of the files without a checksum
+	Extra []string
+}
+
+// Valid returns whether every file has a matching checksum and every checksum has a file
+func (v *ChecksumsVerification) Valid() bool {
+	return len(v.Mismatched) == 0 && len(v.Missing) == 0 && len(v.Extra) == 0
+}
+
+// zipPathPrefix returns the top directory the files of a zip all are in, e.g. "en_ult/", unless they are not or
+// more of their paths have a checksum with the directory than without it
+func zipPathPrefix(files []*zip.File, checksums map[string]string) string {
+	var prefix string
+	for _, f := range files {
+		i := strings.Index(f.Name, "/")
+		if i < 0 {
+			return ""
+		}
+		if prefix == "" {
+			prefix = f.Name[:i+1]
+		} else if f.Name[:i+1] != prefix {
+			return ""
+		}
+	}
+	var with, without int
+	for _, f := range files {
+		if _, ok := checksums[f.Name]; ok {
+			with++
+		}
+		if _, ok := checksums[strings.TrimPrefix(f.Name, prefix)]; ok {
+			without++
+		}
+	}
+	if with > without {
+		return ""
+	}
+	return prefix
+}
+
+// readZipChecksums returns the paths listed in the checksums file of a zip, nil if it has none or it cannot be parsed
+func readZipChecksums(files []*zip.File, prefix string) map[string]string {
+	for _, f := range files {
+		if strings.TrimPrefix(f.Name, prefix) != ChecksumsFile {
+			continue
+		}
+		reader, err := f.Open()
+		if err != nil {
+			return nil
+		}
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil
+		}
+		listed, err := ParseChecksums(string(content))
+		if err != nil {
+			return nil
+		}
+		return listed
+	}
+	return nil
+}
+
+// VerifyZipChecksums verifies the files of a zip, e.g. the zipball of a release, against SHA-256 checksums by path.
+// The top directory of the zip, if its files all are in one, is not part of their paths, and a checksums file
+// of the zip is only verified if it has a checksum. A zip with its own checksums file, e.g. the RC export of
+// a release which only has some of its files, is only missing the files that file lists, their checksums in the
+// zip not being trusted.
+func VerifyZipChecksums(reader *zip.Reader, checksums map[string]string) (*ChecksumsVerification, error) {
+	var files []*zip.File
+	for _, f := range reader.File {
+		if !f.FileInfo().IsDir() {
+			files = append(files, f)
+		}
+	}
+	prefix := zipPathPrefix(files, checksums)
+	var listed map[string]string
+	if _, ok := checksums[ChecksumsFile]; !ok {
+		listed = readZipChecksums(files, prefix)
+	}
+
+	verification := &ChecksumsVerification{
+		Matched:    []string{},
+		Mismatched: []string{},
+		Missing:    []string{},
+		Extra:      []string{},
+	}
+	found := make(map[string]bool, len(files))
+	for _, f := range files {
+		name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(f.Name, prefix)), "/")
+		sum, ok := checksums[name]
+		if !ok {
+			if name != ChecksumsFile {
+				verification.Extra = append(verification.Extra, name)
+			}
+			continue
+		}
+		found[name] = true
+		reader, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		if hex.EncodeToString(hash.Sum(nil)) == sum {
+			verification.Matched = append(verification.Matched, name)
+		} else {
+			verification.Mismatched = append(verification.Mismatched, name)
+		}
+	}
+	for name := range checksums {
+		if _, ok := listed[name]; listed != nil && !ok {
+			continue
+		}
+		if !found[name] {
+			verification.Missing = append(verification.Missing, name)
+		}
+	}
+
+	sort.Strings(verification.Matched)
+	sort.Strings(verification.Mismatched)
+	sort.Strings(verification.Missing)
+	sort.Strings(verification.Extra)
+	return verification, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFormatAndParseChecksums(t *testing.T) {
+	checksums := map[string]string{
+		"manifest.yaml":  sha256Hex("manifest"),
+		"01-GEN.usfm":    sha256Hex("gen"),
+		"with space.txt": sha256Hex(""),
+	}
+	content := FormatChecksums(checksums)
+	assert.Equal(t, sha256Hex("gen")+"  01-GEN.usfm\n"+
+		sha256Hex("manifest")+"  manifest.yaml\n"+
+		sha256Hex("")+"  with space.txt\n", content)
+
+	parsed, err := ParseChecksums(content)
+	assert.NoError(t, err)
+	assert.Equal(t, checksums, parsed)
+
+	parsed, err = ParseChecksums("\n" + sha256Hex("gen") + " *01-GEN.usfm\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"01-GEN.usfm": sha256Hex("gen")}, parsed)
+
+	_, err = ParseChecksums("d41d8cd98f00b204e9800998ecf8427e  md5.txt\n")
+	assert.Error(t, err)
+	_, err = ParseChecksums(sha256Hex("")[:63] + "x  file.txt\n")
+	assert.Error(t, err)
+}
+
+func createZip(t *testing.T, files map[string]string) *zip.Reader {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return reader
+}
+
+func TestVerifyZipChecksums(t *testing.T) {
+	checksums := map[string]string{
+		"manifest.yaml":      sha256Hex("manifest"),
+		"content/01-GEN.txt": sha256Hex("gen"),
+		"content/02-EXO.txt": sha256Hex("exo"),
+		"LICENSE.md":         sha256Hex("license"),
+	}
+
+	// The zipball of a release has the files in a top directory
+	verification, err := VerifyZipChecksums(createZip(t, map[string]string{
+		"en_ult/":                   "",
+		"en_ult/manifest.yaml":      "manifest",
+		"en_ult/content/01-GEN.txt": "gen",
+		"en_ult/content/02-EXO.txt": "exo",
+		"en_ult/LICENSE.md":         "license",
+		"en_ult/checksums.sha256":   FormatChecksums(checksums),
+	}), checksums)
+	assert.NoError(t, err)
+	assert.True(t, verification.Valid())
+	assert.Equal(t, []string{"LICENSE.md", "content/01-GEN.txt", "content/02-EXO.txt", "manifest.yaml"}, verification.Matched)
+	assert.Empty(t, verification.Extra)
+
+	verification, err = VerifyZipChecksums(createZip(t, map[string]string{
+		"manifest.yaml":      "manifest",
+		"content/01-GEN.txt": "genesis",
+		"LICENSE.md":         "license",
+		"notes.txt":          "notes",
+	}), checksums)
+	assert.NoError(t, err)
+	assert.False(t, verification.Valid())
+	assert.Equal(t, []string{"LICENSE.md", "manifest.yaml"}, verification.Matched)
+	assert.Equal(t, []string{"content/01-GEN.txt"}, verification.Mismatched)
+	assert.Equal(t, []string{"content/02-EXO.txt"}, verification.Missing)
+	assert.Equal(t, []string{"notes.txt"}, verification.Extra)
+
+	// The files of a release may all be in a directory
+	verification, err = VerifyZipChecksums(createZip(t, map[string]string{
+		"content/01-GEN.txt": "gen",
+		"content/02-EXO.txt": "exo",
+	}), checksums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"content/01-GEN.txt", "content/02-EXO.txt"}, verification.Matched)
+	assert.Equal(t, []string{"LICENSE.md", "manifest.yaml"}, verification.Missing)
+
+	// The RC export of a release only has some of its files, listed in its own checksums file
+	verification, err = VerifyZipChecksums(createZip(t, map[string]string{
+		"en_ult/manifest.yaml":      "manifest",
+		"en_ult/content/01-GEN.txt": "genesis",
+		"en_ult/checksums.sha256": FormatChecksums(map[string]string{
+			"manifest.yaml":      sha256Hex("manifest"),
+			"content/01-GEN.txt": sha256Hex("genesis"),
+			"LICENSE.md":         sha256Hex("license"),
+		}),
+	}), checksums)
+	assert.NoError(t, err)
+	assert.False(t, verification.Valid())
+	assert.Equal(t, []string{"manifest.yaml"}, verification.Matched)
+	assert.Equal(t, []string{"content/01-GEN.txt"}, verification.Mismatched)
+	assert.Equal(t, []string{"LICENSE.md"}, verification.Missing)
+	assert.Empty(t, verification.Extra)
+}
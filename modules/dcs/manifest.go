@@ -0,0 +1,62 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetDublinCoreString gets the string value at the given path of keys within the dublin_core of a manifest,
+// e.g. GetDublinCoreString(manifest, "language", "identifier")
+func GetDublinCoreString(manifest *map[string]interface{}, keys ...string) string {
+	if manifest == nil {
+		return ""
+	}
+	var value interface{} = (*manifest)["dublin_core"]
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = m[key]
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// CheckManifestConsistency checks that the manifest agrees with the {lang}_{subject} naming convention of the repo
+// and that dublin_core.version agrees with the release tag (if tagName is not empty).
+// Returns a description of each inconsistency found.
+func CheckManifestConsistency(repoName, tagName string, manifest *map[string]interface{}) []string {
+	var issues []string
+	if manifest == nil {
+		return issues
+	}
+
+	if lang := GetLanguageFromRepoName(strings.ToLower(repoName)); lang != "" {
+		if manifestLang := GetDublinCoreString(manifest, "language", "identifier"); !strings.EqualFold(manifestLang, lang) {
+			issues = append(issues, fmt.Sprintf("dublin_core.language.identifier \"%s\" does not match the language \"%s\" of the repo name \"%s\"", manifestLang, lang, repoName))
+		}
+		resourceID := strings.Split(strings.ToLower(repoName), "_")[1]
+		if identifier := GetDublinCoreString(manifest, "identifier"); !strings.EqualFold(identifier, resourceID) {
+			issues = append(issues, fmt.Sprintf("dublin_core.identifier \"%s\" does not match the resource \"%s\" of the repo name \"%s\"", identifier, resourceID, repoName))
+		}
+	}
+
+	if tagName != "" {
+		manifestVersion := GetDublinCoreString(manifest, "version")
+		tagVersion := GetSortableVersionFromString(tagName)
+		if tagVersion != "" && manifestVersion == "" {
+			issues = append(issues, fmt.Sprintf("dublin_core.version is missing for the release tag \"%s\"", tagName))
+		} else if tagVersion != "" && GetSortableVersionFromString(manifestVersion) != tagVersion {
+			issues = append(issues, fmt.Sprintf("dublin_core.version \"%s\" does not match the release tag \"%s\"", manifestVersion, tagName))
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckManifestConsistency(t *testing.T) {
+	// Avoid loading the language names from tD
+	langNames["en"] = map[string]interface{}{"lc": "en", "ln": "English"}
+
+	manifest := &map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"identifier": "tn",
+			"language": map[string]interface{}{
+				"identifier": "en",
+			},
+			"version": "20",
+		},
+	}
+
+	assert.Empty(t, CheckManifestConsistency("en_tn", "v20", manifest))
+	assert.Empty(t, CheckManifestConsistency("EN_TN", "", manifest))
+	assert.Empty(t, CheckManifestConsistency("my-notes", "v20", manifest))
+	assert.Len(t, CheckManifestConsistency("en_tq", "v20", manifest), 1)
+	assert.Len(t, CheckManifestConsistency("en_tn", "v21", manifest), 1)
+	assert.Len(t, CheckManifestConsistency("en_tq", "v21", manifest), 2)
+
+	(*manifest)["dublin_core"].(map[string]interface{})["language"].(map[string]interface{})["identifier"] = "fr"
+	assert.Len(t, CheckManifestConsistency("en_tn", "v20", manifest), 1)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MetadataChange is a change of a value of metadata, e.g. of a manifest, at a path such as
+// dublin_core.version or projects[0].identifier
+type MetadataChange struct {
+	Path string
+	// Old and New are the values before and after the change, nil if the path was added or removed
+	Old interface{}
+	New interface{}
+}
+
+// Kind returns whether the path of the change was "added", "removed" or "changed"
+func (c *MetadataChange) Kind() string {
+	switch {
+	case c.Old == nil:
+		return "added"
+	case c.New == nil:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// flattenMetadata adds the values of metadata that are neither maps nor lists, or are empty ones, by path
+func flattenMetadata(values map[string]interface{}, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			break
+		}
+		for key, child := range v {
+			if prefix == "" {
+				flattenMetadata(values, key, child)
+			} else {
+				flattenMetadata(values, prefix+"."+key, child)
+			}
+		}
+		return
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		for i, child := range v {
+			flattenMetadata(values, fmt.Sprintf("%s[%d]", prefix, i), child)
+		}
+		return
+	case nil:
+		return
+	}
+	if prefix != "" {
+		values[prefix] = value
+	}
+}
+
+// DiffMetadata returns the changes from the old to the new metadata, sorted by path. A nil metadata has no values.
+func DiffMetadata(oldMetadata, newMetadata *map[string]interface{}) []*MetadataChange {
+	oldValues := make(map[string]interface{})
+	if oldMetadata != nil {
+		flattenMetadata(oldValues, "", *oldMetadata)
+	}
+	newValues := make(map[string]interface{})
+	if newMetadata != nil {
+		flattenMetadata(newValues, "", *newMetadata)
+	}
+
+	var changes []*MetadataChange
+	for path, oldValue := range oldValues {
+		newValue, ok := newValues[path]
+		if !ok {
+			changes = append(changes, &MetadataChange{Path: path, Old: oldValue})
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, &MetadataChange{Path: path, Old: oldValue, New: newValue})
+		}
+	}
+	for path, newValue := range newValues {
+		if _, ok := oldValues[path]; !ok {
+			changes = append(changes, &MetadataChange{Path: path, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
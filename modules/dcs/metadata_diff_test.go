@@ -0,0 +1,56 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMetadata(t *testing.T) {
+	prod := map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"version":  "4",
+			"issued":   "2021-01-01",
+			"relation": []interface{}{"en/tw", "en/tn"},
+			"language": map[string]interface{}{"identifier": "en"},
+		},
+		"projects": []interface{}{
+			map[string]interface{}{"identifier": "gen", "sort": 1},
+			map[string]interface{}{"identifier": "exo", "sort": 2},
+		},
+	}
+	preview := map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"version":  "5",
+			"issued":   "2021-01-01",
+			"relation": []interface{}{"en/tw"},
+			"language": map[string]interface{}{"identifier": "en", "direction": "ltr"},
+		},
+		"projects": []interface{}{
+			map[string]interface{}{"identifier": "gen", "sort": 1},
+			map[string]interface{}{"identifier": "exo", "sort": 2},
+		},
+		"checking": map[string]interface{}{},
+	}
+
+	changes := DiffMetadata(&prod, &preview)
+	paths := make([]string, 0, len(changes))
+	kinds := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+		kinds = append(kinds, c.Kind())
+	}
+	assert.Equal(t, []string{"checking", "dublin_core.language.direction", "dublin_core.relation[1]", "dublin_core.version"}, paths)
+	assert.Equal(t, []string{"added", "added", "removed", "changed"}, kinds)
+	assert.Equal(t, "en/tn", changes[2].Old)
+	assert.Equal(t, "4", changes[3].Old)
+	assert.Equal(t, "5", changes[3].New)
+
+	assert.Empty(t, DiffMetadata(&prod, &prod))
+	assert.Len(t, DiffMetadata(nil, &prod), 9)
+	assert.Empty(t, DiffMetadata(nil, &map[string]interface{}{}))
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// OBSStoryFileRegex matches the file names of the stories of Open Bible Stories, 01.md to 50.md
+	OBSStoryFileRegex = regexp.MustCompile(`^(\d{2})\.md$`)
+
+	obsImageRegex     = regexp.MustCompile(`^!\[[^\]]*\]\(\s*<?([^\s>)]+)>?(?:\s+"[^"]*")?\s*\)$`)
+	obsReferenceRegex = regexp.MustCompile(`^_(.+)_$`)
+)
+
+// OBSFrame is a frame of a story of Open Bible Stories, an image with its markdown text
+type OBSFrame struct {
+	Image string
+	Text  string
+}
+
+// OBSStory is a story of Open Bible Stories, read from a file such as content/01.md
+type OBSStory struct {
+	Title     string
+	Frames    []*OBSFrame
+	Reference string // the Bible passages of the story, e.g. "A Bible story from: Genesis 1-2"
+}
+
+// ParseOBSStory parses the markdown of a story: a title heading, then an image starting each frame,
+// and the Bible reference of the story in italics on its last line
+func ParseOBSStory(data []byte) *OBSStory {
+	story := &OBSStory{}
+	var frame *OBSFrame
+	var lines []string
+	flush := func() {
+		if frame != nil {
+			frame.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if story.Title == "" && len(story.Frames) == 0 && strings.HasPrefix(trimmed, "# ") {
+			story.Title = strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+			continue
+		}
+		if matches := obsImageRegex.FindStringSubmatch(trimmed); matches != nil {
+			flush()
+			frame = &OBSFrame{Image: matches[1]}
+			story.Frames = append(story.Frames, frame)
+			continue
+		}
+		if frame == nil {
+			if trimmed == "" {
+				continue
+			}
+			// Text before the first image is a frame without an image
+			frame = &OBSFrame{}
+			story.Frames = append(story.Frames, frame)
+		}
+		lines = append(lines, line)
+	}
+
+	// The reference is the last line of the last frame
+	for i := len(lines) - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+		if matches := obsReferenceRegex.FindStringSubmatch(trimmed); matches != nil {
+			story.Reference = strings.TrimSpace(matches[1])
+			lines = lines[:i]
+		}
+		break
+	}
+	flush()
+
+	// The last frame may have been only the reference
+	if n := len(story.Frames); n > 0 && story.Frames[n-1].Image == "" && story.Frames[n-1].Text == "" {
+		story.Frames = story.Frames[:n-1]
+	}
+	return story
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOBSStory(t *testing.T) {
+	story := ParseOBSStory([]byte(`# 1. The Creation
+
+![OBS Image](https://cdn.door43.org/obs/jpg/360px/obs-en-01-01.jpg)
+
+This is how the beginning of everything happened.
+
+God created the universe.
+
+![OBS Image](https://cdn.door43.org/obs/jpg/360px/obs-en-01-02.jpg)
+
+But the earth was dark and empty.
+
+_A Bible story from: Genesis 1-2_
+`))
+	assert.Equal(t, "1. The Creation", story.Title)
+	assert.Equal(t, "A Bible story from: Genesis 1-2", story.Reference)
+	if assert.Len(t, story.Frames, 2) {
+		assert.Equal(t, "https://cdn.door43.org/obs/jpg/360px/obs-en-01-01.jpg", story.Frames[0].Image)
+		assert.Equal(t, "This is how the beginning of everything happened.\n\nGod created the universe.", story.Frames[0].Text)
+		assert.Equal(t, "https://cdn.door43.org/obs/jpg/360px/obs-en-01-02.jpg", story.Frames[1].Image)
+		assert.Equal(t, "But the earth was dark and empty.", story.Frames[1].Text)
+	}
+
+	story = ParseOBSStory([]byte("# Title\r\n\r\nSome text\r\n"))
+	assert.Equal(t, "Title", story.Title)
+	assert.Empty(t, story.Reference)
+	if assert.Len(t, story.Frames, 1) {
+		assert.Empty(t, story.Frames[0].Image)
+		assert.Equal(t, "Some text", story.Frames[0].Text)
+	}
+
+	story = ParseOBSStory([]byte("# Title\n\n_Genesis 1_\n"))
+	assert.Equal(t, "Genesis 1", story.Reference)
+	assert.Empty(t, story.Frames)
+
+	assert.True(t, OBSStoryFileRegex.MatchString("01.md"))
+	assert.False(t, OBSStoryFileRegex.MatchString("front.md"))
+}
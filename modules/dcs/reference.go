@@ -0,0 +1,114 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	referenceRegex  = regexp.MustCompile(`^\s*(.+?)\.?\s*(\d+)(?:\s*[:.]\s*(\d+)(?:\s*[-–]\s*(\d+))?)?\s*$`)
+	verseRangeRegex = regexp.MustCompile(`^(\d+)(?:[-–](\d+))?$`)
+
+	booksByName = func() map[string]*Book {
+		byName := make(map[string]*Book, len(Books))
+		for _, book := range Books {
+			byName[normalizeBookName(book.Name)] = book
+		}
+		return byName
+	}()
+)
+
+func normalizeBookName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
+
+// Reference is a reference to a chapter, a verse or a range of verses of a book of the Bible, e.g. TIT 1:3-5
+type Reference struct {
+	Book     *Book
+	Chapter  int
+	Verse    int // 0 for the whole chapter
+	EndVerse int // the last verse of a range of verses, else the verse
+}
+
+// ParseReference parses a reference such as "TIT 1:3", "tit 1:3-5", "Titus 1" or "1 John 2.1",
+// the book being given by its identifier or its name
+func ParseReference(str string) (*Reference, error) {
+	matches := referenceRegex.FindStringSubmatch(str)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid reference %q, expected a book, chapter and verse such as TIT 1:3", str)
+	}
+	book := GetBook(strings.TrimSpace(matches[1]))
+	if book == nil {
+		book = booksByName[normalizeBookName(matches[1])]
+	}
+	if book == nil {
+		return nil, fmt.Errorf("invalid reference %q, unknown book %q", str, strings.TrimSpace(matches[1]))
+	}
+	ref := &Reference{Book: book}
+	ref.Chapter, _ = strconv.Atoi(matches[2])
+	if matches[3] != "" {
+		ref.Verse, _ = strconv.Atoi(matches[3])
+		ref.EndVerse = ref.Verse
+	}
+	if matches[4] != "" {
+		ref.EndVerse, _ = strconv.Atoi(matches[4])
+		if ref.EndVerse < ref.Verse {
+			return nil, fmt.Errorf("invalid reference %q, the range of verses ends before it starts", str)
+		}
+	}
+	return ref, nil
+}
+
+// String returns the reference with the identifier of its book, e.g. TIT 1:3-5
+func (r *Reference) String() string {
+	str := fmt.Sprintf("%s %d", strings.ToUpper(r.Book.ID), r.Chapter)
+	if r.Verse > 0 {
+		str += fmt.Sprintf(":%d", r.Verse)
+		if r.EndVerse > r.Verse {
+			str += fmt.Sprintf("-%d", r.EndVerse)
+		}
+	}
+	return str
+}
+
+// MatchesVerse returns true if a chapter and verse of the book, such as "1" and "3" or "1" and "2-4",
+// is within the reference. A verse such as "intro" is only within a reference to a whole chapter.
+func (r *Reference) MatchesVerse(chapter, verse string) bool {
+	if c, err := strconv.Atoi(strings.TrimSpace(chapter)); err != nil || c != r.Chapter {
+		return false
+	}
+	if r.Verse == 0 {
+		return true
+	}
+	matches := verseRangeRegex.FindStringSubmatch(strings.TrimSpace(verse))
+	if matches == nil {
+		return false
+	}
+	start, _ := strconv.Atoi(matches[1])
+	end := start
+	if matches[2] != "" {
+		end, _ = strconv.Atoi(matches[2])
+	}
+	return start <= r.EndVerse && end >= r.Verse
+}
+
+// MatchesRef returns true if a reference of the book as found in TSV files, such as "1:3", "1:3-5", "1:3,5"
+// or "1:intro", is within the reference
+func (r *Reference) MatchesRef(ref string) bool {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	for _, verse := range strings.Split(parts[1], ",") {
+		if r.MatchesVerse(parts[0], verse) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	for str, expected := range map[string]string{
+		"TIT 1:3":           "TIT 1:3",
+		"tit 1:3-5":         "TIT 1:3-5",
+		"Titus 1":           "TIT 1",
+		"1 John 2.1":        "1JN 2:1",
+		"1jn 2:1":           "1JN 2:1",
+		"song of solomon 2": "SNG 2",
+	} {
+		ref, err := ParseReference(str)
+		if assert.NoError(t, err, str) {
+			assert.Equal(t, expected, ref.String())
+		}
+	}
+
+	for _, str := range []string{"", "TIT", "XYZ 1:3", "TIT 1:5-3"} {
+		_, err := ParseReference(str)
+		assert.Error(t, err, str)
+	}
+}
+
+func TestReferenceMatches(t *testing.T) {
+	ref, err := ParseReference("TIT 1:3-5")
+	assert.NoError(t, err)
+	assert.True(t, ref.MatchesVerse("1", "3"))
+	assert.True(t, ref.MatchesVerse("1", "5-6"))
+	assert.True(t, ref.MatchesVerse("1", "1-3"))
+	assert.False(t, ref.MatchesVerse("1", "6"))
+	assert.False(t, ref.MatchesVerse("2", "3"))
+	assert.False(t, ref.MatchesVerse("1", "intro"))
+	assert.True(t, ref.MatchesRef("1:4"))
+	assert.True(t, ref.MatchesRef("1:1,4"))
+	assert.False(t, ref.MatchesRef("front:intro"))
+
+	ref, err = ParseReference("TIT 1")
+	assert.NoError(t, err)
+	assert.True(t, ref.MatchesRef("1:intro"))
+	assert.True(t, ref.MatchesVerse("1", "16"))
+	assert.False(t, ref.MatchesRef("2:1"))
+}
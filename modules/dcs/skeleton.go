@@ -0,0 +1,75 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// An ATX heading, e.g. ## Translation Suggestions
+	markdownHeadingRegex = regexp.MustCompile(`^\s{0,3}(#{1,6})(?:\s|$)`)
+	// A line that is only an image, e.g. ![OBS Image](https://cdn.door43.org/obs/jpg/360px/obs-en-01-01.jpg)
+	markdownImageRegex = regexp.MustCompile(`^\s*!\[[^\]]*\]\([^)]*\)\s*$`)
+)
+
+// tsvKeyColumns are the columns of the TSV files of tN, tQ and tWL that identify a row or refer to the
+// original language rather than being text to translate
+var tsvKeyColumns = map[string]bool{
+	"Book":             true,
+	"Chapter":          true,
+	"Verse":            true,
+	"Reference":        true,
+	"ID":               true,
+	"Tags":             true,
+	"SupportReference": true,
+	"OrigQuote":        true,
+	"Quote":            true,
+	"Occurrence":       true,
+	"OrigWords":        true,
+	"TWLink":           true,
+}
+
+// MarkdownSkeleton returns the skeleton of a markdown file to translate it from: its headings without their text
+// and its images, each in a paragraph of its own
+func MarkdownSkeleton(markdown string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if matches := markdownHeadingRegex.FindStringSubmatch(line); matches != nil {
+			lines = append(lines, matches[1]+" ")
+		} else if markdownImageRegex.MatchString(line) {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n\n") + "\n"
+}
+
+// TSVSkeleton returns the skeleton of a TSV file to translate it from: its header and rows with only the
+// columns that identify each row or refer to the original language, the others being emptied and those
+// beyond the header dropped
+func TSVSkeleton(tsv string) string {
+	lines := strings.Split(strings.ReplaceAll(tsv, "\r\n", "\n"), "\n")
+	header := strings.Split(lines[0], "\t")
+	for i, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		cells := strings.Split(line, "\t")
+		if len(cells) > len(header) {
+			cells = cells[:len(header)]
+		}
+		for j := range cells {
+			if !tsvKeyColumns[header[j]] {
+				cells[j] = ""
+			}
+		}
+		lines[i+1] = strings.Join(cells, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownSkeleton(t *testing.T) {
+	assert.Equal(t, `# 
+
+![OBS Image](https://cdn.door43.org/obs/jpg/360px/obs-en-01-01.jpg)
+
+## 
+`, MarkdownSkeleton("# 1. The Creation\r\n\r\n![OBS Image](https://cdn.door43.org/obs/jpg/360px/obs-en-01-01.jpg)\r\n\r\nThis is how God made everything in the beginning.\r\n\r\n## Note\r\n#hashtag is not a heading\r\n_A Bible story from: Genesis 1-2_"))
+
+	assert.Equal(t, "", MarkdownSkeleton("Introduction to translationAcademy"))
+}
+
+func TestTSVSkeleton(t *testing.T) {
+	assert.Equal(t, "Reference\tID\tTags\tSupportReference\tQuote\tOccurrence\tNote\n"+
+		"1:1\tabcd\tkeyterm\trc://*/ta/man/translate/figs-metaphor\tΠαῦλος\t1\t\n"+
+		"1:2\tefgh\t\t\t\t0\t\n",
+		TSVSkeleton("Reference\tID\tTags\tSupportReference\tQuote\tOccurrence\tNote\r\n"+
+			"1:1\tabcd\tkeyterm\trc://*/ta/man/translate/figs-metaphor\tΠαῦλος\t1\tPaul is speaking\r\n"+
+			"1:2\tefgh\t\t\t\t0\tThe book starts\textra\r\n"))
+
+	// A column that is not known to be a key is emptied
+	assert.Equal(t, "Reference\tQuestion\tResponse\n1:1\t\t\n", TSVSkeleton("Reference\tQuestion\tResponse\n1:1\tWho?\tPaul.\n"))
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"gopkg.in/yaml.v2"
+)
+
+// TocFileName is the name of the file with the table of contents of a Translation Academy manual
+const TocFileName = "toc.yaml"
+
+// TaArticleFiles are the files of the folder of a Translation Academy article, in reading order
+var TaArticleFiles = []string{"title.md", "sub-title.md", "01.md"}
+
+// TocSection is a section of the table of contents of a Translation Academy manual. A section with a link is an
+// article, the link being the name of its folder, and a section can have sub-sections with or without a link.
+type TocSection struct {
+	Title    string        `yaml:"title"`
+	Link     string        `yaml:"link"`
+	Sections []*TocSection `yaml:"sections"`
+}
+
+// Toc is the table of contents of a Translation Academy manual, read from the toc.yaml file of its folder
+type Toc struct {
+	Title    string        `yaml:"title"`
+	Sections []*TocSection `yaml:"sections"`
+}
+
+// TocEntry is a section of a table of contents with its depth in the tree, the top sections having a depth of 0
+type TocEntry struct {
+	*TocSection
+	Depth int
+}
+
+// ParseToc parses the contents of a toc.yaml file
+func ParseToc(data []byte) (*Toc, error) {
+	toc := &Toc{}
+	if err := yaml.Unmarshal(data, toc); err != nil {
+		return nil, err
+	}
+	return toc, nil
+}
+
+// Entries returns all the sections of the table of contents in reading order
+func (t *Toc) Entries() []*TocEntry {
+	var entries []*TocEntry
+	var walk func(sections []*TocSection, depth int)
+	walk = func(sections []*TocSection, depth int) {
+		for _, section := range sections {
+			if section == nil {
+				continue
+			}
+			entries = append(entries, &TocEntry{TocSection: section, Depth: depth})
+			walk(section.Sections, depth+1)
+		}
+	}
+	walk(t.Sections, 0)
+	return entries
+}
+
+// Path returns the sections from the top of the tree down to the article with the given link,
+// or nil if no section links to it
+func (t *Toc) Path(link string) []*TocSection {
+	var find func(sections []*TocSection) []*TocSection
+	find = func(sections []*TocSection) []*TocSection {
+		for _, section := range sections {
+			if section == nil {
+				continue
+			}
+			if section.Link == link {
+				return []*TocSection{section}
+			}
+			if path := find(section.Sections); path != nil {
+				return append([]*TocSection{section}, path...)
+			}
+		}
+		return nil
+	}
+	if link == "" {
+		return nil
+	}
+	return find(t.Sections)
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToc(t *testing.T) {
+	toc, err := ParseToc([]byte(`title: "Table of Contents"
+sections:
+  - title: "Introduction to Translation Manual"
+    link: translate-manual
+  - title: "Figures of Speech"
+    sections:
+      - title: "Metaphor"
+        link: figs-metaphor
+      - title: "Simile"
+        link: figs-simile
+  - title: "Translation Difficulties"
+    link: translation-difficulty
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Table of Contents", toc.Title)
+
+	entries := toc.Entries()
+	if assert.Len(t, entries, 5) {
+		assert.Equal(t, "translate-manual", entries[0].Link)
+		assert.Equal(t, 0, entries[0].Depth)
+		assert.Equal(t, "Figures of Speech", entries[1].Title)
+		assert.Empty(t, entries[1].Link)
+		assert.Equal(t, "figs-metaphor", entries[2].Link)
+		assert.Equal(t, 1, entries[2].Depth)
+		assert.Equal(t, "translation-difficulty", entries[4].Link)
+		assert.Equal(t, 0, entries[4].Depth)
+	}
+
+	path := toc.Path("figs-simile")
+	if assert.Len(t, path, 2) {
+		assert.Equal(t, "Figures of Speech", path[0].Title)
+		assert.Equal(t, "Simile", path[1].Title)
+	}
+	assert.Len(t, toc.Path("translate-manual"), 1)
+	assert.Nil(t, toc.Path("figs-unknown"))
+	assert.Nil(t, toc.Path(""))
+
+	_, err = ParseToc([]byte("sections: [misformatted"))
+	assert.Error(t, err)
+}
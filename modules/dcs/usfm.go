@@ -0,0 +1,108 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// The alignment milestones, e.g. \zaln-s |x-strong="G0976" x-content="Βίβλος"\* and \zaln-e\*
+	usfmAlignmentRegex = regexp.MustCompile(`\\zaln-[se][^\\]*\\\*`)
+	// The words of an alignment with their attributes, e.g. \w book|x-occurrence="1" x-occurrences="1"\w*
+	usfmWordRegex   = regexp.MustCompile(`\\\+?w\s+([^|\\]*?)\s*(?:\|[^\\]*)?\\\+?w\*`)
+	usfmMarkerRegex = regexp.MustCompile(`\\\+?[A-Za-z][A-Za-z0-9-]*\*?|\\\*`)
+	usfmVerseRegex  = regexp.MustCompile(`\\([cv])\s+(\S+)`)
+
+	bookInFileNameRegex = regexp.MustCompile(`(?i)(?:^|[_-])([1-3a-z][a-z]{2})\.(?:usfm3?|sfm|tsv)$`)
+)
+
+// GetBookFromFileName gets the book identifier from a file name such as 01-GEN.usfm or en_tn_01-GEN.tsv,
+// returning an empty string if it has none
+func GetBookFromFileName(name string) string {
+	if matches := bookInFileNameRegex.FindStringSubmatch(name); matches != nil {
+		return strings.ToLower(matches[1])
+	}
+	return ""
+}
+
+// UsfmText returns the text of USFM markup, without its alignments, attributes and markers
+func UsfmText(usfm string) string {
+	text := usfmAlignmentRegex.ReplaceAllString(usfm, " ")
+	text = usfmWordRegex.ReplaceAllString(text, " $1 ")
+	text = usfmMarkerRegex.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ParseUsfmVerses returns the refs of the verses of a USFM file in their order, e.g. 3:16 or 3:16-17,
+// and the text of each verse. The text of a chapter before its first verse has the verse 0,
+// and the text before the first chapter has the ref 0:0.
+func ParseUsfmVerses(usfm string) ([]string, map[string]string) {
+	var refs []string
+	verses := make(map[string]string)
+	addText := func(ref, usfm string) {
+		text := UsfmText(usfm)
+		if text == "" {
+			return
+		}
+		if existing, ok := verses[ref]; ok {
+			verses[ref] = existing + " " + text
+			return
+		}
+		refs = append(refs, ref)
+		verses[ref] = text
+	}
+
+	chapter, verse, start := "0", "0", 0
+	for _, match := range usfmVerseRegex.FindAllStringSubmatchIndex(usfm, -1) {
+		addText(chapter+":"+verse, usfm[start:match[0]])
+		if usfm[match[2]:match[3]] == "c" {
+			chapter, verse = usfm[match[4]:match[5]], "0"
+		} else {
+			verse = usfm[match[4]:match[5]]
+		}
+		start = match[1]
+	}
+	addText(chapter+":"+verse, usfm[start:])
+	return refs, verses
+}
+
+var (
+	usfmIDRegex       = regexp.MustCompile(`\\id\s+(\S+)`)
+	usfmSkeletonRegex = regexp.MustCompile(`\\(usfm|ide|c|v)\s+(\S+)|\\(p|m|b|q[1-4]?|nb)\b`)
+)
+
+// UsfmSkeleton returns the skeleton of a USFM file to translate it from: its identification and empty headers,
+// then its markers of chapters, paragraphs, poetry and verses without their text
+func UsfmSkeleton(usfm string) string {
+	var lines []string
+	if matches := usfmIDRegex.FindStringSubmatch(usfm); matches != nil {
+		lines = append(lines, `\id `+matches[1])
+	}
+	headers := false
+	content := usfmAlignmentRegex.ReplaceAllString(usfm, " ")
+	for _, match := range usfmSkeletonRegex.FindAllStringSubmatch(content, -1) {
+		switch match[1] {
+		case "usfm", "ide":
+			lines = append(lines, `\`+match[1]+" "+match[2])
+			continue
+		case "c":
+			if !headers {
+				lines = append(lines, `\h`, `\toc1`, `\toc2`, `\toc3`, `\mt`)
+				headers = true
+			}
+			lines = append(lines, `\c `+match[2])
+			continue
+		case "v":
+			lines = append(lines, `\v `+match[2])
+			continue
+		}
+		if headers {
+			lines = append(lines, `\`+match[3])
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	usfmTokenRegex      = regexp.MustCompile(`\\(\+?[A-Za-z][A-Za-z0-9-]*\*?|\*)`)
+	usfmWhitespaceRegex = regexp.MustCompile(`\s+`)
+	usfmMarkerNumRegex  = regexp.MustCompile(`^([a-z-]+?)[0-9]*$`)
+)
+
+// usfmHeaderMarkers are the markers whose text isn't shown, such as the identification and the table of contents
+var usfmHeaderMarkers = map[string]bool{
+	"id": true, "ide": true, "usfm": true, "h": true, "toc": true, "toca": true, "rem": true, "sts": true,
+	"cl": true, "cp": true, "ca": true, "va": true, "vp": true, "restore": true,
+}
+
+// usfmTitleMarkers are the markers of the main title of a book
+var usfmTitleMarkers = map[string]bool{"mt": true, "mte": true}
+
+// usfmHeadingMarkers are the markers of the section headings
+var usfmHeadingMarkers = map[string]bool{"ms": true, "s": true, "is": true, "imt": true}
+
+// usfmParagraphMarkers are the markers of the paragraphs, including the poetry and the references of the headings
+var usfmParagraphMarkers = map[string]bool{
+	"p": true, "m": true, "po": true, "pr": true, "cls": true, "pmo": true, "pm": true, "pmc": true, "pmr": true,
+	"pi": true, "mi": true, "nb": true, "pc": true, "ph": true, "li": true, "lh": true, "lf": true, "lim": true,
+	"q": true, "qr": true, "qc": true, "qa": true, "qm": true, "qd": true, "d": true, "sp": true, "r": true,
+	"sr": true, "mr": true, "sd": true, "ip": true, "ipi": true, "im": true, "imi": true, "ipq": true, "imq": true,
+	"ipr": true, "iq": true, "ili": true, "iot": true, "io": true, "ie": true, "lit": true, "tr": true,
+}
+
+// usfmHTMLWriter writes the HTML of USFM markup as it is tokenized
+type usfmHTMLWriter struct {
+	builder  bytes.Buffer
+	chapter  string
+	block    string // the closing tag of the block being written, empty if none
+	spans    []string
+	skip     bool // whether the text is skipped until the next marker
+	note     string
+	noteText *strings.Builder
+}
+
+func (w *usfmHTMLWriter) closeSpans() {
+	for range w.spans {
+		w.builder.WriteString("</span>")
+	}
+	w.spans = nil
+}
+
+func (w *usfmHTMLWriter) closeBlock() {
+	for bytes.HasSuffix(w.builder.Bytes(), []byte(" ")) {
+		w.builder.Truncate(w.builder.Len() - 1)
+	}
+	w.closeSpans()
+	if w.block != "" {
+		w.builder.WriteString(w.block + "\n")
+		w.block = ""
+	}
+}
+
+func (w *usfmHTMLWriter) openBlock(tag, class string) {
+	w.closeBlock()
+	w.builder.WriteString("<" + tag + ` class="` + class + `">`)
+	w.block = "</" + tag + ">"
+}
+
+// ensureBlock opens a paragraph if no block is being written
+func (w *usfmHTMLWriter) ensureBlock() {
+	if w.block == "" {
+		w.openBlock("p", "p")
+	}
+}
+
+func (w *usfmHTMLWriter) writeText(text string) {
+	if w.skip {
+		return
+	}
+	if w.noteText != nil {
+		w.noteText.WriteString(text)
+		return
+	}
+	if w.block == "" {
+		text = strings.TrimLeft(text, " ")
+		if text == "" {
+			return
+		}
+		w.ensureBlock()
+	}
+	w.builder.WriteString(html.EscapeString(text))
+}
+
+func (w *usfmHTMLWriter) writeMarker(marker, text string) {
+	w.skip = false
+	closing := strings.HasSuffix(marker, "*")
+	name := strings.TrimSuffix(strings.TrimPrefix(marker, "+"), "*")
+	base := name
+	if matches := usfmMarkerNumRegex.FindStringSubmatch(name); matches != nil {
+		base = matches[1]
+	}
+
+	// Footnotes and cross references, whose text is only that of their content markers
+	if w.note != "" {
+		if closing && name == w.note {
+			if w.note != "x" && w.noteText != nil {
+				if content := strings.TrimSpace(w.noteText.String()); content != "" {
+					w.ensureBlock()
+					w.builder.WriteString(`<span class="footnote">` + html.EscapeString(content) + `</span>`)
+				}
+			}
+			w.note, w.noteText = "", nil
+			w.writeText(text)
+			return
+		}
+		if w.noteText != nil && !closing && (name == "ft" || name == "fq" || name == "fqa" || name == "fk" || name == "fl") {
+			w.noteText.WriteString(" " + strings.TrimSpace(text))
+		}
+		return
+	}
+
+	switch {
+	case name == "":
+		w.writeText(text)
+	case closing:
+		if len(w.spans) > 0 {
+			w.spans = w.spans[:len(w.spans)-1]
+			w.builder.WriteString("</span>")
+		}
+		w.writeText(text)
+	case name == "f" || name == "fe" || name == "x" || name == "ef" || name == "ex":
+		// The caller of a note, e.g. + in \f + \ft text\f*, is dropped
+		w.note = name
+		w.noteText = new(strings.Builder)
+	case name == "c":
+		w.closeBlock()
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return
+		}
+		w.chapter = fields[0]
+		w.builder.WriteString(`<h2 class="c" id="chapter-` + html.EscapeString(w.chapter) + `">` + html.EscapeString(w.chapter) + "</h2>\n")
+		w.writeText(strings.TrimPrefix(text, fields[0]))
+	case name == "v":
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return
+		}
+		w.ensureBlock()
+		w.builder.WriteString(`<sup class="v" id="verse-` + html.EscapeString(w.chapter+"-"+fields[0]) + `">` + html.EscapeString(fields[0]) + "</sup>")
+		w.writeText(" " + strings.TrimLeft(strings.TrimPrefix(strings.TrimLeft(text, " "), fields[0]), " "))
+	case usfmHeaderMarkers[base], strings.HasSuffix(name, "-s"), strings.HasSuffix(name, "-e"):
+		// The attributes of a milestone, e.g. \qt-s |who="Paul"\*, are skipped like the headers
+		w.skip = true
+	case usfmTitleMarkers[base]:
+		w.openBlock("h1", name)
+		w.writeText(strings.TrimLeft(text, " "))
+	case usfmHeadingMarkers[base]:
+		w.openBlock("h3", name)
+		w.writeText(strings.TrimLeft(text, " "))
+	case usfmParagraphMarkers[base]:
+		w.openBlock("p", name)
+		w.writeText(strings.TrimLeft(text, " "))
+	case name == "b" || name == "ib":
+		w.closeBlock()
+		w.writeText(strings.TrimLeft(text, " "))
+	default:
+		// A character style such as \nd or \wj, closed by its marker with a *
+		w.ensureBlock()
+		w.builder.WriteString(`<span class="` + html.EscapeString(name) + `">`)
+		w.spans = append(w.spans, name)
+		w.writeText(text)
+	}
+}
+
+// UsfmToHTML renders USFM markup as an XHTML fragment: the title, headings and paragraphs of a book, with
+// its chapters as h2 elements of ids such as chapter-3 and its verses as sup elements of ids such as verse-3-16.
+// Alignments are removed and footnotes are kept inline, but cross references and headers aren't shown.
+func UsfmToHTML(usfm string) string {
+	content := usfmAlignmentRegex.ReplaceAllString(usfm, "")
+	content = usfmWordRegex.ReplaceAllString(content, "$1")
+	content = usfmWhitespaceRegex.ReplaceAllString(content, " ")
+
+	w := &usfmHTMLWriter{}
+	matches := usfmTokenRegex.FindAllStringSubmatchIndex(content, -1)
+	start := len(content)
+	if len(matches) > 0 {
+		start = matches[0][0]
+	}
+	w.writeText(content[:start])
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		text := content[match[1]:end]
+		if !strings.HasSuffix(content[match[2]:match[3]], "*") {
+			// The space after an opening marker only separates it from its text
+			text = strings.TrimPrefix(text, " ")
+		}
+		w.writeMarker(content[match[2]:match[3]], text)
+	}
+	w.closeBlock()
+	return w.builder.String()
+}
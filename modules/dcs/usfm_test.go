@@ -0,0 +1,94 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsfmText(t *testing.T) {
+	assert.Equal(t, "The book of the genealogy",
+		UsfmText(`\zaln-s |x-strong="G0976" x-lemma="βίβλος" x-occurrence="1" x-occurrences="1" x-content="Βίβλος"\*\w The|x-occurrence="1" x-occurrences="1"\w*
+\w book|x-occurrence="1" x-occurrences="1"\w*\zaln-e\*
+\w of|x-occurrence="1" x-occurrences="1"\w* \w the\w* \p genealogy`))
+	assert.Equal(t, "In the beginning", UsfmText(`\q1 \+w In\+w* the beginning`))
+}
+
+func TestParseUsfmVerses(t *testing.T) {
+	refs, verses := ParseUsfmVerses(`\id TIT
+\c 1
+\p
+\v 1 Paul, a servant of God
+\v 2-3 in hope
+\c 2
+\s Heading
+\v 1 But you`)
+	assert.Equal(t, []string{"0:0", "1:1", "1:2-3", "2:0", "2:1"}, refs)
+	assert.Equal(t, "TIT", verses["0:0"])
+	assert.Equal(t, "in hope", verses["1:2-3"])
+	assert.Equal(t, "Heading", verses["2:0"])
+}
+
+func TestGetBookFromFileName(t *testing.T) {
+	assert.Equal(t, "tit", GetBookFromFileName("57-TIT.usfm"))
+	assert.Equal(t, "tit", GetBookFromFileName("en_tn_57-TIT.tsv"))
+	assert.Equal(t, "1jn", GetBookFromFileName("tn_1JN.tsv"))
+	assert.Empty(t, GetBookFromFileName("README.md"))
+}
+
+func TestUsfmSkeleton(t *testing.T) {
+	assert.Equal(t, `\id TIT
+\usfm 3.0
+\ide UTF-8
+\h
+\toc1
+\toc2
+\toc3
+\mt
+\c 1
+\p
+\v 1
+\v 2-3
+\q1
+\v 4
+`, UsfmSkeleton(`\id TIT EN_ULT en_English_ltr
+\usfm 3.0
+\ide UTF-8
+\h Titus
+\toc1 The Letter of Paul to Titus
+\mt Titus
+\c 1
+\p
+\v 1 \zaln-s |x-strong="G39720" x-content="Παῦλος"\*\w Paul|x-occurrence="1" x-occurrences="1"\w*\zaln-e\*,
+\v 2-3 in hope
+\q1 \v 4 To Titus`))
+}
+
+func TestUsfmToHTML(t *testing.T) {
+	assert.Equal(t, `<h1 class="mt1">Titus</h1>
+<h2 class="c" id="chapter-1">1</h2>
+<h3 class="s1">Greeting</h3>
+<p class="p"><sup class="v" id="verse-1-1">1</sup> Paul, a servant of <span class="nd">God</span><span class="footnote">Or: the Lord</span> &amp; an apostle</p>
+<p class="q1"><sup class="v" id="verse-1-2-3">2-3</sup> in hope</p>
+<h2 class="c" id="chapter-2">2</h2>
+<p class="p"><sup class="v" id="verse-2-1">1</sup> But you, speak</p>
+`, UsfmToHTML(`\id TIT EN_ULT
+\usfm 3.0
+\h Titus
+\toc1 The Letter of Paul to Titus
+\mt1 Titus
+\c 1
+\s1 Greeting
+\p
+\v 1 \zaln-s |x-strong="G3972" x-content="Παῦλος"\*\w Paul|x-occurrence="1" x-occurrences="1"\w*\zaln-e\*, a servant of \nd God\nd*\f + \fr 1:1 \ft Or: the Lord\f* & an apostle
+\q1
+\v 2-3 in hope\x - \xo 1:2 \xt Rom 1:1\x*
+\c 2
+\p
+\v 1 But you, \qt-s |who="Paul"\*speak`))
+	assert.Equal(t, "<p class=\"p\">Just text</p>\n", UsfmToHTML("Just text"))
+}
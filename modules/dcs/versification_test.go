@@ -0,0 +1,45 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersification(t *testing.T) {
+	total := 0
+	for _, book := range Books {
+		assert.NotEmpty(t, book.ChapterVerses(), book.ID)
+		total += book.VerseCount()
+	}
+	assert.Equal(t, 31102, total)
+	assert.Equal(t, 1533, GetBook("gen").VerseCount())
+	assert.Equal(t, []int{16, 15, 15}, GetBook("tit").ChapterVerses())
+}
+
+func TestCountUsfmVerses(t *testing.T) {
+	tit := GetBook("tit")
+	assert.Equal(t, 0, CountUsfmVerses(`\id TIT
+\h Titus
+\c 1
+\p
+\v 1
+\v 2`, tit))
+	assert.Equal(t, 4, CountUsfmVerses(`\id TIT
+\c 1
+\p
+\v 1 Paul, a servant of God
+\v 2-3 in hope
+\v 4
+\c 2
+\s Heading
+\v 1 But you
+\v 1 again
+\v 16 beyond the chapter
+\c 4
+\v 1 beyond the book`, tit))
+}
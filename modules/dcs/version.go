@@ -0,0 +1,175 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// versionSegmentWidth is the zero padded width of each segment of a sortable version
+const versionSegmentWidth = 10
+
+// versionMinSegments is the minimum number of segments of a sortable version, so 1.2.3 and 1.2.3.1 compare correctly
+const versionMinSegments = 4
+
+var versionConstraintRegexp = regexp.MustCompile(`^\s*(=|!=|>=|<=|>|<|~>|\^)?\s*(\S+)\s*$`)
+
+// ParseVersion parses a release tag or a dublin_core.version value as a version, ignoring a leading "v"
+func ParseVersion(str string) (*version.Version, error) {
+	str = strings.TrimSpace(str)
+	if len(str) > 1 && (str[0] == 'v' || str[0] == 'V') {
+		str = str[1:]
+	}
+	return version.NewVersion(str)
+}
+
+// GetSortableVersion returns a string for the given version that sorts the same way as the version does,
+// e.g. "v9" => "0000000009.0000000000.0000000000.0000000000~" sorts before "v10" => "0000000010.0000000000.0000000000.0000000000~"
+func GetSortableVersion(v *version.Version) string {
+	if v == nil {
+		return ""
+	}
+	segments := v.Segments64()
+	for len(segments) < versionMinSegments {
+		segments = append(segments, 0)
+	}
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		parts[i] = fmt.Sprintf("%0*d", versionSegmentWidth, segment)
+	}
+	sortable := strings.Join(parts, ".")
+	// A prerelease comes before its release, and "-" sorts before "~"
+	if v.Prerelease() != "" {
+		return sortable + "-" + v.Prerelease()
+	}
+	return sortable + "~"
+}
+
+// GetSortableVersionFromString parses the given tag or version string and returns its sortable string,
+// or an empty string if it is not a valid version
+func GetSortableVersionFromString(str string) string {
+	v, err := ParseVersion(str)
+	if err != nil {
+		return ""
+	}
+	return GetSortableVersion(v)
+}
+
+// VersionConstraint is a single operator and version, e.g. ">=20"
+type VersionConstraint struct {
+	Operator string
+	Version  *version.Version
+}
+
+// VersionConstraints is a list of constraints that all must be met
+type VersionConstraints []*VersionConstraint
+
+// ParseVersionConstraints parses a comma separated list of constraints, e.g. ">=20,<30".
+// Supported operators are =, !=, >, >=, <, <=, ~> (pessimistic) and ^ (same major version)
+func ParseVersionConstraints(str string) (VersionConstraints, error) {
+	var constraints VersionConstraints
+	for _, part := range strings.Split(str, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		matches := versionConstraintRegexp.FindStringSubmatch(part)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid version constraint: \"%s\"", part)
+		}
+		v, err := ParseVersion(matches[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint: \"%s\"", part)
+		}
+		operator := matches[1]
+		if operator == "" {
+			operator = "="
+		}
+		constraints = append(constraints, &VersionConstraint{Operator: operator, Version: v})
+	}
+	return constraints, nil
+}
+
+// Range returns the inclusive lower and exclusive upper sortable versions that satisfy the constraint.
+// An empty string means that side is unbounded. Used for "~>" and "^".
+func (c *VersionConstraint) Range() (string, string) {
+	lower := GetSortableVersion(c.Version.Core())
+	segments := c.Version.Segments64()
+	var upper []int64
+	switch c.Operator {
+	case "~>":
+		// ~>1.2.3 means >=1.2.3,<1.3 and ~>1.2 means >=1.2,<2
+		bump := specifiedSegments(c.Version) - 2
+		if bump < 0 {
+			bump = 0
+		}
+		upper = append(upper, segments[:bump+1]...)
+		upper[bump]++
+	case "^":
+		// ^1.2.3 means >=1.2.3,<2
+		upper = []int64{segments[0] + 1}
+	default:
+		return "", ""
+	}
+	upperStrs := make([]string, len(upper))
+	for i, segment := range upper {
+		upperStrs[i] = fmt.Sprint(segment)
+	}
+	upperVersion, err := version.NewVersion(strings.Join(upperStrs, "."))
+	if err != nil {
+		return lower, ""
+	}
+	// The upper bound excludes prereleases of the next version
+	return lower, strings.TrimSuffix(GetSortableVersion(upperVersion), "~")
+}
+
+// specifiedSegments returns how many segments were given in the original version string, e.g. 2 for "v1.2"
+func specifiedSegments(v *version.Version) int {
+	core := strings.TrimLeft(strings.TrimSpace(v.Original()), "vV")
+	if idx := strings.IndexAny(core, "-+"); idx >= 0 {
+		core = core[:idx]
+	}
+	return len(strings.Split(core, "."))
+}
+
+// Check returns true if the given version satisfies the constraint
+func (c *VersionConstraint) Check(v *version.Version) bool {
+	if v == nil {
+		return false
+	}
+	cmp := GetSortableVersion(v)
+	sortable := GetSortableVersion(c.Version)
+	switch c.Operator {
+	case "=":
+		return cmp == sortable
+	case "!=":
+		return cmp != sortable
+	case ">":
+		return cmp > sortable
+	case ">=":
+		return cmp >= sortable
+	case "<":
+		return cmp < sortable
+	case "<=":
+		return cmp <= sortable
+	case "~>", "^":
+		lower, upper := c.Range()
+		return cmp >= lower && (upper == "" || cmp < upper)
+	}
+	return false
+}
+
+// Check returns true if the given version satisfies all of the constraints
+func (cs VersionConstraints) Check(v *version.Version) bool {
+	for _, c := range cs {
+		if !c.Check(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSortableVersionFromString(t *testing.T) {
+	assert.True(t, GetSortableVersionFromString("v9") < GetSortableVersionFromString("v10"))
+	assert.True(t, GetSortableVersionFromString("v10-beta") < GetSortableVersionFromString("v10"))
+	assert.True(t, GetSortableVersionFromString("1.2.3") < GetSortableVersionFromString("1.2.3.1"))
+	assert.Equal(t, GetSortableVersionFromString("v20"), GetSortableVersionFromString("20.0"))
+	assert.Equal(t, "", GetSortableVersionFromString("master"))
+}
+
+func TestParseVersionConstraints(t *testing.T) {
+	constraints, err := ParseVersionConstraints(">=20, <30")
+	assert.NoError(t, err)
+	assert.Len(t, constraints, 2)
+
+	_, err = ParseVersionConstraints(">=master")
+	assert.Error(t, err)
+
+	kases := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{">=20", "v20", true},
+		{">=20", "v9", false},
+		{">=20", "v20-rc1", false},
+		{"20", "v20.0", true},
+		{"!=20", "v21", true},
+		{">=20,<30", "v30", false},
+		{"~>1.2", "v1.9", true},
+		{"~>1.2", "v2.0", false},
+		{"~>1.2.3", "v1.2.9", true},
+		{"~>1.2.3", "v1.3.0", false},
+		{"^1.2", "v1.9.9", true},
+		{"^1.2", "v2.0.0-beta", false},
+	}
+	for _, kase := range kases {
+		constraints, err := ParseVersionConstraints(kase.constraint)
+		assert.NoError(t, err)
+		v, err := ParseVersion(kase.version)
+		assert.NoError(t, err)
+		assert.Equal(t, kase.expected, constraints.Check(v), "%s %s", kase.constraint, kase.version)
+	}
+}
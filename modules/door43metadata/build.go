@@ -0,0 +1,88 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// Build is the door43 metadata of a commit of a repository, built as that of a release or the default branch is
+// but not saved
+type Build struct {
+	// Manifest is nil if the commit has no manifest.yaml or it is not valid YAML
+	Manifest *map[string]interface{}
+	// Errors are why the manifest.yaml is not valid
+	Errors []string
+	// Door43Metadata is nil if the manifest.yaml is not valid. Its warnings are the inconsistencies of the manifest,
+	// e.g. with the name of the repository, and it is given the progresses of its books.
+	Door43Metadata *models.Door43Metadata
+}
+
+// BuildDoor43Metadata builds the door43 metadata of a commit of a repository as that of a release, or of the default
+// branch if nil, without saving anything. The checksums of a release are computed from the commit when needed, see
+// GetDoor43MetadataChecksums.
+func BuildDoor43Metadata(repo *models.Repository, release *models.Release, commit *git.Commit) (*Build, error) {
+	build := &Build{}
+	blob, err := commit.GetBlobByPath("manifest.yaml")
+	if err != nil {
+		if !git.IsErrNotExist(err) {
+			return nil, err
+		}
+		build.Errors = []string{"manifest.yaml does not exist"}
+		return build, nil
+	}
+	manifest, err := base.ReadYAMLFromBlob(blob)
+	if err != nil {
+		build.Errors = []string{fmt.Sprintf("manifest.yaml is not valid YAML: %v", err)}
+		return build, nil
+	}
+	build.Manifest = manifest
+
+	result, err := base.ValidateBlobByRC020Schema(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid() {
+		for _, desc := range result.Errors() {
+			build.Errors = append(build.Errors, desc.String())
+		}
+		return build, nil
+	}
+
+	var releaseID int64
+	var tagName string
+	if release != nil {
+		releaseID = release.ID
+		tagName = release.TagName
+	}
+	bps, err := GetBookProgresses(commit, manifest)
+	if err != nil {
+		return nil, err
+	}
+	// The progresses are given to the metadata even if there are none, so as not to be those saved for the release
+	bookProgresses := make(models.BookProgressList, 0, len(bps))
+	bookProgresses = append(bookProgresses, bps...)
+	releaseDateUnix, branchOrTag := getReleaseDateAndBranchOrTag(repo, release, commit)
+	build.Door43Metadata = &models.Door43Metadata{
+		RepoID:          repo.ID,
+		Repo:            repo,
+		ReleaseID:       releaseID,
+		Release:         release,
+		ReleaseDateUnix: releaseDateUnix,
+		MetadataVersion: "rc0.2",
+		Metadata:        manifest,
+		Stage:           getStage(release),
+		BranchOrTag:     branchOrTag,
+		SortableVersion: GetSortableVersion(manifest, release),
+		Warnings:        dcs.CheckManifestConsistency(repo.Name, tagName, manifest),
+		BookProgresses:  bookProgresses,
+	}
+	return build, nil
+}
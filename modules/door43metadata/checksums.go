@@ -0,0 +1,108 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// GetChecksums returns the SHA-256 checksums of every file of a commit in the format of sha256sum
+func GetChecksums(commit *git.Commit) (string, error) {
+	entries, err := commit.Tree.ListEntriesRecursive()
+	if err != nil {
+		return "", err
+	}
+	checksums := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !entry.IsRegular() && !entry.IsExecutable() {
+			continue
+		}
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return "", err
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, reader)
+		reader.Close()
+		if err != nil {
+			return "", err
+		}
+		checksums[entry.Name()] = hex.EncodeToString(hash.Sum(nil))
+	}
+	return dcs.FormatChecksums(checksums), nil
+}
+
+// GetDoor43MetadataChecksums returns the checksums of the files of the release of a door43 metadata, computing
+// and signing them if they have not been yet, e.g. for a release made before they were, or its tag has moved.
+// They are nil if the metadata has none, e.g. that of a draft.
+func GetDoor43MetadataChecksums(dm *models.Door43Metadata) (*models.Door43MetadataChecksums, error) {
+	if !dm.HasChecksums() {
+		return nil, nil
+	}
+	if err := dm.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	gitRepo, err := git.OpenRepository(dm.Repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetTagCommit(dm.Release.TagName)
+	if err != nil {
+		return nil, err
+	}
+	return getDoor43MetadataChecksums(dm, commit)
+}
+
+// getDoor43MetadataChecksums returns the checksums of the files of the commit of the release of a door43 metadata,
+// computing and signing them if those it has are not of the commit
+func getDoor43MetadataChecksums(dm *models.Door43Metadata, commit *git.Commit) (*models.Door43MetadataChecksums, error) {
+	if !dm.HasChecksums() {
+		return nil, nil
+	}
+	dmc, err := models.GetDoor43MetadataChecksums(dm.ID)
+	if err != nil {
+		return nil, err
+	}
+	if dmc != nil && dmc.CommitSHA == commit.ID.String() {
+		return dmc, nil
+	}
+
+	checksums, err := GetChecksums(commit)
+	if err != nil {
+		return nil, err
+	}
+	dmc = &models.Door43MetadataChecksums{
+		Door43MetadataID: dm.ID,
+		CommitSHA:        commit.ID.String(),
+		Checksums:        checksums,
+	}
+	if err := dm.GetRepo(); err != nil {
+		return nil, err
+	}
+	dmc.Signature, dmc.SigningKey = signChecksums(dm.Repo, checksums)
+	if err := models.SaveDoor43MetadataChecksums(dmc); err != nil {
+		return nil, err
+	}
+	return dmc, nil
+}
+
+// signChecksums signs the checksums of a release with the signing key of its repository, returning the signature
+// and the key ID. A failure to sign leaves the checksums unsigned.
+func signChecksums(repo *models.Repository, checksums string) (string, string) {
+	signature, signingKey, err := models.SignDetached(repo.RepoPath(), checksums)
+	if err != nil {
+		log.Error("SignDetached: %v", err)
+		return "", ""
+	}
+	return signature, signingKey
+}
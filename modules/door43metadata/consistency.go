@@ -0,0 +1,81 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// GetReleaseManifestInconsistencies reads the manifest.yaml file at the release's target and returns how it disagrees
+// with the repo name and the release tag. Returns no issues if there is no manifest.yaml file.
+func GetReleaseManifestInconsistencies(gitRepo *git.Repository, repo *models.Repository, rel *models.Release) ([]string, error) {
+	var commit *git.Commit
+	var err error
+	if gitRepo.IsTagExist(rel.TagName) {
+		commit, err = gitRepo.GetTagCommit(rel.TagName)
+	} else {
+		commit, err = gitRepo.GetCommit(rel.Target)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := commit.GetBlobByPath("manifest.yaml")
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	manifest, err := base.ReadYAMLFromBlob(blob)
+	if err != nil {
+		// An unreadable manifest is reported by the schema validation
+		return nil, nil
+	}
+
+	return dcs.CheckManifestConsistency(repo.Name, rel.TagName, manifest), nil
+}
+
+// CheckReleaseManifestConsistency checks the release's manifest.yaml file against the repo name and release tag.
+// If the repo's owner is an organization that blocks inconsistent releases, an ErrInconsistentManifest is returned,
+// otherwise the issues are only logged.
+func CheckReleaseManifestConsistency(gitRepo *git.Repository, rel *models.Release) error {
+	if rel.IsTag || rel.IsDraft {
+		return nil
+	}
+	if rel.Repo == nil {
+		repo, err := models.GetRepositoryByID(rel.RepoID)
+		if err != nil {
+			return err
+		}
+		rel.Repo = repo
+	}
+
+	issues, err := GetReleaseManifestInconsistencies(gitRepo, rel.Repo, rel)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if err := rel.Repo.GetOwner(); err != nil {
+		return err
+	}
+	if rel.Repo.Owner.IsOrganization() && rel.Repo.Owner.BlockInconsistentReleases {
+		return models.ErrInconsistentManifest{TagName: rel.TagName, Issues: issues}
+	}
+
+	log.Warn("%s/%s: manifest.yaml is not consistent with the repo name and release:", rel.Repo.FullName(), rel.TagName)
+	for _, issue := range issues {
+		log.Warn("- %s", issue)
+	}
+	return nil
+}
@@ -7,9 +7,10 @@ package door43metadata
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"code.gitea.io/gitea/models"
-	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/dcs"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/structs"
@@ -20,13 +21,15 @@ import (
 	"xorm.io/xorm"
 )
 
-// GenerateDoor43Metadata Generate door43 metadata for valid repos not in the door43_metadata table
+// GenerateDoor43Metadata Generate door43 metadata for valid repos not in the door43_metadata table, and
+// reprocess the metadata generated before its sortable version was added, which is NULL rather than empty
 func GenerateDoor43Metadata(x *xorm.Engine) error {
 	sess := x.NewSession()
 	defer sess.Close()
 
 	// Query to find repos that need processing, either having releases that
-	// haven't been processed, or their default branch hasn't been processed.
+	// haven't been processed, or their default branch hasn't been processed,
+	// or metadata whose sortable version has not been set.
 	records, err := sess.Query("SELECT rel.id as release_id, r.id as repo_id  FROM `repository` r " +
 		"  JOIN `release` rel ON rel.repo_id = r.id " +
 		"  LEFT JOIN `door43_metadata` dm ON r.id = dm.repo_id " +
@@ -37,6 +40,9 @@ func GenerateDoor43Metadata(x *xorm.Engine) error {
 		"  LEFT JOIN `door43_metadata` dm2 ON r2.id = dm2.repo_id " +
 		"  AND dm2.release_id = 0 " +
 		"  WHERE dm2.id IS NULL " +
+		"UNION " +
+		"SELECT dm3.release_id, dm3.repo_id FROM `door43_metadata` dm3 " +
+		"  WHERE dm3.sortable_version IS NULL " +
 		"ORDER BY repo_id ASC, release_id ASC")
 	if err != nil {
 		return err
@@ -66,6 +72,11 @@ func GenerateDoor43Metadata(x *xorm.Engine) error {
 		if err = ProcessDoor43MetadataForRepoRelease(repo, release); err != nil {
 			continue
 		}
+		// The metadata without a version is not to be reprocessed every time
+		if _, err = sess.Exec("UPDATE `door43_metadata` SET sortable_version = '' "+
+			"WHERE repo_id = ? AND release_id = ? AND sortable_version IS NULL", repoID, releaseID); err != nil {
+			log.Error("UPDATE door43_metadata: %v", err)
+		}
 	}
 
 	return nil
@@ -202,37 +213,20 @@ func ProcessDoor43MetadataForRepoRelease(repo *models.Repository, release *model
 		}
 	}
 
-	blob, err := commit.GetBlobByPath("manifest.yaml")
-	if err != nil && !git.IsErrNotExist(err) {
-		return err
-	}
-	if blob == nil {
-		return nil
-	}
-
-	manifest, err := base.ReadYAMLFromBlob(blob)
+	build, err := BuildDoor43Metadata(repo, release, commit)
 	if err != nil {
 		return err
 	}
-
-	result, err := base.ValidateBlobByRC020Schema(manifest)
-	if err != nil {
-		return err
+	if build.Manifest == nil {
+		for _, desc := range build.Errors {
+			log.Warn("%s: %s", repo.FullName(), desc)
+		}
+		return nil
 	}
 
 	var releaseID int64
-	var stage models.Stage
 	if release != nil {
 		releaseID = release.ID
-		if release.IsDraft {
-			stage = models.StageDraft
-		} else if release.IsPrerelease {
-			stage = models.StagePreProd
-		} else {
-			stage = models.StageProd
-		}
-	} else {
-		stage = models.StageLatest
 	}
 
 	dm, err := models.GetDoor43MetadataByRepoIDAndReleaseID(repo.ID, releaseID)
@@ -240,68 +234,109 @@ func ProcessDoor43MetadataForRepoRelease(repo *models.Repository, release *model
 		return err
 	}
 
-	//metadata, err := ConvertGenericMapToRC020Manifest(manifest)
-	//if err != nil {
-	//	return err
-	//}
-
-	var releaseDateUnix timeutil.TimeStamp
-	var branchOrTag string
-	if release != nil && !release.IsDraft {
-		releaseDateUnix = release.CreatedUnix
-		branchOrTag = release.TagName
-	} else {
-		releaseDateUnix = timeutil.TimeStamp(commit.Author.When.Unix())
+	built := build.Door43Metadata
+	if built == nil {
+		log.Warn("%s: manifest.yaml is not valid. see errors:", repo.FullName())
+		log.Warn("REPO ID: %d, RELEASE ID: %d", repo.ID, releaseID)
 		if release != nil {
-			branchOrTag = release.Target
+			log.Warn("RELEASE: %v", release.TagName)
 		} else {
-			branchOrTag = repo.DefaultBranch
+			log.Warn("BRANCH: %s", repo.DefaultBranch)
+		}
+		for _, desc := range build.Errors {
+			log.Warn("- %s", desc)
+		}
+		if dm != nil {
+			return models.DeleteDoor43Metadata(dm)
 		}
+		return nil
+	}
+
+	// The books may be translated further without the manifest changing
+	if err := models.UpdateBookProgresses(repo.ID, releaseID, built.BookProgresses); err != nil {
+		log.Error("UpdateBookProgresses: %v", err)
 	}
 
 	if dm == nil ||
-		releaseDateUnix != dm.ReleaseDateUnix ||
-		dm.Stage != stage ||
-		dm.BranchOrTag != branchOrTag ||
-		!reflect.DeepEqual(dm.Metadata, manifest) {
-		if !result.Valid() {
-			log.Warn("%s/%s: manifest.yaml is not valid. see errors:", repo.FullName(), branchOrTag)
-			log.Warn("REPO ID: %d, RELEASE ID: %d", repo.ID, releaseID)
-			if release != nil {
-				log.Warn("RELEASE: %v", release.TagName)
-			} else {
-				log.Warn("BRANCH: %s", repo.DefaultBranch)
-			}
-			for _, desc := range result.Errors() {
-				log.Warn("- %s", desc.Description())
-				log.Warn("- %s = %s", desc.Field(), desc.Value())
-			}
-			if dm != nil {
-				return models.DeleteDoor43Metadata(dm)
+		built.ReleaseDateUnix != dm.ReleaseDateUnix ||
+		dm.Stage != built.Stage ||
+		dm.BranchOrTag != built.BranchOrTag ||
+		dm.SortableVersion != built.SortableVersion ||
+		strings.Join(dm.Warnings, "\n") != strings.Join(built.Warnings, "\n") ||
+		!reflect.DeepEqual(dm.Metadata, built.Metadata) {
+		log.Warn("%s/%s: manifest.yaml is valid.", repo.FullName(), built.BranchOrTag)
+		for _, issue := range built.Warnings {
+			log.Warn("%s/%s: manifest.yaml is not consistent: %s", repo.FullName(), built.BranchOrTag, issue)
+		}
+		if dm == nil {
+			dm = built
+			if err := models.InsertDoor43Metadata(dm); err != nil {
+				return err
 			}
 		} else {
-			log.Warn("%s/%s: manifest.yaml is valid.", repo.FullName(), branchOrTag)
-			if dm == nil {
-				dm = &models.Door43Metadata{
-					RepoID:          repo.ID,
-					Repo:            repo,
-					ReleaseID:       releaseID,
-					Release:         release,
-					ReleaseDateUnix: releaseDateUnix,
-					MetadataVersion: "rc0.2",
-					Metadata:        manifest,
-					Stage:           stage,
-					BranchOrTag:     branchOrTag,
-				}
-				return models.InsertDoor43Metadata(dm)
+			dm.Metadata = built.Metadata
+			dm.ReleaseDateUnix = built.ReleaseDateUnix
+			dm.Stage = built.Stage
+			dm.BranchOrTag = built.BranchOrTag
+			dm.SortableVersion = built.SortableVersion
+			dm.Warnings = built.Warnings
+			if err := models.UpdateDoor43MetadataCols(dm, "metadata", "release_date_unix", "stage", "branch_or_tag", "sortable_version", "warnings"); err != nil {
+				return err
 			}
-			dm.Metadata = manifest
-			dm.ReleaseDateUnix = releaseDateUnix
-			dm.Stage = stage
-			dm.BranchOrTag = branchOrTag
-			return models.UpdateDoor43MetadataCols(dm, "metadata", "release_date_unix", "stage", "branch_or_tag")
 		}
 	}
 
+	// The files of a release are checksummed for its copies to be verified, those of a draft may still change
+	if _, err := getDoor43MetadataChecksums(dm, commit); err != nil {
+		log.Error("getDoor43MetadataChecksums: %v", err)
+		return err
+	}
+
 	return nil
 }
+
+// getStage returns the stage of the metadata of a release, latest for the default branch if nil
+func getStage(release *models.Release) models.Stage {
+	switch {
+	case release == nil:
+		return models.StageLatest
+	case release.IsDraft:
+		return models.StageDraft
+	case release.IsPrerelease:
+		return models.StagePreProd
+	default:
+		return models.StageProd
+	}
+}
+
+// getReleaseDateAndBranchOrTag returns the release date and the branch or tag of the metadata of a commit of
+// a release, the default branch if nil. Drafts and the default branch are released when their commit was authored.
+func getReleaseDateAndBranchOrTag(repo *models.Repository, release *models.Release, commit *git.Commit) (timeutil.TimeStamp, string) {
+	switch {
+	case release != nil && !release.IsDraft:
+		return release.CreatedUnix, release.TagName
+	case release != nil:
+		return timeutil.TimeStamp(commit.Author.When.Unix()), release.Target
+	default:
+		return timeutil.TimeStamp(commit.Author.When.Unix()), repo.DefaultBranch
+	}
+}
+
+// GetSortableVersion gets the sortable version of a release's tag, or if the tag is not a version (or there is no
+// release), of the manifest's dublin_core.version
+func GetSortableVersion(manifest *map[string]interface{}, release *models.Release) string {
+	if release != nil {
+		if sortable := dcs.GetSortableVersionFromString(release.TagName); sortable != "" {
+			return sortable
+		}
+	}
+	if manifest == nil {
+		return ""
+	}
+	if dc, ok := (*manifest)["dublin_core"].(map[string]interface{}); ok {
+		if v, ok := dc["version"]; ok && v != nil {
+			return dcs.GetSortableVersionFromString(fmt.Sprint(v))
+		}
+	}
+	return ""
+}
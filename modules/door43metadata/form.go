@@ -0,0 +1,463 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/dcs"
+
+	"github.com/ghodss/yaml"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Types of the fields of the manifest.yaml editor form
+const (
+	ManifestFieldTypeText     = "text"
+	ManifestFieldTypeNumber   = "number"
+	ManifestFieldTypeSelect   = "select"
+	ManifestFieldTypeList     = "list" // an array of strings, one per line
+	ManifestFieldTypeYAML     = "yaml" // an array of objects or anything else, edited as YAML
+	ManifestFieldTypeLanguage = "language"
+	ManifestFieldTypeSubject  = "subject"
+)
+
+// manifestFieldFormPrefix is prepended to the path of each field to get its form input name
+const manifestFieldFormPrefix = "manifest."
+
+// ManifestFormField is a field of the manifest.yaml editor form, generated from the RC schema
+type ManifestFormField struct {
+	Path        string // dot separated path in the manifest, e.g. dublin_core.language.identifier
+	Label       string
+	Description string
+	Placeholder string
+	Type        string
+	Options     []string
+	Required    bool
+	Value       string
+	Error       string
+	Depth       int
+	IsGroup     bool // a heading for the fields of an object
+}
+
+// FormName returns the name of the field's form input
+func (f *ManifestFormField) FormName() string {
+	return manifestFieldFormPrefix + f.Path
+}
+
+type schemaNode struct {
+	Ref         string                 `json:"$ref"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Type        interface{}            `json:"type"`
+	Enum        []interface{}          `json:"enum"`
+	Examples    []interface{}          `json:"examples"`
+	Required    []string               `json:"required"`
+	Properties  map[string]*schemaNode `json:"properties"`
+	Items       *schemaNode            `json:"items"`
+	Definitions map[string]*schemaNode `json:"definitions"`
+}
+
+// resolve merges in the definition the node refers to, if any
+func (n *schemaNode) resolve(definitions map[string]*schemaNode) *schemaNode {
+	if n.Ref == "" {
+		return n
+	}
+	def, ok := definitions[strings.TrimPrefix(n.Ref, "#/definitions/")]
+	if !ok {
+		return n
+	}
+	resolved := *n
+	if resolved.Type == nil {
+		resolved.Type = def.Type
+	}
+	if resolved.Enum == nil {
+		resolved.Enum = def.Enum
+	}
+	if resolved.Description == "" {
+		resolved.Description = def.Description
+	}
+	if resolved.Items == nil {
+		resolved.Items = def.Items
+	}
+	return &resolved
+}
+
+// types returns the JSON types the node allows
+func (n *schemaNode) types() []string {
+	switch t := n.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+		return types
+	}
+	return nil
+}
+
+func (n *schemaNode) hasType(typ string) bool {
+	for _, t := range n.types() {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedProperties returns the property keys of an object, required ones first in schema order, then the rest sorted
+func (n *schemaNode) orderedProperties() []string {
+	keys := make([]string, 0, len(n.Properties))
+	seen := make(map[string]bool, len(n.Properties))
+	for _, key := range n.Required {
+		if _, ok := n.Properties[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	var rest []string
+	for key := range n.Properties {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+func loadManifestSchema() (*schemaNode, error) {
+	schemaBytes, err := base.GetRC020Schema()
+	if err != nil {
+		return nil, err
+	}
+	var schema schemaNode
+	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// GetManifestFormFields returns the fields of the manifest.yaml editor form, generated from the RC schema,
+// with the values of the given manifest
+func GetManifestFormFields(manifest *map[string]interface{}) ([]*ManifestFormField, error) {
+	schema, err := loadManifestSchema()
+	if err != nil {
+		return nil, err
+	}
+	var fields []*ManifestFormField
+	addManifestFormFields(&fields, schema, schema, "", 0)
+
+	if manifest != nil {
+		for _, field := range fields {
+			if !field.IsGroup {
+				field.Value = formatManifestValue(field.Type, getManifestValue(*manifest, field.Path))
+			}
+		}
+	}
+	return fields, nil
+}
+
+func addManifestFormFields(fields *[]*ManifestFormField, schema, node *schemaNode, parentPath string, depth int) {
+	required := make(map[string]bool, len(node.Required))
+	for _, key := range node.Required {
+		required[key] = true
+	}
+	for _, key := range node.orderedProperties() {
+		prop := node.Properties[key].resolve(schema.Definitions)
+		path := key
+		if parentPath != "" {
+			path = parentPath + "." + key
+		}
+		field := &ManifestFormField{
+			Path:        path,
+			Label:       key,
+			Description: prop.Description,
+			Required:    required[key],
+			Depth:       depth,
+		}
+		if len(prop.Examples) > 0 {
+			field.Placeholder = fmt.Sprint(prop.Examples[0])
+		}
+
+		switch {
+		case prop.hasType("object") && len(prop.Properties) > 0:
+			field.IsGroup = true
+			*fields = append(*fields, field)
+			addManifestFormFields(fields, schema, prop, path, depth+1)
+			continue
+		case prop.hasType("array"):
+			items := &schemaNode{}
+			if prop.Items != nil {
+				items = prop.Items.resolve(schema.Definitions)
+			}
+			if items.hasType("string") {
+				field.Type = ManifestFieldTypeList
+				if len(items.Examples) > 0 {
+					field.Placeholder = fmt.Sprint(items.Examples[0])
+				}
+			} else {
+				field.Type = ManifestFieldTypeYAML
+			}
+		case path == "dublin_core.language.identifier":
+			field.Type = ManifestFieldTypeLanguage
+		case path == "dublin_core.subject":
+			field.Type = ManifestFieldTypeSubject
+			field.Options = getSubjectOptions(prop)
+		case len(prop.Enum) > 0:
+			field.Type = ManifestFieldTypeSelect
+			for _, option := range prop.Enum {
+				field.Options = append(field.Options, fmt.Sprint(option))
+			}
+		case prop.hasType("integer") || prop.hasType("number"):
+			field.Type = ManifestFieldTypeNumber
+		case prop.hasType("object"):
+			field.Type = ManifestFieldTypeYAML
+		default:
+			field.Type = ManifestFieldTypeText
+		}
+		*fields = append(*fields, field)
+	}
+}
+
+// getSubjectOptions returns the subjects of the schema combined with the subjects known to DCS
+func getSubjectOptions(prop *schemaNode) []string {
+	seen := make(map[string]bool)
+	var options []string
+	for _, subject := range prop.Enum {
+		if s := fmt.Sprint(subject); !seen[s] {
+			seen[s] = true
+			options = append(options, s)
+		}
+	}
+	for _, subject := range dcs.Subjects {
+		if !seen[subject] {
+			seen[subject] = true
+			options = append(options, subject)
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+// ManifestLanguageOption is a language that can be picked for dublin_core.language.identifier
+type ManifestLanguageOption struct {
+	Code      string
+	Name      string
+	Direction string
+}
+
+// GetManifestLanguageOptions returns the languages from tD sorted by language code
+func GetManifestLanguageOptions() []*ManifestLanguageOption {
+	langNames := dcs.GetLangNames()
+	options := make([]*ManifestLanguageOption, 0, len(langNames))
+	for code, value := range langNames {
+		option := &ManifestLanguageOption{Code: code}
+		if lang, ok := value.(map[string]interface{}); ok {
+			if ln, ok := lang["ln"].(string); ok {
+				option.Name = ln
+			}
+			if ld, ok := lang["ld"].(string); ok {
+				option.Direction = ld
+			}
+		}
+		options = append(options, option)
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Code < options[j].Code
+	})
+	return options
+}
+
+func getManifestValue(manifest map[string]interface{}, path string) interface{} {
+	var value interface{} = manifest
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func setManifestValue(manifest map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	m := manifest
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			m[key] = child
+		}
+		m = child
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+func formatManifestValue(fieldType string, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	switch fieldType {
+	case ManifestFieldTypeList:
+		if list, ok := value.([]interface{}); ok {
+			strs := make([]string, len(list))
+			for i, item := range list {
+				strs[i] = fmt.Sprint(item)
+			}
+			return strings.Join(strs, "\n")
+		}
+	case ManifestFieldTypeYAML:
+		if content, err := yaml.Marshal(value); err == nil {
+			return string(content)
+		}
+	}
+	if _, ok := value.(map[string]interface{}); ok {
+		if content, err := yaml.Marshal(value); err == nil {
+			return string(content)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// ApplyManifestFormValues sets the submitted values of the form fields in the manifest, keeping any keys the form
+// does not know about. values is keyed by the form input names. Fields whose values could not be parsed get an Error.
+func ApplyManifestFormValues(manifest map[string]interface{}, fields []*ManifestFormField, values map[string]string) bool {
+	valid := true
+	for _, field := range fields {
+		if field.IsGroup {
+			continue
+		}
+		value, ok := values[field.FormName()]
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		field.Value = value
+		field.Error = ""
+
+		if value == "" && !field.Required && getManifestValue(manifest, field.Path) == nil {
+			continue
+		}
+
+		switch field.Type {
+		case ManifestFieldTypeList:
+			list := []interface{}{}
+			for _, line := range strings.Split(value, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					list = append(list, line)
+				}
+			}
+			setManifestValue(manifest, field.Path, list)
+		case ManifestFieldTypeYAML:
+			var parsed interface{}
+			if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+				field.Error = strings.ReplaceAll(err.Error(), " converting YAML to JSON", "")
+				valid = false
+				continue
+			}
+			if parsed == nil {
+				parsed = []interface{}{}
+			}
+			setManifestValue(manifest, field.Path, parsed)
+		case ManifestFieldTypeNumber:
+			if i, err := strconv.Atoi(value); err == nil {
+				setManifestValue(manifest, field.Path, i)
+			} else {
+				setManifestValue(manifest, field.Path, value)
+			}
+		default:
+			setManifestValue(manifest, field.Path, value)
+		}
+	}
+	fillManifestLanguage(manifest)
+	return valid
+}
+
+// fillManifestLanguage fills in an empty dublin_core.language title and direction from tD
+func fillManifestLanguage(manifest map[string]interface{}) {
+	dc, ok := manifest["dublin_core"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	language, ok := dc["language"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	code, ok := language["identifier"].(string)
+	if !ok || code == "" {
+		return
+	}
+	title, _ := language["title"].(string)
+	direction, _ := language["direction"].(string)
+	if title != "" && direction != "" {
+		return
+	}
+	lang, ok := dcs.GetLangNames()[code].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if title == "" {
+		if ln, ok := lang["ln"].(string); ok {
+			language["title"] = ln
+		}
+	}
+	if direction == "" {
+		if ld, ok := lang["ld"].(string); ok {
+			language["direction"] = ld
+		}
+	}
+}
+
+// SetManifestFormErrors puts each schema validation error on the form field it belongs to.
+// Returns the errors that do not belong to any field.
+func SetManifestFormErrors(fields []*ManifestFormField, result *gojsonschema.Result) []string {
+	var otherErrors []string
+	if result == nil || result.Valid() {
+		return otherErrors
+	}
+	for _, resultErr := range result.Errors() {
+		path := resultErr.Field()
+		if resultErr.Type() == "required" {
+			if property, ok := resultErr.Details()["property"]; ok {
+				if path == gojsonschema.STRING_CONTEXT_ROOT {
+					path = fmt.Sprint(property)
+				} else {
+					path = path + "." + fmt.Sprint(property)
+				}
+			}
+		}
+		if field := findManifestFormField(fields, path); field != nil {
+			if field.Error != "" {
+				field.Error += "; "
+			}
+			field.Error += resultErr.Description()
+		} else {
+			otherErrors = append(otherErrors, resultErr.String())
+		}
+	}
+	return otherErrors
+}
+
+// findManifestFormField finds the field for the given path, or the closest field containing it,
+// e.g. projects.0.path belongs to the projects field
+func findManifestFormField(fields []*ManifestFormField, path string) *ManifestFormField {
+	var found *ManifestFormField
+	for _, field := range fields {
+		if field.Path == path || strings.HasPrefix(path, field.Path+".") {
+			if found == nil || len(field.Path) > len(found.Path) {
+				found = field
+			}
+		}
+	}
+	return found
+}
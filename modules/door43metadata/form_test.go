@@ -0,0 +1,136 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestManifestFormFields(t *testing.T, manifest map[string]interface{}) map[string]*ManifestFormField {
+	setting.StaticRootPath = "../../"
+	fields, err := GetManifestFormFields(&manifest)
+	assert.NoError(t, err)
+	byPath := make(map[string]*ManifestFormField, len(fields))
+	for _, field := range fields {
+		byPath[field.Path] = field
+	}
+	return byPath
+}
+
+func TestGetManifestFormFields(t *testing.T) {
+	fields := getTestManifestFormFields(t, readTestManifest(t, testManifest))
+
+	dc := fields["dublin_core"]
+	if assert.NotNil(t, dc) {
+		assert.True(t, dc.IsGroup)
+		assert.True(t, dc.Required)
+		assert.Equal(t, 0, dc.Depth)
+	}
+	if version := fields["dublin_core.version"]; assert.NotNil(t, version) {
+		assert.Equal(t, ManifestFieldTypeText, version.Type)
+		assert.Equal(t, "5", version.Value)
+		assert.Equal(t, 1, version.Depth)
+		assert.Equal(t, "manifest.dublin_core.version", version.FormName())
+	}
+	if language := fields["dublin_core.language.identifier"]; assert.NotNil(t, language) {
+		assert.Equal(t, ManifestFieldTypeLanguage, language.Type)
+		assert.Equal(t, "en", language.Value)
+		assert.Equal(t, 2, language.Depth)
+	}
+	if subject := fields["dublin_core.subject"]; assert.NotNil(t, subject) {
+		assert.Equal(t, ManifestFieldTypeSubject, subject.Type)
+		assert.Contains(t, subject.Options, "Aligned Bible")
+		assert.IsIncreasing(t, subject.Options)
+		assert.Empty(t, subject.Value)
+	}
+	if relation := fields["dublin_core.relation"]; assert.NotNil(t, relation) {
+		assert.Equal(t, ManifestFieldTypeList, relation.Type)
+		assert.Equal(t, "en/tn\nen/tw", relation.Value)
+	}
+	if projects := fields["projects"]; assert.NotNil(t, projects) {
+		assert.Equal(t, ManifestFieldTypeYAML, projects.Type)
+		assert.Contains(t, projects.Value, "identifier: gen")
+	}
+	if level := fields["checking.checking_level"]; assert.NotNil(t, level) {
+		assert.Equal(t, ManifestFieldTypeSelect, level.Type)
+		assert.Equal(t, "3", level.Value)
+	}
+
+	// Without a manifest the fields have no values
+	for _, field := range getTestManifestFormFields(t, nil) {
+		assert.Empty(t, field.Value, field.Path)
+	}
+}
+
+func TestApplyManifestFormValues(t *testing.T) {
+	manifest := readTestManifest(t, testManifest)
+	manifest["x_extra"] = "kept"
+	setting.StaticRootPath = "../../"
+	fields, err := GetManifestFormFields(&manifest)
+	assert.NoError(t, err)
+
+	valid := ApplyManifestFormValues(manifest, fields, map[string]string{
+		"manifest.dublin_core.version":  " 6 ",
+		"manifest.dublin_core.relation": "en/tn\n\n en/tq \n",
+		"manifest.dublin_core.creator":  "",
+		"manifest.projects":             "- title: Exodus\n  identifier: exo\n  path: ./02-EXO.usfm\n",
+	})
+	assert.True(t, valid)
+	dc := manifest["dublin_core"].(map[string]interface{})
+	assert.Equal(t, "6", dc["version"])
+	assert.Equal(t, []interface{}{"en/tn", "en/tq"}, dc["relation"])
+	assert.Equal(t, "", dc["creator"], "an empty required value is set for the schema to report it")
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"title":      "Exodus",
+		"identifier": "exo",
+		"path":       "./02-EXO.usfm",
+	}}, manifest["projects"])
+	assert.Equal(t, "kept", manifest["x_extra"], "keys the form does not know about are kept")
+	assert.Equal(t, "unfoldingWord Literal Text", dc["title"], "values that were not submitted are kept")
+
+	// Values that cannot be parsed are not applied and get an error
+	valid = ApplyManifestFormValues(manifest, fields, map[string]string{
+		"manifest.projects": "- [",
+	})
+	assert.False(t, valid)
+	for _, field := range fields {
+		if field.Path == "projects" {
+			assert.NotEmpty(t, field.Error)
+			assert.Equal(t, "- [", field.Value)
+		}
+	}
+	assert.Len(t, manifest["projects"], 1)
+}
+
+func TestSetManifestFormErrors(t *testing.T) {
+	manifest := readTestManifest(t, testManifest)
+	dc := manifest["dublin_core"].(map[string]interface{})
+	delete(dc, "version")
+	dc["type"] = "novel"
+	setting.StaticRootPath = "../../"
+	fields, err := GetManifestFormFields(&manifest)
+	assert.NoError(t, err)
+
+	result, err := base.ValidateBlobByRC020Schema(&manifest)
+	assert.NoError(t, err)
+	assert.False(t, result.Valid())
+	SetManifestFormErrors(fields, result)
+	for _, field := range fields {
+		switch field.Path {
+		case "dublin_core.version", "dublin_core.type":
+			assert.NotEmpty(t, field.Error, field.Path)
+		case "dublin_core.identifier", "dublin_core.language.identifier", "projects":
+			assert.Empty(t, field.Error, field.Path)
+		}
+	}
+
+	// A valid manifest has no errors
+	assert.Empty(t, SetManifestFormErrors(fields, nil))
+}
@@ -0,0 +1,309 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// Formats of the generated manifests
+const (
+	manifestFormatUSFM     = "text/usfm3"
+	manifestFormatMarkdown = "text/markdown"
+	manifestFormatTSV      = "text/tsv"
+)
+
+// usfmHeaderSize is how much of a USFM file is read to find its \id marker
+const usfmHeaderSize = 4096
+
+var (
+	usfmIDRegexp = regexp.MustCompile(`\\id\s+([A-Za-z0-9]{3})`)
+	taProjects   = []string{"intro", "process", "translate", "checking"}
+)
+
+// manifestProject is a project found in the tree of a repo
+type manifestProject struct {
+	Identifier    string
+	Title         string
+	Path          string
+	Sort          int
+	Versification string
+	Categories    []string
+}
+
+func (p *manifestProject) toMap() map[string]interface{} {
+	project := map[string]interface{}{
+		"identifier":    p.Identifier,
+		"title":         p.Title,
+		"path":          p.Path,
+		"sort":          p.Sort,
+		"versification": p.Versification,
+		"categories":    []interface{}{},
+	}
+	for _, category := range p.Categories {
+		project["categories"] = append(project["categories"].([]interface{}), category)
+	}
+	return project
+}
+
+func newBookProject(book *dcs.Book, treePath string) *manifestProject {
+	return &manifestProject{
+		Identifier:    book.ID,
+		Title:         book.Name,
+		Path:          "./" + treePath,
+		Sort:          book.Sort,
+		Versification: "ufw",
+		Categories:    []string{book.Category()},
+	}
+}
+
+// manifestEntry is a file or directory of the tree of a repo that is inspected for the projects of its manifest
+type manifestEntry struct {
+	Path  string
+	IsDir bool
+	// readUSFMHeader returns the book identifier of the \id marker of a USFM file and whether it has alignments
+	readUSFMHeader func() (string, bool, error)
+}
+
+// GenerateManifest generates a manifest for a repo that does not have one by inspecting the tree of the commit
+// for USFM and TSV files, in any directory, and at the top of the tree for tN/tQ book directories, OBS content
+// and tA/tW directories. The language and subject are taken from the repo name when it follows the
+// {lang}_{subject} convention.
+func GenerateManifest(repo *models.Repository, commit *git.Commit) (map[string]interface{}, error) {
+	entries, err := commit.Tree.ListEntriesRecursive()
+	if err != nil {
+		return nil, err
+	}
+	manifestEntries := make([]*manifestEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && !entry.IsRegular() {
+			continue
+		}
+		entry := entry
+		manifestEntries = append(manifestEntries, &manifestEntry{
+			Path:  entry.Name(),
+			IsDir: entry.IsDir(),
+			readUSFMHeader: func() (string, bool, error) {
+				return readUSFMHeader(entry)
+			},
+		})
+	}
+	return generateManifest(repo, manifestEntries)
+}
+
+// generateManifest generates a manifest for a repo from the entries of its tree
+func generateManifest(repo *models.Repository, entries []*manifestEntry) (map[string]interface{}, error) {
+	// The files nearer the top of the tree are the projects, e.g. rather than copies of them in a directory
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.Count(entries[i].Path, "/") < strings.Count(entries[j].Path, "/")
+	})
+
+	var projects []*manifestProject
+	var format string
+	isAligned := false
+	seen := make(map[string]bool)
+	addProject := func(project *manifestProject, projectFormat string) {
+		if seen[project.Identifier] {
+			return
+		}
+		seen[project.Identifier] = true
+		projects = append(projects, project)
+		if format == "" {
+			format = projectFormat
+		}
+	}
+
+	for _, entry := range entries {
+		name := entry.Path
+		lowerName := strings.ToLower(path.Base(name))
+		isTop := !strings.Contains(name, "/")
+		switch {
+		case entry.IsDir && !isTop:
+			continue
+		case entry.IsDir && lowerName == "content":
+			addProject(&manifestProject{Identifier: "obs", Title: "Open Bible Stories", Path: "./" + name, Versification: "obs"}, manifestFormatMarkdown)
+		case entry.IsDir && lowerName == "bible":
+			addProject(&manifestProject{Identifier: "bible", Title: "translationWords", Path: "./" + name}, manifestFormatMarkdown)
+		case entry.IsDir && isTaProject(lowerName):
+			addProject(&manifestProject{Identifier: lowerName, Title: strings.Title(lowerName), Path: "./" + name, Categories: []string{"ta"}}, manifestFormatMarkdown)
+		case entry.IsDir && dcs.IsValidBook(lowerName):
+			// tN and tQ markdown layout, e.g. gen/01/01.md
+			addProject(newBookProject(dcs.GetBook(lowerName), name), manifestFormatMarkdown)
+		case !entry.IsDir && (path.Ext(lowerName) == ".usfm" || path.Ext(lowerName) == ".usfm3" || path.Ext(lowerName) == ".sfm"):
+			bookID, aligned, err := entry.readUSFMHeader()
+			if err != nil {
+				return nil, err
+			}
+			if bookID == "" {
+				bookID = dcs.GetBookFromFileName(lowerName)
+			}
+			if book := dcs.GetBook(bookID); book != nil {
+				addProject(newBookProject(book, name), manifestFormatUSFM)
+				isAligned = isAligned || aligned
+			}
+		case !entry.IsDir && path.Ext(lowerName) == ".tsv":
+			// TSV layout, e.g. en_tn_01-GEN.tsv, tn_GEN.tsv or tn_OBS.tsv
+			bookID := dcs.GetBookFromFileName(lowerName)
+			if book := dcs.GetBook(bookID); book != nil {
+				addProject(newBookProject(book, name), manifestFormatTSV)
+			} else if strings.HasSuffix(lowerName, "obs.tsv") {
+				addProject(&manifestProject{Identifier: "obs", Title: "Open Bible Stories", Path: "./" + name, Versification: "obs"}, manifestFormatTSV)
+			}
+		}
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Sort < projects[j].Sort
+	})
+	projectMaps := make([]interface{}, len(projects))
+	for i, project := range projects {
+		projectMaps[i] = project.toMap()
+	}
+
+	dublinCore := generateDublinCore(repo, format, isAligned)
+	if format == "" {
+		delete(dublinCore, "format")
+	}
+	checkingEntity := []interface{}{}
+	if repo.OwnerName != "" {
+		checkingEntity = append(checkingEntity, repo.OwnerName)
+	}
+
+	return map[string]interface{}{
+		"dublin_core": dublinCore,
+		"checking": map[string]interface{}{
+			"checking_entity": checkingEntity,
+			"checking_level":  "1",
+		},
+		"projects": projectMaps,
+	}, nil
+}
+
+func generateDublinCore(repo *models.Repository, format string, isAligned bool) map[string]interface{} {
+	repoName := strings.ToLower(repo.Name)
+	parts := strings.Split(repoName, "_")
+	identifier := parts[len(parts)-1]
+
+	subject := dcs.GetSubjectFromRepoName(repoName)
+	if subject == "" {
+		switch {
+		case format == manifestFormatUSFM && isAligned:
+			subject = "Aligned Bible"
+		case format == manifestFormatUSFM:
+			subject = "Bible"
+		case identifier == "obs":
+			subject = "Open Bible Stories"
+		}
+	}
+
+	title := repo.Description
+	if title == "" {
+		title = subject
+	}
+	if title == "" {
+		title = repo.Name
+	}
+
+	today := time.Now().Format("2006-01-02")
+	dublinCore := map[string]interface{}{
+		"conformsto":  "rc0.2",
+		"contributor": []interface{}{},
+		"creator":     repo.OwnerName,
+		"description": repo.Description,
+		"format":      format,
+		"identifier":  identifier,
+		"issued":      today,
+		"modified":    today,
+		"publisher":   repo.OwnerName,
+		"relation":    []interface{}{},
+		"rights":      "CC BY-SA 4.0",
+		"source":      []interface{}{},
+		"subject":     subject,
+		"title":       title,
+		"type":        getManifestType(subject),
+		"version":     "1",
+	}
+
+	language := map[string]interface{}{
+		"identifier": "",
+		"title":      "",
+		"direction":  "ltr",
+	}
+	if len(parts) > 1 && dcs.IsValidLanguage(parts[0]) {
+		language["identifier"] = parts[0]
+		if lang, ok := dcs.GetLangNames()[parts[0]].(map[string]interface{}); ok {
+			if ln, ok := lang["ln"].(string); ok {
+				language["title"] = ln
+			}
+			if ld, ok := lang["ld"].(string); ok && ld != "" {
+				language["direction"] = ld
+			}
+		}
+	}
+	dublinCore["language"] = language
+
+	return dublinCore
+}
+
+// getManifestType returns the RC type of a subject
+func getManifestType(subject string) string {
+	switch subject {
+	case "Bible", "Aligned Bible", "Greek New Testament", "Hebrew Old Testament":
+		return "bundle"
+	case "Open Bible Stories":
+		return "book"
+	case "Translation Words":
+		return "dict"
+	case "Translation Academy":
+		return "man"
+	case "":
+		return ""
+	}
+	return "help"
+}
+
+func isTaProject(name string) bool {
+	for _, project := range taProjects {
+		if name == project {
+			return true
+		}
+	}
+	return false
+}
+
+// readUSFMHeader reads the book identifier from the \id marker of a USFM file and whether it has alignments
+func readUSFMHeader(entry *git.TreeEntry) (string, bool, error) {
+	dataRc, err := entry.Blob().DataAsync()
+	if err != nil {
+		return "", false, err
+	}
+	defer dataRc.Close()
+
+	buf := make([]byte, usfmHeaderSize)
+	n, err := io.ReadFull(dataRc, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", false, err
+	}
+	bookID, aligned := parseUSFMHeader(string(buf[:n]))
+	return bookID, aligned, nil
+}
+
+// parseUSFMHeader returns the book identifier of the \id marker of the start of a USFM file and whether it has alignments
+func parseUSFMHeader(header string) (string, bool) {
+	var bookID string
+	if matches := usfmIDRegexp.FindStringSubmatch(header); matches != nil {
+		bookID = strings.ToLower(matches[1])
+	}
+	return bookID, strings.Contains(header, `\zaln-s`)
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEntries returns the entries of a tree from their paths, directories ending with a slash,
+// and the headers of the USFM files
+func testEntries(paths []string, headers map[string]string) []*manifestEntry {
+	entries := make([]*manifestEntry, 0, len(paths))
+	for _, p := range paths {
+		entry := &manifestEntry{Path: p}
+		if p[len(p)-1] == '/' {
+			entry.Path = p[:len(p)-1]
+			entry.IsDir = true
+		}
+		header := headers[p]
+		entry.readUSFMHeader = func() (string, bool, error) {
+			bookID, aligned := parseUSFMHeader(header)
+			return bookID, aligned, nil
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestParseUSFMHeader(t *testing.T) {
+	kases := []struct {
+		header  string
+		bookID  string
+		aligned bool
+	}{
+		{`\id GEN EN_ULT en_English_ltr`, "gen", false},
+		{"\\usfm 3.0\n\\id 1JN\n\\c 1\n\\v 1 \\zaln-s |x-strong=\"G35880\"\\*", "1jn", true},
+		{`\h Genesis`, "", false},
+		{"", "", false},
+	}
+	for _, kase := range kases {
+		bookID, aligned := parseUSFMHeader(kase.header)
+		assert.Equal(t, kase.bookID, bookID, kase.header)
+		assert.Equal(t, kase.aligned, aligned, kase.header)
+	}
+}
+
+func TestGenerateManifest(t *testing.T) {
+	kases := []struct {
+		name     string
+		repoName string
+		paths    []string
+		headers  map[string]string
+		format   string
+		subject  string
+		typ      string
+		projects [][2]string // identifier and path
+	}{
+		{
+			name:     "USFM files sorted by book",
+			repoName: "scripture",
+			paths:    []string{"41-MAT.usfm", "01-GEN.usfm", "README.md", "LICENSE.md"},
+			headers:  map[string]string{"41-MAT.usfm": `\id MAT`, "01-GEN.usfm": `\id GEN`},
+			format:   manifestFormatUSFM,
+			subject:  "Bible",
+			typ:      "bundle",
+			projects: [][2]string{{"gen", "./01-GEN.usfm"}, {"mat", "./41-MAT.usfm"}},
+		},
+		{
+			name:     "aligned USFM files in a directory, the book from the file name without an \\id",
+			repoName: "scripture",
+			paths:    []string{"src/", "src/57-TIT.usfm", "src/nt/", "src/nt/jud.usfm"},
+			headers:  map[string]string{"src/57-TIT.usfm": `\id TIT \zaln-s`},
+			format:   manifestFormatUSFM,
+			subject:  "Aligned Bible",
+			typ:      "bundle",
+			projects: [][2]string{{"tit", "./src/57-TIT.usfm"}, {"jud", "./src/nt/jud.usfm"}},
+		},
+		{
+			name:     "the file nearest the top of the tree is the project",
+			repoName: "scripture",
+			paths:    []string{"backup/", "backup/01-GEN.usfm", "01-GEN.usfm"},
+			headers:  map[string]string{"backup/01-GEN.usfm": `\id GEN`, "01-GEN.usfm": `\id GEN`},
+			format:   manifestFormatUSFM,
+			subject:  "Bible",
+			typ:      "bundle",
+			projects: [][2]string{{"gen", "./01-GEN.usfm"}},
+		},
+		{
+			name:     "TSV files of books and OBS",
+			repoName: "notes",
+			paths:    []string{"en_tn_02-EXO.tsv", "en_tn_01-GEN.tsv", "tn_OBS.tsv", "other.tsv"},
+			format:   manifestFormatTSV,
+			typ:      "",
+			projects: [][2]string{{"obs", "./tn_OBS.tsv"}, {"gen", "./en_tn_01-GEN.tsv"}, {"exo", "./en_tn_02-EXO.tsv"}},
+		},
+		{
+			name:     "tN and tQ book directories at the top of the tree",
+			repoName: "questions",
+			paths:    []string{"gen/", "gen/01/", "gen/01/01.md", "media/", "media/exo/"},
+			format:   manifestFormatMarkdown,
+			projects: [][2]string{{"gen", "./gen"}},
+		},
+		{
+			name:     "OBS",
+			repoName: "obs",
+			paths:    []string{"content/", "content/01.md", "content/front/"},
+			format:   manifestFormatMarkdown,
+			subject:  "Open Bible Stories",
+			typ:      "book",
+			projects: [][2]string{{"obs", "./content"}},
+		},
+		{
+			name:     "tA manuals and tW",
+			repoName: "helps",
+			paths:    []string{"translate/", "intro/", "bible/", "process/figs-metaphor/"},
+			format:   manifestFormatMarkdown,
+			projects: [][2]string{{"translate", "./translate"}, {"intro", "./intro"}, {"bible", "./bible"}},
+		},
+		{
+			name:     "nothing to be found",
+			repoName: "empty",
+			paths:    []string{"README.md"},
+		},
+	}
+	for _, kase := range kases {
+		t.Run(kase.name, func(t *testing.T) {
+			repo := &models.Repository{Name: kase.repoName, OwnerName: "unfoldingWord"}
+			manifest, err := generateManifest(repo, testEntries(kase.paths, kase.headers))
+			assert.NoError(t, err)
+
+			dc := manifest["dublin_core"].(map[string]interface{})
+			if kase.format == "" {
+				assert.NotContains(t, dc, "format")
+			} else {
+				assert.Equal(t, kase.format, dc["format"])
+			}
+			assert.Equal(t, kase.subject, dc["subject"])
+			assert.Equal(t, kase.typ, dc["type"])
+
+			projects := manifest["projects"].([]interface{})
+			if assert.Len(t, projects, len(kase.projects)) {
+				for i, project := range projects {
+					assert.Equal(t, kase.projects[i][0], project.(map[string]interface{})["identifier"])
+					assert.Equal(t, kase.projects[i][1], project.(map[string]interface{})["path"])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDublinCore(t *testing.T) {
+	dc := generateDublinCore(&models.Repository{Name: "Scripture", OwnerName: "unfoldingWord", Description: "A Bible"}, manifestFormatUSFM, false)
+	assert.Equal(t, "scripture", dc["identifier"])
+	assert.Equal(t, "A Bible", dc["title"])
+	assert.Equal(t, "A Bible", dc["description"])
+	assert.Equal(t, "unfoldingWord", dc["creator"])
+	assert.Equal(t, "unfoldingWord", dc["publisher"])
+	assert.Equal(t, "rc0.2", dc["conformsto"])
+	assert.Equal(t, "1", dc["version"])
+	assert.Equal(t, dc["issued"], dc["modified"])
+	assert.Equal(t, map[string]interface{}{"identifier": "", "title": "", "direction": "ltr"}, dc["language"])
+
+	// The title is the subject without a description, or the repo name without either
+	dc = generateDublinCore(&models.Repository{Name: "obs"}, manifestFormatMarkdown, false)
+	assert.Equal(t, "Open Bible Stories", dc["title"])
+	dc = generateDublinCore(&models.Repository{Name: "Notes"}, manifestFormatTSV, false)
+	assert.Equal(t, "Notes", dc["title"])
+	assert.Equal(t, "", dc["type"])
+}
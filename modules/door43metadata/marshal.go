@@ -0,0 +1,151 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// MarshalManifest returns the manifest as YAML to be saved as manifest.yaml. If the original manifest.yaml is given,
+// its YAML is kept for the values that did not change, with the order of its keys and its comments, so that only the
+// lines of the changed values differ. Keys that are not in the original are added at the end of their mapping.
+func MarshalManifest(original []byte, manifest map[string]interface{}) ([]byte, error) {
+	var doc yamlv3.Node
+	if len(bytes.TrimSpace(original)) > 0 {
+		if err := yamlv3.Unmarshal(original, &doc); err != nil {
+			doc = yamlv3.Node{}
+		}
+	}
+	if doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 {
+		return yaml.Marshal(manifest)
+	}
+
+	root, err := mergeYAMLNode(doc.Content[0], manifest)
+	if err != nil {
+		return nil, err
+	}
+	doc.Content[0] = root
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeYAMLNode returns the node of a value, keeping the node if the value did not change and the nodes of
+// the keys and items of a mapping or sequence that did not. A node is nil if the value is new.
+func mergeYAMLNode(node *yamlv3.Node, value interface{}) (*yamlv3.Node, error) {
+	if node != nil && isYAMLNodeValue(node, value) {
+		return node, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if node == nil || node.Kind != yamlv3.MappingNode {
+			break
+		}
+		content := make([]*yamlv3.Node, 0, len(v)*2)
+		seen := make(map[string]bool, len(v))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			child, ok := v[key]
+			if !ok || seen[key] {
+				continue
+			}
+			seen[key] = true
+			childNode, err := mergeYAMLNode(node.Content[i+1], child)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, node.Content[i], childNode)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childNode, err := mergeYAMLNode(nil, v[key])
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, childNode)
+		}
+		node.Content = content
+		return node, nil
+	case []interface{}:
+		if node == nil || node.Kind != yamlv3.SequenceNode {
+			break
+		}
+		content := make([]*yamlv3.Node, len(v))
+		for i, child := range v {
+			var childNode *yamlv3.Node
+			if i < len(node.Content) {
+				childNode = node.Content[i]
+			}
+			var err error
+			if content[i], err = mergeYAMLNode(childNode, child); err != nil {
+				return nil, err
+			}
+		}
+		node.Content = content
+		return node, nil
+	}
+
+	// The value is marshaled as a whole manifest would be if it had no original
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	newNode := doc.Content[0]
+	if node != nil {
+		newNode.HeadComment = node.HeadComment
+		newNode.LineComment = node.LineComment
+		newNode.FootComment = node.FootComment
+		if node.Kind == yamlv3.ScalarNode && newNode.Kind == yamlv3.ScalarNode &&
+			node.ShortTag() == "!!str" && newNode.ShortTag() == "!!str" {
+			newNode.Style = node.Style
+		}
+	}
+	return newNode, nil
+}
+
+// isYAMLNodeValue returns whether a node has the value, as it would be read from a manifest.yaml
+func isYAMLNodeValue(node *yamlv3.Node, value interface{}) bool {
+	content, err := yamlv3.Marshal(node)
+	if err != nil {
+		return false
+	}
+	var nodeValue, otherValue interface{}
+	if err := yaml.Unmarshal(content, &nodeValue); err != nil {
+		return false
+	}
+	otherContent, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(otherContent, &otherValue); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(nodeValue, otherValue)
+}
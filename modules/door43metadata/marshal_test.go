@@ -0,0 +1,99 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = `# The manifest of the ULT
+dublin_core:
+  type: bundle
+  conformsto: 'rc0.2'
+  identifier: ult
+  title: unfoldingWord Literal Text
+  version: '5' # the version of the release
+  language:
+    identifier: en
+    title: English
+    direction: ltr
+  relation:
+    - en/tn
+    - en/tw
+checking:
+  checking_level: 3
+projects:
+  - title: Genesis
+    identifier: gen
+    path: ./01-GEN.usfm
+`
+
+func readTestManifest(t *testing.T, content string) map[string]interface{} {
+	var manifest map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(content), &manifest))
+	return manifest
+}
+
+func TestMarshalManifest(t *testing.T) {
+	// An unchanged manifest is kept as it is
+	manifest := readTestManifest(t, testManifest)
+	content, err := MarshalManifest([]byte(testManifest), manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, testManifest, string(content))
+
+	// Only the changed values differ, new keys are at the end of their mapping
+	dc := manifest["dublin_core"].(map[string]interface{})
+	dc["version"] = "6"
+	dc["relation"] = []interface{}{"en/tn", "en/tq"}
+	dc["publisher"] = "unfoldingWord"
+	delete(manifest, "checking")
+	manifest["projects"] = append(manifest["projects"].([]interface{}), map[string]interface{}{
+		"title":      "Exodus",
+		"identifier": "exo",
+		"path":       "./02-EXO.usfm",
+	})
+	content, err = MarshalManifest([]byte(testManifest), manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, `# The manifest of the ULT
+dublin_core:
+  type: bundle
+  conformsto: 'rc0.2'
+  identifier: ult
+  title: unfoldingWord Literal Text
+  version: '6' # the version of the release
+  language:
+    identifier: en
+    title: English
+    direction: ltr
+  relation:
+    - en/tn
+    - en/tq
+  publisher: unfoldingWord
+projects:
+  - title: Genesis
+    identifier: gen
+    path: ./01-GEN.usfm
+  - identifier: exo
+    path: ./02-EXO.usfm
+    title: Exodus
+`, string(content))
+	assert.Equal(t, manifest, readTestManifest(t, string(content)))
+
+	// A string that looks like a number stays a string
+	content, err = MarshalManifest([]byte("dublin_core:\n  version: 6\n"), map[string]interface{}{"dublin_core": map[string]interface{}{"version": "7"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "dublin_core:\n  version: \"7\"\n", string(content))
+
+	// Without an original manifest.yaml, or one that cannot be read, the manifest is marshaled as a whole
+	content, err = MarshalManifest(nil, map[string]interface{}{"b": 1, "a": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a: x\nb: 1\n", string(content))
+	content, err = MarshalManifest([]byte("a: [\n"), map[string]interface{}{"a": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a: x\n", string(content))
+}
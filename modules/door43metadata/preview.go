@@ -0,0 +1,113 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// Preview is the door43 metadata a ref of a repository would have, processed as a release or the default branch
+// would be but not saved
+type Preview struct {
+	Ref      string
+	CommitID string
+	// Door43Metadata is nil if the ref has no valid manifest.yaml
+	Door43Metadata *models.Door43Metadata
+	// Manifest is nil if the ref has no manifest.yaml or it cannot be read
+	Manifest *map[string]interface{}
+	// Errors are why the manifest.yaml is not valid
+	Errors []string
+	// Warnings are the inconsistencies of a valid manifest.yaml, e.g. with the name of the repository
+	Warnings []string
+	// Prod is the latest production entry of the repository, nil if there is none
+	Prod *models.Door43Metadata
+	// Changes are the changes from the metadata of the production entry to the manifest
+	Changes []*dcs.MetadataChange
+}
+
+// Valid returns whether the ref has a valid manifest.yaml
+func (p *Preview) Valid() bool {
+	return p.Door43Metadata != nil
+}
+
+// PreviewDoor43Metadata processes the metadata of a ref of a repository, e.g. a branch, tag, commit ID or the
+// head of a pull request, without saving it. The metadata of a tag is that of its release if it has one.
+// The ref is shown as the branch or tag of the metadata if given, e.g. as the head branch of a pull request.
+func PreviewDoor43Metadata(repo *models.Repository, gitRepo *git.Repository, ref, branchOrTag string) (*Preview, error) {
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	preview := &Preview{
+		Ref:      ref,
+		CommitID: commit.ID.String(),
+	}
+
+	prod, err := repo.GetLatestProdCatalogMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if prod != nil {
+		prod.Repo = repo
+		if err := prod.LoadAttributes(); err != nil {
+			return nil, err
+		}
+		preview.Prod = prod
+	}
+
+	var release *models.Release
+	if gitRepo.IsTagExist(ref) {
+		release, err = models.GetRelease(repo.ID, ref)
+		if err != nil && !models.IsErrReleaseNotExist(err) {
+			return nil, err
+		}
+		if release != nil && release.IsTag {
+			release = nil
+		}
+		if release != nil {
+			if err := release.LoadAttributes(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	build, err := BuildDoor43Metadata(repo, release, commit)
+	if err != nil {
+		return nil, err
+	}
+	preview.Manifest = build.Manifest
+	preview.Errors = build.Errors
+	if build.Manifest == nil {
+		return preview, nil
+	}
+	var prodMetadata *map[string]interface{}
+	if prod != nil {
+		prodMetadata = prod.Metadata
+	}
+	preview.Changes = dcs.DiffMetadata(prodMetadata, build.Manifest)
+	if build.Door43Metadata == nil {
+		return preview, nil
+	}
+
+	if branchOrTag != "" {
+		build.Door43Metadata.BranchOrTag = branchOrTag
+	} else if release == nil {
+		build.Door43Metadata.BranchOrTag = ref
+	}
+	preview.Door43Metadata = build.Door43Metadata
+	preview.Warnings = build.Door43Metadata.Warnings
+	return preview, nil
+}
+
+// PreviewDoor43MetadataForPullRequest processes the metadata of the head of a pull request in its base repository
+// without saving it, the head branch being shown as the branch of the metadata
+func PreviewDoor43MetadataForPullRequest(pr *models.PullRequest, gitRepo *git.Repository) (*Preview, error) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, err
+	}
+	return PreviewDoor43Metadata(pr.BaseRepo, gitRepo, pr.GetGitRefName(), pr.HeadBranch)
+}
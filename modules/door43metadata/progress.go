@@ -0,0 +1,77 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// GetBookProgresses counts the verses with text of the USFM file of each book of the projects of a manifest,
+// against the versification of the book. The projects without a USFM file in the commit are skipped.
+func GetBookProgresses(commit *git.Commit, manifest *map[string]interface{}) ([]*models.BookProgress, error) {
+	if manifest == nil {
+		return nil, nil
+	}
+	projects, _ := (*manifest)["projects"].([]interface{})
+	var bps []*models.BookProgress
+	seen := make(map[string]bool)
+	for _, p := range projects {
+		project, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		identifier, _ := project["identifier"].(string)
+		projectPath, _ := project["path"].(string)
+		projectPath = path.Clean(strings.TrimPrefix(projectPath, "./"))
+		book := dcs.GetBook(identifier)
+		if book == nil || seen[book.ID] {
+			continue
+		}
+		switch strings.ToLower(path.Ext(projectPath)) {
+		case ".usfm", ".usfm3", ".sfm":
+		default:
+			continue
+		}
+
+		blob, err := commit.GetBlobByPath(projectPath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		usfm, err := readBlob(blob)
+		if err != nil {
+			return nil, err
+		}
+		seen[book.ID] = true
+		bps = append(bps, &models.BookProgress{
+			Book:        book.ID,
+			Path:        projectPath,
+			Verses:      dcs.CountUsfmVerses(usfm, book),
+			TotalVerses: book.VerseCount(),
+		})
+	}
+	return bps, nil
+}
+
+func readBlob(blob *git.Blob) (string, error) {
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return "", err
+	}
+	defer dataRc.Close()
+	data, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+)
+
+// GetTranslationRepoName returns the name of the repo of the translation of a catalog entry into a language,
+// {lang}_{subject} such as fr_ult for en_ult, the subject being the identifier of the resource if the name
+// of the repo of the entry is not one of a language and subject
+func GetTranslationRepoName(dm *models.Door43Metadata, lang string) string {
+	subject := dcs.GetDublinCoreString(dm.Metadata, "identifier")
+	if dm.Repo != nil {
+		if parts := strings.SplitN(dm.Repo.Name, "_", 2); len(parts) == 2 && parts[1] != "" {
+			subject = parts[1]
+		}
+	}
+	return lang + "_" + strings.ToLower(subject)
+}
+
+// TranslateManifest rewrites the manifest of a catalog entry as that of its translation into a language:
+// the language is the new one, the source is the entry at its version, and the translation has no contributor yet,
+// is at version 1 and is not checked yet. The title and direction of the language are filled in from tD if empty.
+func TranslateManifest(manifest map[string]interface{}, source *models.Door43Metadata, lang, langTitle, langDirection string) {
+	dc, ok := manifest["dublin_core"].(map[string]interface{})
+	if !ok {
+		dc = map[string]interface{}{}
+		manifest["dublin_core"] = dc
+	}
+	dc["language"] = map[string]interface{}{
+		"identifier": lang,
+		"title":      langTitle,
+		"direction":  langDirection,
+	}
+	dc["source"] = []interface{}{
+		map[string]interface{}{
+			"identifier": dcs.GetDublinCoreString(source.Metadata, "identifier"),
+			"language":   dcs.GetDublinCoreString(source.Metadata, "language", "identifier"),
+			"version":    dcs.GetDublinCoreString(source.Metadata, "version"),
+		},
+	}
+	dc["contributor"] = []interface{}{}
+	dc["version"] = "1"
+	today := time.Now().Format("2006-01-02")
+	dc["issued"] = today
+	dc["modified"] = today
+	manifest["checking"] = map[string]interface{}{
+		"checking_entity": []interface{}{},
+		"checking_level":  "1",
+	}
+	if langTitle == "" || langDirection == "" {
+		fillManifestLanguage(manifest)
+	}
+}
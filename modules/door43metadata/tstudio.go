@@ -0,0 +1,155 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package door43metadata
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+)
+
+// TsManifestLanguage is the target language of a translationStudio project
+type TsManifestLanguage struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+}
+
+// TsManifestItem is the project or resource of a translationStudio project
+type TsManifestItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// TsManifestSource is a source translation of a translationStudio project
+type TsManifestSource struct {
+	LanguageID string `json:"language_id"`
+	ResourceID string `json:"resource_id"`
+	Version    string `json:"version"`
+}
+
+// TsManifest is the manifest.json file of a translationStudio project, e.g. of en_tit_text_ulb
+type TsManifest struct {
+	Project            TsManifestItem      `json:"project"`
+	TargetLanguage     TsManifestLanguage  `json:"target_language"`
+	Resource           TsManifestItem      `json:"resource"`
+	SourceTranslations []*TsManifestSource `json:"source_translations"`
+	Translators        []string            `json:"translators"`
+}
+
+// ParseTsManifest parses the manifest.json file of a translationStudio project, returning nil if it is not one
+func ParseTsManifest(data []byte) *TsManifest {
+	manifest := &TsManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil || manifest.Project.ID == "" {
+		return nil
+	}
+	return manifest
+}
+
+// Book returns the book of the Bible of the project, or nil if it is not a book of the Bible
+func (m *TsManifest) Book() *dcs.Book {
+	return dcs.GetBook(m.Project.ID)
+}
+
+// GenerateManifestFromTs generates the manifest of a resource container for translationStudio projects of books
+// of the same language and resource, their USFM files being named after their books, e.g. 57-TIT.usfm
+func GenerateManifestFromTs(repo *models.Repository, tsManifests []*TsManifest) map[string]interface{} {
+	var projects []*manifestProject
+	seen := make(map[string]bool)
+	for _, ts := range tsManifests {
+		book := ts.Book()
+		if book == nil || seen[book.ID] {
+			continue
+		}
+		seen[book.ID] = true
+		project := newBookProject(book, book.UsfmFileName())
+		if ts.Project.Name != "" {
+			project.Title = ts.Project.Name
+		}
+		projects = append(projects, project)
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Sort < projects[j].Sort
+	})
+	projectMaps := make([]interface{}, len(projects))
+	for i, project := range projects {
+		projectMaps[i] = project.toMap()
+	}
+
+	dublinCore := generateDublinCore(repo, manifestFormatUSFM, false)
+	contributors := []interface{}{}
+	sources := []interface{}{}
+	seenContributors := make(map[string]bool)
+	seenSources := make(map[string]bool)
+	for _, ts := range tsManifests {
+		for _, translator := range ts.Translators {
+			if translator = strings.TrimSpace(translator); translator != "" && !seenContributors[translator] {
+				seenContributors[translator] = true
+				contributors = append(contributors, translator)
+			}
+		}
+		for _, source := range ts.SourceTranslations {
+			key := source.LanguageID + "_" + source.ResourceID
+			if source.LanguageID == "" || source.ResourceID == "" || seenSources[key] {
+				continue
+			}
+			seenSources[key] = true
+			sources = append(sources, map[string]interface{}{
+				"identifier": source.ResourceID,
+				"language":   source.LanguageID,
+				"version":    source.Version,
+			})
+		}
+	}
+	dublinCore["contributor"] = contributors
+	dublinCore["source"] = sources
+	if len(tsManifests) > 0 {
+		ts := tsManifests[0]
+		if ts.Resource.ID != "" && dcs.GetSubjectFromRepoName(strings.ToLower(repo.Name)) == "" {
+			dublinCore["identifier"] = strings.ToLower(ts.Resource.ID)
+		}
+		if ts.Resource.Name != "" && repo.Description == "" {
+			dublinCore["title"] = ts.Resource.Name
+		}
+		if ts.TargetLanguage.ID != "" {
+			language := map[string]interface{}{
+				"identifier": ts.TargetLanguage.ID,
+				"title":      ts.TargetLanguage.Name,
+				"direction":  ts.TargetLanguage.Direction,
+			}
+			if language["direction"] == "" {
+				language["direction"] = "ltr"
+			}
+			dublinCore["language"] = language
+		}
+	}
+
+	checkingEntity := []interface{}{}
+	if repo.OwnerName != "" {
+		checkingEntity = append(checkingEntity, repo.OwnerName)
+	}
+	return map[string]interface{}{
+		"dublin_core": dublinCore,
+		"checking": map[string]interface{}{
+			"checking_entity": checkingEntity,
+			"checking_level":  "1",
+		},
+		"projects": projectMaps,
+	}
+}
+
+// UpdateManifestProjects replaces the projects of an existing manifest by those of a generated one,
+// keeping the rest of the existing manifest, such as its dublin_core and checking, as is
+func UpdateManifestProjects(existing, generated map[string]interface{}) map[string]interface{} {
+	existing["projects"] = generated["projects"]
+	if dublinCore, ok := existing["dublin_core"].(map[string]interface{}); ok {
+		dublinCore["modified"] = time.Now().Format("2006-01-02")
+	}
+	return existing
+}
@@ -21,6 +21,10 @@ const (
 	ZIP ArchiveType = iota + 1
 	// TARGZ tar gz archive type
 	TARGZ
+	/*** DCS Customizations ***/
+	// RCZIP zip archive type of a resource container, filtered by its manifest
+	RCZIP
+	/*** END DCS Customizations ***/
 )
 
 // String converts an ArchiveType to string
@@ -30,6 +34,8 @@ func (a ArchiveType) String() string {
 		return "zip"
 	case TARGZ:
 		return "tar.gz"
+	case RCZIP: // DCS Customizations
+		return "rc.zip" // DCS Customizations
 	}
 	return "unknown"
 }
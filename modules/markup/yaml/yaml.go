@@ -0,0 +1,67 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Markup renderer for YAML files ***/
+
+package markup
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/yaml"
+)
+
+// MarkupName describes markup's name
+var MarkupName = "yaml"
+
+func init() {
+	markup.RegisterRenderer(Renderer{})
+}
+
+// Renderer implements markup.Renderer for YAML files
+type Renderer struct {
+}
+
+// Name implements markup.Renderer
+func (Renderer) Name() string {
+	return MarkupName
+}
+
+// NeedPostProcess implements markup.Renderer
+func (Renderer) NeedPostProcess() bool { return false }
+
+// Extensions implements markup.Renderer
+func (Renderer) Extensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+// SanitizerRules implements markup.Renderer
+func (Renderer) SanitizerRules() []setting.MarkupSanitizerRule {
+	return []setting.MarkupSanitizerRule{
+		{Element: "table", AllowAttr: "data", Regexp: regexp.MustCompile(`^yaml-metadata$`)},
+	}
+}
+
+// Render implements markup.Renderer. A file that can't be parsed is shown as is with the error.
+func (Renderer) Render(ctx *markup.RenderContext, input io.Reader, output io.Writer) error {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	rendered, err := yaml.Render(data)
+	if err != nil {
+		rendered = []byte(fmt.Sprintf("<p><strong>%s</strong></p><pre>%s</pre>",
+			html.EscapeString(err.Error()), html.EscapeString(string(data))))
+	}
+	_, err = output.Write(rendered)
+	return err
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,110 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oaipmh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DublinCore is the unqualified Dublin Core of a record, in the oai_dc format
+type DublinCore struct {
+	XMLNSOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XMLNSDC        string   `xml:"xmlns:dc,attr"`
+	XMLNSXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Titles         []string `xml:"dc:title"`
+	Creators       []string `xml:"dc:creator"`
+	Subjects       []string `xml:"dc:subject"`
+	Descriptions   []string `xml:"dc:description"`
+	Publishers     []string `xml:"dc:publisher"`
+	Contributors   []string `xml:"dc:contributor"`
+	Dates          []string `xml:"dc:date"`
+	Types          []string `xml:"dc:type"`
+	Formats        []string `xml:"dc:format"`
+	Identifiers    []string `xml:"dc:identifier"`
+	Sources        []string `xml:"dc:source"`
+	Languages      []string `xml:"dc:language"`
+	Relations      []string `xml:"dc:relation"`
+	Rights         []string `xml:"dc:rights"`
+}
+
+// dublinCoreValues returns the non-empty values of a field of the dublin_core of a manifest, a list or a single value
+func dublinCoreValues(dc map[string]interface{}, key string) []string {
+	var values []string
+	add := func(value interface{}) {
+		if value == nil {
+			return
+		}
+		if s := strings.TrimSpace(fmt.Sprint(value)); s != "" {
+			values = append(values, s)
+		}
+	}
+	switch value := dc[key].(type) {
+	case []interface{}:
+		for _, v := range value {
+			add(v)
+		}
+	default:
+		add(value)
+	}
+	return values
+}
+
+// NewDublinCore maps the dublin_core of an RC manifest to the unqualified Dublin Core of a record, the identifiers
+// of the record, such as its URLs, coming first
+func NewDublinCore(manifest *map[string]interface{}, identifiers ...string) *DublinCore {
+	record := &DublinCore{
+		XMLNSOAIDC:     "http://www.openarchives.org/OAI/2.0/oai_dc/",
+		XMLNSDC:        "http://purl.org/dc/elements/1.1/",
+		XMLNSXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+		Identifiers:    identifiers,
+	}
+	if manifest == nil {
+		return record
+	}
+	dc, _ := (*manifest)["dublin_core"].(map[string]interface{})
+	if dc == nil {
+		return record
+	}
+
+	record.Titles = dublinCoreValues(dc, "title")
+	record.Creators = dublinCoreValues(dc, "creator")
+	record.Subjects = dublinCoreValues(dc, "subject")
+	record.Descriptions = dublinCoreValues(dc, "description")
+	record.Publishers = dublinCoreValues(dc, "publisher")
+	record.Contributors = dublinCoreValues(dc, "contributor")
+	record.Dates = append(dublinCoreValues(dc, "issued"), dublinCoreValues(dc, "modified")...)
+	record.Types = dublinCoreValues(dc, "type")
+	record.Formats = dublinCoreValues(dc, "format")
+	record.Relations = dublinCoreValues(dc, "relation")
+	record.Rights = dublinCoreValues(dc, "rights")
+	if language, ok := dc["language"].(map[string]interface{}); ok {
+		record.Languages = dublinCoreValues(language, "identifier")
+	}
+	// The sources of a resource are the resources it was translated from, e.g. en_ult v5
+	sources, _ := dc["source"].([]interface{})
+	for _, s := range sources {
+		source, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var name string
+		if language := dublinCoreValues(source, "language"); len(language) > 0 {
+			name = language[0] + "_"
+		}
+		if identifier := dublinCoreValues(source, "identifier"); len(identifier) > 0 {
+			name += identifier[0]
+		}
+		if version := dublinCoreValues(source, "version"); len(version) > 0 {
+			name += " v" + version[0]
+		}
+		if name != "" {
+			record.Sources = append(record.Sources, name)
+		}
+	}
+	return record
+}
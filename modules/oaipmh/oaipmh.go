@@ -0,0 +1,336 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package oaipmh writes the OAI-PMH 2.0 responses harvested by libraries and archives
+package oaipmh
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verbs of the requests
+const (
+	VerbIdentify            = "Identify"
+	VerbListMetadataFormats = "ListMetadataFormats"
+	VerbListSets            = "ListSets"
+	VerbListIdentifiers     = "ListIdentifiers"
+	VerbListRecords         = "ListRecords"
+	VerbGetRecord           = "GetRecord"
+)
+
+// Codes of the errors of the responses
+const (
+	ErrorBadArgument             = "badArgument"
+	ErrorBadResumptionToken      = "badResumptionToken"
+	ErrorBadVerb                 = "badVerb"
+	ErrorCannotDisseminateFormat = "cannotDisseminateFormat"
+	ErrorIDDoesNotExist          = "idDoesNotExist"
+	ErrorNoRecordsMatch          = "noRecordsMatch"
+)
+
+// MetadataPrefixDC is the prefix of the only metadata format, unqualified Dublin Core
+const MetadataPrefixDC = "oai_dc"
+
+// Granularity is the granularity of the datestamps, as in the Identify response
+const Granularity = "YYYY-MM-DDThh:mm:ssZ"
+
+const datestampFormat = "2006-01-02T15:04:05Z"
+
+// verbArguments are the arguments each verb accepts, true if it requires it
+var verbArguments = map[string]map[string]bool{
+	VerbIdentify:            {},
+	VerbListMetadataFormats: {"identifier": false},
+	VerbListSets:            {"resumptionToken": false},
+	VerbListIdentifiers:     {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	VerbListRecords:         {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	VerbGetRecord:           {"identifier": true, "metadataPrefix": true},
+}
+
+// Error is an error of a response
+type Error struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s: %s", err.Code, err.Message)
+}
+
+// CheckArguments checks that the arguments of a request are the ones of its verb, the resumption token
+// being exclusive
+func CheckArguments(args url.Values) *Error {
+	verb := args.Get("verb")
+	allowed, ok := verbArguments[verb]
+	if !ok || len(args["verb"]) > 1 {
+		return &Error{Code: ErrorBadVerb, Message: fmt.Sprintf("Illegal OAI verb: %q", verb)}
+	}
+	for name, values := range args {
+		if name == "verb" {
+			continue
+		}
+		if _, ok := allowed[name]; !ok {
+			return &Error{Code: ErrorBadArgument, Message: fmt.Sprintf("Illegal argument for %s: %s", verb, name)}
+		}
+		if len(values) > 1 {
+			return &Error{Code: ErrorBadArgument, Message: fmt.Sprintf("Repeated argument: %s", name)}
+		}
+	}
+	if args.Get("resumptionToken") != "" {
+		if len(args) > 2 {
+			return &Error{Code: ErrorBadArgument, Message: "resumptionToken is an exclusive argument"}
+		}
+		return nil
+	}
+	for name, required := range allowed {
+		if required && args.Get(name) == "" {
+			return &Error{Code: ErrorBadArgument, Message: fmt.Sprintf("Missing argument for %s: %s", verb, name)}
+		}
+	}
+	return nil
+}
+
+// FormatDatestamp formats a time as a datestamp
+func FormatDatestamp(t time.Time) string {
+	return t.UTC().Format(datestampFormat)
+}
+
+// ParseDatestamp parses a datestamp of a from or until argument, either a day or a time in seconds,
+// a day being until its end if until is true
+func ParseDatestamp(value string, until bool) (time.Time, error) {
+	if t, err := time.Parse(datestampFormat, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return t, err
+	}
+	if until {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
+
+// ResumptionToken is the state of an incomplete list, resumed after its last record in the order they changed
+type ResumptionToken struct {
+	MetadataPrefix string
+	Set            string
+	From           int64
+	Until          int64
+	// AfterUnix and AfterID are the change time and the ID of the last record of the previous page
+	AfterUnix int64
+	AfterID   int64
+	// Cursor is the number of records of the previous pages
+	Cursor int
+}
+
+// Encode encodes the token as the opaque string of the responses
+func (t *ResumptionToken) Encode() string {
+	values := url.Values{}
+	values.Set("p", t.MetadataPrefix)
+	values.Set("s", t.Set)
+	values.Set("f", strconv.FormatInt(t.From, 10))
+	values.Set("u", strconv.FormatInt(t.Until, 10))
+	values.Set("a", strconv.FormatInt(t.AfterUnix, 10)+"."+strconv.FormatInt(t.AfterID, 10))
+	values.Set("c", strconv.Itoa(t.Cursor))
+	return base64.RawURLEncoding.EncodeToString([]byte(values.Encode()))
+}
+
+// DecodeResumptionToken decodes a token encoded in a previous response
+func DecodeResumptionToken(token string) (*ResumptionToken, error) {
+	content, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	values, err := url.ParseQuery(string(content))
+	if err != nil {
+		return nil, err
+	}
+	t := &ResumptionToken{
+		MetadataPrefix: values.Get("p"),
+		Set:            values.Get("s"),
+	}
+	after := strings.SplitN(values.Get("a"), ".", 2)
+	if len(after) != 2 {
+		return nil, fmt.Errorf("invalid position: %q", values.Get("a"))
+	}
+	for _, field := range []struct {
+		value string
+		dest  *int64
+	}{
+		{values.Get("f"), &t.From},
+		{values.Get("u"), &t.Until},
+		{after[0], &t.AfterUnix},
+		{after[1], &t.AfterID},
+	} {
+		if *field.dest, err = strconv.ParseInt(field.value, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if t.Cursor, err = strconv.Atoi(values.Get("c")); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ResumptionTokenElement is the resumption token of a response, empty in the last response of a list
+type ResumptionTokenElement struct {
+	CompleteListSize int64  `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+	Token            string `xml:",chardata"`
+}
+
+// Request is the request element of a response, without attributes if the response is a badVerb
+// or badArgument error
+type Request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	BaseURL         string `xml:",chardata"`
+}
+
+// NewRequest returns the request element of the arguments of a request
+func NewRequest(baseURL string, args url.Values) *Request {
+	return &Request{
+		Verb:            args.Get("verb"),
+		Identifier:      args.Get("identifier"),
+		MetadataPrefix:  args.Get("metadataPrefix"),
+		From:            args.Get("from"),
+		Until:           args.Get("until"),
+		Set:             args.Get("set"),
+		ResumptionToken: args.Get("resumptionToken"),
+		BaseURL:         baseURL,
+	}
+}
+
+// Identify is the description of the repository
+type Identify struct {
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmails       []string `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+// MetadataFormat is a metadata format records are disseminated in
+type MetadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+// DCFormat is the unqualified Dublin Core metadata format
+var DCFormat = &MetadataFormat{
+	MetadataPrefix:    MetadataPrefixDC,
+	Schema:            "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+	MetadataNamespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
+}
+
+// Set is a set records belong to
+type Set struct {
+	Spec string `xml:"setSpec"`
+	Name string `xml:"setName"`
+}
+
+// Header is the header of a record
+type Header struct {
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpecs   []string `xml:"setSpec"`
+}
+
+// Metadata is the metadata of a record
+type Metadata struct {
+	DC *DublinCore `xml:"oai_dc:dc"`
+}
+
+// Record is a record with its metadata
+type Record struct {
+	Header   *Header   `xml:"header"`
+	Metadata *Metadata `xml:"metadata"`
+}
+
+// ListMetadataFormats is the content of a ListMetadataFormats response
+type ListMetadataFormats struct {
+	Formats []*MetadataFormat `xml:"metadataFormat"`
+}
+
+// ListSets is the content of a ListSets response
+type ListSets struct {
+	Sets []*Set `xml:"set"`
+}
+
+// ListIdentifiers is the content of a ListIdentifiers response
+type ListIdentifiers struct {
+	Headers         []*Header               `xml:"header"`
+	ResumptionToken *ResumptionTokenElement `xml:"resumptionToken,omitempty"`
+}
+
+// ListRecords is the content of a ListRecords response
+type ListRecords struct {
+	Records         []*Record               `xml:"record"`
+	ResumptionToken *ResumptionTokenElement `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecord is the content of a GetRecord response
+type GetRecord struct {
+	Record *Record `xml:"record"`
+}
+
+// Response is the response to a request, with the content of its verb or its errors
+type Response struct {
+	XMLName             xml.Name             `xml:"OAI-PMH"`
+	XMLNS               string               `xml:"xmlns,attr"`
+	XMLNSXSI            string               `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string               `xml:"xsi:schemaLocation,attr"`
+	ResponseDate        string               `xml:"responseDate"`
+	Request             *Request             `xml:"request"`
+	Errors              []*Error             `xml:"error"`
+	Identify            *Identify            `xml:"Identify,omitempty"`
+	ListMetadataFormats *ListMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	ListSets            *ListSets            `xml:"ListSets,omitempty"`
+	ListIdentifiers     *ListIdentifiers     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *ListRecords         `xml:"ListRecords,omitempty"`
+	GetRecord           *GetRecord           `xml:"GetRecord,omitempty"`
+}
+
+// NewResponse returns the response to a request made now
+func NewResponse(request *Request) *Response {
+	return &Response{
+		XMLNS:          "http://www.openarchives.org/OAI/2.0/",
+		XMLNSXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd",
+		ResponseDate:   FormatDatestamp(time.Now()),
+		Request:        request,
+	}
+}
+
+// SetError sets the error of the response, removing the attributes of the request if the request
+// itself is illegal
+func (r *Response) SetError(err *Error) {
+	if err.Code == ErrorBadVerb || err.Code == ErrorBadArgument {
+		r.Request = &Request{BaseURL: r.Request.BaseURL}
+	}
+	r.Errors = append(r.Errors, err)
+}
+
+// Marshal returns the response as an XML document
+func (r *Response) Marshal() ([]byte, error) {
+	content, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oaipmh
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckArguments(t *testing.T) {
+	check := func(query string) string {
+		args, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+		if err := CheckArguments(args); err != nil {
+			return err.Code
+		}
+		return ""
+	}
+	assert.Equal(t, "", check("verb=Identify"))
+	assert.Equal(t, "", check("verb=ListRecords&metadataPrefix=oai_dc&set=lang:en&from=2021-01-01"))
+	assert.Equal(t, "", check("verb=ListIdentifiers&resumptionToken=abc"))
+	assert.Equal(t, "", check("verb=GetRecord&identifier=oai:dcs:o/r/v1&metadataPrefix=oai_dc"))
+	assert.Equal(t, ErrorBadVerb, check(""))
+	assert.Equal(t, ErrorBadVerb, check("verb=Harvest"))
+	assert.Equal(t, ErrorBadVerb, check("verb=Identify&verb=ListSets"))
+	assert.Equal(t, ErrorBadArgument, check("verb=Identify&set=lang:en"))
+	assert.Equal(t, ErrorBadArgument, check("verb=ListRecords"))
+	assert.Equal(t, ErrorBadArgument, check("verb=ListRecords&metadataPrefix=oai_dc&set=a&set=b"))
+	assert.Equal(t, ErrorBadArgument, check("verb=ListRecords&metadataPrefix=oai_dc&resumptionToken=abc"))
+	assert.Equal(t, ErrorBadArgument, check("verb=GetRecord&metadataPrefix=oai_dc"))
+}
+
+func TestParseDatestamp(t *testing.T) {
+	from, err := ParseDatestamp("2021-03-04", false)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), from)
+	until, err := ParseDatestamp("2021-03-04", true)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 3, 4, 23, 59, 59, 0, time.UTC), until)
+	exact, err := ParseDatestamp("2021-03-04T05:06:07Z", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "2021-03-04T05:06:07Z", FormatDatestamp(exact))
+	_, err = ParseDatestamp("04/03/2021", false)
+	assert.Error(t, err)
+}
+
+func TestResumptionToken(t *testing.T) {
+	token := &ResumptionToken{
+		MetadataPrefix: MetadataPrefixDC,
+		Set:            "subject:Aligned_Bible",
+		From:           1609459200,
+		AfterUnix:      1614556800,
+		AfterID:        42,
+		Cursor:         50,
+	}
+	decoded, err := DecodeResumptionToken(token.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, token, decoded)
+
+	_, err = DecodeResumptionToken("not a token")
+	assert.Error(t, err)
+	_, err = DecodeResumptionToken((&ResumptionToken{}).Encode()[:10])
+	assert.Error(t, err)
+}
+
+func TestNewDublinCore(t *testing.T) {
+	manifest := map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"title":       "unfoldingWord® Literal Text",
+			"subject":     "Aligned Bible",
+			"description": "An open-licensed translation",
+			"creator":     "unfoldingWord",
+			"contributor": []interface{}{"Alice", "", "Bob"},
+			"publisher":   "unfoldingWord",
+			"issued":      "2021-03-01",
+			"modified":    "2021-03-01",
+			"type":        "bundle",
+			"format":      "text/usfm3",
+			"rights":      "CC BY-SA 4.0",
+			"relation":    []interface{}{"en/tw", "hbo/uhb"},
+			"language":    map[string]interface{}{"identifier": "en", "title": "English"},
+			"source": []interface{}{
+				map[string]interface{}{"identifier": "uhb", "language": "hbo", "version": "2.1.15"},
+			},
+		},
+	}
+	dc := NewDublinCore(&manifest, "https://dcs/unfoldingWord/en_ult/releases/tag/v5")
+	assert.Equal(t, []string{"unfoldingWord® Literal Text"}, dc.Titles)
+	assert.Equal(t, []string{"Alice", "Bob"}, dc.Contributors)
+	assert.Equal(t, []string{"2021-03-01", "2021-03-01"}, dc.Dates)
+	assert.Equal(t, []string{"en"}, dc.Languages)
+	assert.Equal(t, []string{"hbo_uhb v2.1.15"}, dc.Sources)
+	assert.Equal(t, []string{"en/tw", "hbo/uhb"}, dc.Relations)
+	assert.Equal(t, []string{"https://dcs/unfoldingWord/en_ult/releases/tag/v5"}, dc.Identifiers)
+
+	dc = NewDublinCore(nil, "id")
+	assert.Equal(t, []string{"id"}, dc.Identifiers)
+	assert.Nil(t, dc.Titles)
+}
+
+func TestResponseMarshal(t *testing.T) {
+	args := url.Values{"verb": {"GetRecord"}, "identifier": {"oai:dcs:o/r/v1"}, "metadataPrefix": {"oai_dc"}}
+	response := NewResponse(NewRequest("https://dcs/api/catalog/v5/oai", args))
+	manifest := map[string]interface{}{"dublin_core": map[string]interface{}{"title": "Title & more"}}
+	response.GetRecord = &GetRecord{Record: &Record{
+		Header:   &Header{Identifier: "oai:dcs:o/r/v1", Datestamp: "2021-03-01T00:00:00Z", SetSpecs: []string{"lang:en"}},
+		Metadata: &Metadata{DC: NewDublinCore(&manifest)},
+	}}
+	content, err := response.Marshal()
+	assert.NoError(t, err)
+	xml := string(content)
+	assert.Contains(t, xml, `<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"`)
+	assert.Contains(t, xml, `<request verb="GetRecord" identifier="oai:dcs:o/r/v1" metadataPrefix="oai_dc">https://dcs/api/catalog/v5/oai</request>`)
+	assert.Contains(t, xml, `<setSpec>lang:en</setSpec>`)
+	assert.Contains(t, xml, `<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"`)
+	assert.Contains(t, xml, `<dc:title>Title &amp; more</dc:title>`)
+	assert.NotContains(t, xml, "<error")
+
+	response = NewResponse(NewRequest("https://dcs/api/catalog/v5/oai", url.Values{"verb": {"Harvest"}}))
+	response.SetError(&Error{Code: ErrorBadVerb, Message: "Illegal OAI verb"})
+	content, err = response.Marshal()
+	assert.NoError(t, err)
+	xml = string(content)
+	assert.Contains(t, xml, `<request>https://dcs/api/catalog/v5/oai</request>`)
+	assert.Contains(t, xml, `<error code="badVerb">Illegal OAI verb</error>`)
+}
@@ -0,0 +1,334 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package opds writes the OPDS 1.2 (Atom) and OPDS 2.0 (JSON) catalog feeds read by e-reader apps
+package opds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Media types of the OPDS feeds and of the publications they link to
+const (
+	MediaTypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	MediaTypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	MediaTypeJSON        = "application/opds+json"
+	MediaTypeZip         = "application/zip"
+	MediaTypeEPUB        = "application/epub+zip"
+	MediaTypeHTML        = "text/html"
+)
+
+// Relations of the links of the feeds and of their entries
+const (
+	RelSelf        = "self"
+	RelStart       = "start"
+	RelUp          = "up"
+	RelFirst       = "first"
+	RelPrevious    = "previous"
+	RelNext        = "next"
+	RelLast        = "last"
+	RelAlternate   = "alternate"
+	RelSubsection  = "subsection"
+	RelAcquisition = "http://opds-spec.org/acquisition/open-access"
+)
+
+// Link is a link of a feed or of one of its entries
+type Link struct {
+	Rel   string
+	Href  string
+	Type  string
+	Title string
+	// Count is the number of entries of the feed the link is to, if known
+	Count int64
+}
+
+// Entry is an entry of a feed, either a link to another feed in a navigation feed or a publication in
+// an acquisition feed
+type Entry struct {
+	ID        string
+	Title     string
+	Summary   string
+	Updated   time.Time
+	Published time.Time
+	Language  string
+	Publisher string
+	Subjects  []string
+	Links     []*Link
+}
+
+// Feed is a navigation feed, whose entries link to other feeds, or an acquisition feed, whose entries are
+// publications
+type Feed struct {
+	ID          string
+	Title       string
+	Updated     time.Time
+	Acquisition bool
+	Links       []*Link
+	Entries     []*Entry
+	// Total, ItemsPerPage and Page are the pagination of an acquisition feed, Page being 1-based
+	Total        int64
+	ItemsPerPage int
+	Page         int
+}
+
+// MediaType returns the media type of the OPDS 1.2 feed
+func (f *Feed) MediaType() string {
+	if f.Acquisition {
+		return MediaTypeAcquisition
+	}
+	return MediaTypeNavigation
+}
+
+type atomLink struct {
+	Rel      string `xml:"rel,attr,omitempty"`
+	Href     string `xml:"href,attr"`
+	Type     string `xml:"type,attr,omitempty"`
+	Title    string `xml:"title,attr,omitempty"`
+	ThrCount int64  `xml:"thr:count,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string          `xml:"title"`
+	ID         string          `xml:"id"`
+	Updated    string          `xml:"updated"`
+	Published  string          `xml:"published,omitempty"`
+	Author     *atomAuthor     `xml:"author,omitempty"`
+	Language   string          `xml:"dc:language,omitempty"`
+	Publisher  string          `xml:"dc:publisher,omitempty"`
+	Categories []*atomCategory `xml:"category"`
+	Content    *atomContent    `xml:"content,omitempty"`
+	Links      []*atomLink     `xml:"link"`
+}
+
+type atomFeed struct {
+	XMLName         xml.Name     `xml:"feed"`
+	XMLNS           string       `xml:"xmlns,attr"`
+	XMLNSDC         string       `xml:"xmlns:dc,attr"`
+	XMLNSOPDS       string       `xml:"xmlns:opds,attr"`
+	XMLNSOpenSearch string       `xml:"xmlns:opensearch,attr"`
+	XMLNSThr        string       `xml:"xmlns:thr,attr"`
+	ID              string       `xml:"id"`
+	Title           string       `xml:"title"`
+	Updated         string       `xml:"updated"`
+	TotalResults    *int64       `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage    int          `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex      int          `xml:"opensearch:startIndex,omitempty"`
+	Links           []*atomLink  `xml:"link"`
+	Entries         []*atomEntry `xml:"entry"`
+}
+
+// formatTime formats a time as in Atom, returning an empty string for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func toAtomLinks(links []*Link) []*atomLink {
+	atomLinks := make([]*atomLink, 0, len(links))
+	for _, link := range links {
+		atomLinks = append(atomLinks, &atomLink{
+			Rel:      link.Rel,
+			Href:     link.Href,
+			Type:     link.Type,
+			Title:    link.Title,
+			ThrCount: link.Count,
+		})
+	}
+	return atomLinks
+}
+
+// Atom returns the feed as an OPDS 1.2 Atom document
+func (f *Feed) Atom() ([]byte, error) {
+	feed := &atomFeed{
+		XMLNS:           "http://www.w3.org/2005/Atom",
+		XMLNSDC:         "http://purl.org/dc/terms/",
+		XMLNSOPDS:       "http://opds-spec.org/2010/catalog",
+		XMLNSOpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		XMLNSThr:        "http://purl.org/syndication/thread/1.0",
+		ID:              f.ID,
+		Title:           f.Title,
+		Updated:         formatTime(f.Updated),
+		Links:           toAtomLinks(f.Links),
+		Entries:         make([]*atomEntry, 0, len(f.Entries)),
+	}
+	if f.Acquisition {
+		total := f.Total
+		feed.TotalResults = &total
+		feed.ItemsPerPage = f.ItemsPerPage
+		if f.Page > 0 && f.ItemsPerPage > 0 {
+			feed.StartIndex = (f.Page-1)*f.ItemsPerPage + 1
+		}
+	}
+	for _, e := range f.Entries {
+		entry := &atomEntry{
+			Title:     e.Title,
+			ID:        e.ID,
+			Updated:   formatTime(e.Updated),
+			Published: formatTime(e.Published),
+			Language:  e.Language,
+			Publisher: e.Publisher,
+			Links:     toAtomLinks(e.Links),
+		}
+		if entry.Updated == "" {
+			entry.Updated = feed.Updated
+		}
+		if e.Publisher != "" {
+			entry.Author = &atomAuthor{Name: e.Publisher}
+		}
+		for _, subject := range e.Subjects {
+			entry.Categories = append(entry.Categories, &atomCategory{Term: subject, Label: subject})
+		}
+		if e.Summary != "" {
+			entry.Content = &atomContent{Type: "text", Text: e.Summary}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	content, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+type jsonLink struct {
+	Rel        string          `json:"rel,omitempty"`
+	Href       string          `json:"href"`
+	Type       string          `json:"type,omitempty"`
+	Title      string          `json:"title,omitempty"`
+	Properties *jsonProperties `json:"properties,omitempty"`
+}
+
+type jsonProperties struct {
+	NumberOfItems int64 `json:"numberOfItems"`
+}
+
+type jsonFeedMetadata struct {
+	Title         string `json:"title"`
+	Modified      string `json:"modified,omitempty"`
+	NumberOfItems *int64 `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type jsonContributor struct {
+	Name string `json:"name"`
+}
+
+type jsonSubject struct {
+	Name string `json:"name"`
+}
+
+type jsonPublicationMetadata struct {
+	Type        string           `json:"@type"`
+	Identifier  string           `json:"identifier"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Publisher   *jsonContributor `json:"publisher,omitempty"`
+	Subject     []*jsonSubject   `json:"subject,omitempty"`
+	Modified    string           `json:"modified,omitempty"`
+	Published   string           `json:"published,omitempty"`
+}
+
+type jsonPublication struct {
+	Metadata *jsonPublicationMetadata `json:"metadata"`
+	Links    []*jsonLink              `json:"links"`
+}
+
+type jsonFeed struct {
+	Metadata     *jsonFeedMetadata  `json:"metadata"`
+	Links        []*jsonLink        `json:"links"`
+	Navigation   []*jsonLink        `json:"navigation,omitempty"`
+	Publications []*jsonPublication `json:"publications,omitempty"`
+}
+
+func toJSONLink(link *Link) *jsonLink {
+	jl := &jsonLink{
+		Rel:   link.Rel,
+		Href:  link.Href,
+		Type:  link.Type,
+		Title: link.Title,
+	}
+	if link.Count > 0 {
+		jl.Properties = &jsonProperties{NumberOfItems: link.Count}
+	}
+	return jl
+}
+
+func toJSONLinks(links []*Link) []*jsonLink {
+	jsonLinks := make([]*jsonLink, 0, len(links))
+	for _, link := range links {
+		jsonLinks = append(jsonLinks, toJSONLink(link))
+	}
+	return jsonLinks
+}
+
+// JSON returns the feed as an OPDS 2.0 JSON document, the entries of a navigation feed being its navigation
+// links and those of an acquisition feed its publications
+func (f *Feed) JSON() ([]byte, error) {
+	feed := &jsonFeed{
+		Metadata: &jsonFeedMetadata{
+			Title:    f.Title,
+			Modified: formatTime(f.Updated),
+		},
+		Links: toJSONLinks(f.Links),
+	}
+	if f.Acquisition {
+		total := f.Total
+		feed.Metadata.NumberOfItems = &total
+		feed.Metadata.ItemsPerPage = f.ItemsPerPage
+		feed.Metadata.CurrentPage = f.Page
+		feed.Publications = make([]*jsonPublication, 0, len(f.Entries))
+	}
+	for _, e := range f.Entries {
+		if !f.Acquisition {
+			// The entries of a navigation feed are a link each, titled as the entry
+			for _, link := range e.Links {
+				nav := toJSONLink(link)
+				nav.Title = e.Title
+				feed.Navigation = append(feed.Navigation, nav)
+			}
+			continue
+		}
+		publication := &jsonPublication{
+			Metadata: &jsonPublicationMetadata{
+				Type:        "http://schema.org/Book",
+				Identifier:  e.ID,
+				Title:       e.Title,
+				Description: e.Summary,
+				Language:    e.Language,
+				Modified:    formatTime(e.Updated),
+				Published:   formatTime(e.Published),
+			},
+			Links: toJSONLinks(e.Links),
+		}
+		if e.Publisher != "" {
+			publication.Metadata.Publisher = &jsonContributor{Name: e.Publisher}
+		}
+		for _, subject := range e.Subjects {
+			publication.Metadata.Subject = append(publication.Metadata.Subject, &jsonSubject{Name: subject})
+		}
+		feed.Publications = append(feed.Publications, publication)
+	}
+	return json.Marshal(feed)
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opds
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testUpdated = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testAcquisitionFeed() *Feed {
+	return &Feed{
+		ID:          "https://dcs/api/catalog/v5/opds/1.2/search?lang=en",
+		Title:       "Resources",
+		Updated:     testUpdated,
+		Acquisition: true,
+		Links: []*Link{
+			{Rel: RelSelf, Href: "https://dcs/api/catalog/v5/opds/1.2/search?lang=en", Type: MediaTypeAcquisition},
+			{Rel: RelNext, Href: "https://dcs/api/catalog/v5/opds/1.2/search?lang=en&page=2", Type: MediaTypeAcquisition},
+		},
+		Entries: []*Entry{
+			{
+				ID:        "https://dcs/api/catalog/v5/entry/unfoldingWord/en_ult/v5",
+				Title:     "unfoldingWord Literal Text (v5)",
+				Updated:   testUpdated,
+				Published: testUpdated.AddDate(0, -1, 0),
+				Language:  "en",
+				Publisher: "unfoldingWord",
+				Subjects:  []string{"Aligned Bible"},
+				Links: []*Link{
+					{Rel: RelAcquisition, Href: "https://dcs/unfoldingWord/en_ult/archive/v5.zip", Type: MediaTypeZip, Title: "Zip"},
+					{Rel: RelAcquisition, Href: "https://dcs/attachments/1", Type: MediaTypeEPUB, Title: "en_ult_v5.epub"},
+				},
+			},
+		},
+		Total:        51,
+		ItemsPerPage: 50,
+		Page:         1,
+	}
+}
+
+func TestFeedAtom(t *testing.T) {
+	feed := testAcquisitionFeed()
+	assert.Equal(t, MediaTypeAcquisition, feed.MediaType())
+	content, err := feed.Atom()
+	assert.NoError(t, err)
+	atom := string(content)
+	assert.Contains(t, atom, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/terms/"`)
+	assert.Contains(t, atom, `<opensearch:totalResults>51</opensearch:totalResults>`)
+	assert.Contains(t, atom, `<opensearch:startIndex>1</opensearch:startIndex>`)
+	assert.Contains(t, atom, `<link rel="next" href="https://dcs/api/catalog/v5/opds/1.2/search?lang=en&amp;page=2" type="application/atom+xml;profile=opds-catalog;kind=acquisition"></link>`)
+	assert.Contains(t, atom, `<updated>2021-06-01T12:00:00Z</updated>`)
+	assert.Contains(t, atom, `<published>2021-05-01T12:00:00Z</published>`)
+	assert.Contains(t, atom, `<dc:language>en</dc:language>`)
+	assert.Contains(t, atom, `<category term="Aligned Bible" label="Aligned Bible"></category>`)
+	assert.Contains(t, atom, `<link rel="http://opds-spec.org/acquisition/open-access" href="https://dcs/attachments/1" type="application/epub+zip" title="en_ult_v5.epub"></link>`)
+
+	feed = &Feed{ID: "root", Title: "Catalog", Updated: testUpdated, Entries: []*Entry{
+		{ID: "languages", Title: "By Language", Links: []*Link{{Rel: RelSubsection, Href: "/languages", Type: MediaTypeNavigation, Count: 3}}},
+	}}
+	assert.Equal(t, MediaTypeNavigation, feed.MediaType())
+	content, err = feed.Atom()
+	assert.NoError(t, err)
+	atom = string(content)
+	assert.NotContains(t, atom, "opensearch:totalResults")
+	assert.Contains(t, atom, `<link rel="subsection" href="/languages" type="application/atom+xml;profile=opds-catalog;kind=navigation" thr:count="3"></link>`)
+	// An entry without its own update time is as recent as the feed
+	assert.Contains(t, atom, "<title>By Language</title>\n    <id>languages</id>\n    <updated>2021-06-01T12:00:00Z</updated>")
+}
+
+func TestFeedJSON(t *testing.T) {
+	content, err := testAcquisitionFeed().JSON()
+	assert.NoError(t, err)
+	var feed map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &feed))
+	assert.Equal(t, map[string]interface{}{
+		"title":         "Resources",
+		"modified":      "2021-06-01T12:00:00Z",
+		"numberOfItems": float64(51),
+		"itemsPerPage":  float64(50),
+		"currentPage":   float64(1),
+	}, feed["metadata"])
+	assert.Nil(t, feed["navigation"])
+	publications := feed["publications"].([]interface{})
+	if assert.Len(t, publications, 1) {
+		publication := publications[0].(map[string]interface{})
+		metadata := publication["metadata"].(map[string]interface{})
+		assert.Equal(t, "http://schema.org/Book", metadata["@type"])
+		assert.Equal(t, "en", metadata["language"])
+		assert.Equal(t, map[string]interface{}{"name": "unfoldingWord"}, metadata["publisher"])
+		assert.Len(t, publication["links"], 2)
+	}
+
+	content, err = (&Feed{Title: "Catalog", Entries: []*Entry{
+		{Title: "By Language", Links: []*Link{{Rel: RelSubsection, Href: "/languages", Type: MediaTypeJSON, Count: 3}}},
+	}}).JSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"metadata": {"title": "Catalog"},
+		"links": [],
+		"navigation": [{"rel": "subsection", "href": "/languages", "type": "application/opds+json", "title": "By Language", "properties": {"numberOfItems": 3}}]
+	}`, string(content))
+}
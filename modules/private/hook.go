@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -102,6 +103,13 @@ func HookPreReceive(ctx context.Context, ownerName, repoName string, opts HookOp
 		return resp.StatusCode, decodeJSONError(resp).Err
 	}
 
+	/*** DCS Customizations ***/
+	// A successful check may have a message for the user
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return http.StatusOK, decodeJSONError(resp).UserMsg
+	}
+	/*** END DCS Customizations ***/
+
 	return http.StatusOK, ""
 }
 
@@ -26,6 +26,9 @@ func newRequest(ctx context.Context, url, method string) *httplib.Request {
 // Response internal request response
 type Response struct {
 	Err string `json:"err"`
+	/*** DCS Customizations ***/
+	UserMsg string `json:"user_msg,omitempty"` // message for the user of a successful request, e.g. a warning
+	/*** END DCS Customizations ***/
 }
 
 func decodeJSONError(resp *http.Response) *Response {
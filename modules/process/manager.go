@@ -70,6 +70,19 @@ func (pm *Manager) Add(description string, cancel context.CancelFunc) int64 {
 	return pid
 }
 
+/*** DCS Customizations ***/
+
+// SetDescription updates the description of a process, e.g. to report the progress of a long running process
+func (pm *Manager) SetDescription(pid int64, description string) {
+	pm.mutex.Lock()
+	if process, ok := pm.processes[pid]; ok {
+		process.Description = description
+	}
+	pm.mutex.Unlock()
+}
+
+/*** END DCS Customizations ***/
+
 // Remove a process from the ProcessManager.
 func (pm *Manager) Remove(pid int64) {
 	pm.mutex.Lock()
@@ -92,7 +105,10 @@ func (pm *Manager) Processes() []*Process {
 	pm.mutex.Lock()
 	processes := make([]*Process, 0, len(pm.processes))
 	for _, process := range pm.processes {
-		processes = append(processes, process)
+		/*** DCS Customizations - copy so a description set afterwards doesn't race with the caller ***/
+		p := *process
+		processes = append(processes, &p)
+		/*** END DCS Customizations ***/
 	}
 	pm.mutex.Unlock()
 	sort.Sort(processList(processes))
@@ -61,6 +61,26 @@ func TestManager_Remove(t *testing.T) {
 	assert.False(t, exists, "PID %d is in the list but shouldn't", pid2)
 }
 
+/*** DCS Customizations ***/
+
+func TestManager_SetDescription(t *testing.T) {
+	pm := Manager{processes: make(map[int64]*Process)}
+
+	pid := pm.Add("foo", nil)
+	processes := pm.Processes()
+	pm.SetDescription(pid, "foo: 1/2")
+
+	assert.Equal(t, "foo", processes[0].Description, "expected the listed process to be unchanged")
+	assert.Equal(t, "foo: 1/2", pm.Processes()[0].Description)
+
+	// A removed process is ignored
+	pm.Remove(pid)
+	pm.SetDescription(pid, "bar")
+	assert.Empty(t, pm.Processes())
+}
+
+/*** END DCS Customizations ***/
+
 func TestExecTimeoutNever(t *testing.T) {
 
 	// TODO Investigate how to improve the time elapsed per round.
@@ -0,0 +1,129 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Commit of a directory of files as the whole tree of a branch ***/
+
+package repofiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+)
+
+// ImportRepoFilesOptions contains the options to import a directory of files into a branch
+type ImportRepoFilesOptions struct {
+	Branch  string
+	Message string
+	Dir     string
+	Signoff bool
+}
+
+// ImportRepoFiles commits the files of a directory as the whole tree of a branch, the files of the branch
+// that the directory doesn't have being removed, and returns the ID of the commit. The first commit of
+// an empty repository is made to its default branch. Nothing is committed if the tree is unchanged,
+// the ID of the last commit of the branch being returned.
+func ImportRepoFiles(repo *models.Repository, doer *models.User, opts *ImportRepoFilesOptions) (string, error) {
+	if opts.Branch == "" || repo.IsEmpty {
+		opts.Branch = repo.DefaultBranch
+	}
+
+	if !repo.IsEmpty {
+		protectedBranch, err := repo.GetBranchProtection(opts.Branch)
+		if err != nil {
+			return "", err
+		}
+		if protectedBranch != nil && !protectedBranch.CanUserPush(doer.ID) {
+			return "", models.ErrUserCannotCommit{
+				UserName: doer.LowerName,
+			}
+		}
+	}
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+	if repo.IsEmpty {
+		err = t.Init()
+	} else {
+		err = t.Clone(opts.Branch)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The index starts empty so that the tree only has the files of the directory
+	err = filepath.Walk(opts.Dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(opts.Dir, filePath)
+		if err != nil {
+			return err
+		}
+		treePath := CleanUploadFileName(filepath.ToSlash(relPath))
+		if treePath == "" {
+			return models.ErrFilenameInvalid{Path: relPath}
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		objectHash, err := t.HashObject(file)
+		if err != nil {
+			return err
+		}
+		mode := "100644"
+		if info.Mode()&0111 != 0 {
+			mode = "100755"
+		}
+		return t.AddObjectToIndex(mode, objectHash, treePath)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+	if !repo.IsEmpty {
+		lastCommitID, err := t.GetLastCommit()
+		if err != nil {
+			return "", err
+		}
+		lastTreeHash, err := t.GetLastCommitByRef(lastCommitID + "^{tree}")
+		if err != nil {
+			return "", err
+		}
+		if lastTreeHash == treeHash {
+			return lastCommitID, nil
+		}
+	}
+
+	commitHash, err := t.CommitTree(doer, doer, treeHash, strings.TrimSpace(opts.Message), opts.Signoff)
+	if err != nil {
+		return "", err
+	}
+	if err := t.Push(doer, commitHash, opts.Branch); err != nil {
+		return "", err
+	}
+	return commitHash, nil
+}
+
+/*** END DCS Customizations ***/
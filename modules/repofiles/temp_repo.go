@@ -26,6 +26,7 @@ type TemporaryUploadRepository struct {
 	repo     *models.Repository
 	gitRepo  *git.Repository
 	basePath string
+	isInit   bool // DCS Customizations
 }
 
 // NewTemporaryUploadRepository creates a new temporary upload repository
@@ -73,6 +74,24 @@ func (t *TemporaryUploadRepository) Clone(branch string) error {
 	return nil
 }
 
+/*** DCS Customizations ***/
+
+// Init initializes the repository without a HEAD, for the first commit of an empty repository
+func (t *TemporaryUploadRepository) Init() error {
+	if err := git.InitRepository(t.basePath, true); err != nil {
+		return err
+	}
+	gitRepo, err := git.OpenRepository(t.basePath)
+	if err != nil {
+		return err
+	}
+	t.gitRepo = gitRepo
+	t.isInit = true
+	return nil
+}
+
+/*** END DCS Customizations ***/
+
 // SetDefaultIndex sets the git index to our HEAD
 func (t *TemporaryUploadRepository) SetDefaultIndex() error {
 	if _, err := git.NewCommand("read-tree", "HEAD").RunInDir(t.basePath); err != nil {
@@ -212,6 +231,11 @@ func (t *TemporaryUploadRepository) CommitTreeWithDate(author, committer *models
 	_, _ = messageBytes.WriteString("\n")
 
 	args := []string{"commit-tree", treeHash, "-p", "HEAD"}
+	/*** DCS Customizations - The first commit of an empty repository has no parent ***/
+	if t.isInit {
+		args = args[:2]
+	}
+	/*** END DCS Customizations ***/
 
 	// Determine if we should sign
 	if git.CheckGitVersionAtLeast("1.7.9") == nil {
@@ -97,7 +97,7 @@ func checkGiteaTemplate(tmpDir string) (*models.GiteaTemplate, error) {
 	return gt, nil
 }
 
-func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmpDir string) error {
+func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmpDir string, opts models.GenerateRepoOptions) error { // DCS Customizations
 	commitTimeStr := time.Now().Format(time.RFC3339)
 	authorSig := repo.Owner.NewGitSig()
 
@@ -111,11 +111,18 @@ func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmp
 		"GIT_COMMITTER_DATE="+commitTimeStr,
 	)
 
+	/*** DCS Customizations ***/
+	ref := opts.Ref
+	if ref == "" {
+		ref = templateRepo.DefaultBranch
+	}
+	/*** END DCS Customizations ***/
+
 	// Clone to temporary path and do the init commit.
 	templateRepoPath := templateRepo.RepoPath()
 	if err := git.Clone(templateRepoPath, tmpDir, git.CloneRepoOptions{
 		Depth:  1,
-		Branch: templateRepo.DefaultBranch,
+		Branch: ref, // DCS Customizations
 	}); err != nil {
 		return fmt.Errorf("git clone: %v", err)
 	}
@@ -170,6 +177,14 @@ func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmp
 		}
 	}
 
+	/*** DCS Customizations ***/
+	if opts.ContentTransform != nil {
+		if err := opts.ContentTransform(tmpDir); err != nil {
+			return fmt.Errorf("ContentTransform: %v", err)
+		}
+	}
+	/*** END DCS Customizations ***/
+
 	if err := git.InitRepository(tmpDir, false); err != nil {
 		return err
 	}
@@ -185,7 +200,7 @@ func generateRepoCommit(repo, templateRepo, generateRepo *models.Repository, tmp
 	return initRepoCommit(tmpDir, repo, repo.Owner, templateRepo.DefaultBranch)
 }
 
-func generateGitContent(ctx models.DBContext, repo, templateRepo, generateRepo *models.Repository) (err error) {
+func generateGitContent(ctx models.DBContext, repo, templateRepo, generateRepo *models.Repository, opts models.GenerateRepoOptions) (err error) { // DCS Customizations
 	tmpDir, err := ioutil.TempDir(os.TempDir(), "gitea-"+repo.Name)
 	if err != nil {
 		return fmt.Errorf("Failed to create temp dir for repository %s: %v", repo.RepoPath(), err)
@@ -197,7 +212,7 @@ func generateGitContent(ctx models.DBContext, repo, templateRepo, generateRepo *
 		}
 	}()
 
-	if err = generateRepoCommit(repo, templateRepo, generateRepo, tmpDir); err != nil {
+	if err = generateRepoCommit(repo, templateRepo, generateRepo, tmpDir, opts); err != nil { // DCS Customizations
 		return fmt.Errorf("generateRepoCommit: %v", err)
 	}
 
@@ -223,8 +238,8 @@ func generateGitContent(ctx models.DBContext, repo, templateRepo, generateRepo *
 }
 
 // GenerateGitContent generates git content from a template repository
-func GenerateGitContent(ctx models.DBContext, templateRepo, generateRepo *models.Repository) error {
-	if err := generateGitContent(ctx, generateRepo, templateRepo, generateRepo); err != nil {
+func GenerateGitContent(ctx models.DBContext, templateRepo, generateRepo *models.Repository, opts models.GenerateRepoOptions) error { // DCS Customizations
+	if err := generateGitContent(ctx, generateRepo, templateRepo, generateRepo, opts); err != nil { // DCS Customizations
 		return err
 	}
 
@@ -0,0 +1,233 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Checking pushes for sensitive data ***/
+
+package scrubber
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// maxCheckFileSize is the size above which the files of a push are not checked
+const maxCheckFileSize = 5 * 1024 * 1024
+
+// CheckResult is the sensitive data found in the files of a ref that a push adds or changes
+type CheckResult struct {
+	RefName  string
+	CommitID string
+	// Checked are the paths of the files that were checked, including those deleted at the tip
+	Checked []string
+	// Found are the paths of the files with sensitive data, and what was found in each
+	Found map[string][]string
+}
+
+// Message describes the sensitive data found, one file per line
+func (r *CheckResult) Message() string {
+	paths := make([]string, 0, len(r.Found))
+	for p := range r.Found {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Sensitive data found in %s:\n", git.RefEndName(r.RefName))
+	for _, p := range paths {
+		fmt.Fprintf(&sb, "  %s: %s\n", p, strings.Join(r.Found[p], ", "))
+	}
+	return sb.String()
+}
+
+// CheckPush checks the files of the repo at repoPath that a push of a ref from oldCommitID to newCommitID adds or
+// changes for the sensitive data the rules would scrub: every version of them added by the commits of the push,
+// which are to stay in its history, and those changed at its tip, every file matching the rules if the ref is new.
+// The objects already reachable from the other refs are not checked again. The env must give access to the objects
+// of the push, which are still in quarantine in a pre-receive hook.
+// Files that cannot be parsed are skipped, as they are not for the checks to reject.
+func CheckPush(ctx context.Context, repoPath string, env []string, rules *Rules, refName, oldCommitID, newCommitID string) (*CheckResult, error) {
+	if newCommitID == git.EmptySHA {
+		return &CheckResult{RefName: refName, CommitID: newCommitID, Found: make(map[string][]string)}, nil
+	}
+	var tipArgs []string
+	revListArgs := []string{"rev-list", "--objects", newCommitID, "--not"}
+	if oldCommitID == git.EmptySHA {
+		tipArgs = []string{"ls-tree", "-r", "-z", "--name-only", newCommitID}
+	} else {
+		tipArgs = []string{"diff", "-z", "--name-only", "--no-renames", oldCommitID, newCommitID}
+		revListArgs = append(revListArgs, oldCommitID)
+	}
+	// Not --all, which would also exclude HEAD, the default branch
+	revListArgs = append(revListArgs, "--exclude="+refName, "--glob=refs/*")
+	return check(ctx, repoPath, env, rules, refName, newCommitID, tipArgs, revListArgs)
+}
+
+// CheckRef checks every file of the history of a ref of the repo at repoPath for the sensitive data the rules
+// would scrub, e.g. that pushed before the checks were turned on
+func CheckRef(ctx context.Context, repoPath string, rules *Rules, refName, commitID string) (*CheckResult, error) {
+	return check(ctx, repoPath, nil, rules, refName, commitID,
+		[]string{"ls-tree", "-r", "-z", "--name-only", commitID},
+		[]string{"rev-list", "--objects", commitID})
+}
+
+// check checks the files matching the rules that are listed by the git command of tipArgs at the commit of a ref,
+// and every blob matching them listed by the git rev-list command of revListArgs
+func check(ctx context.Context, repoPath string, env []string, rules *Rules, refName, commitID string, tipArgs, revListArgs []string) (*CheckResult, error) {
+	result := &CheckResult{RefName: refName, CommitID: commitID, Found: make(map[string][]string)}
+
+	// The objects to check, by their path
+	var paths, objects []string
+	stdout := new(bytes.Buffer)
+	stderr := new(strings.Builder)
+	if err := git.NewCommandContext(ctx, tipArgs...).
+		RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, stdout, stderr, nil); err != nil {
+		return nil, fmt.Errorf("%s: %v", tipArgs[0], git.ConcatenateError(err, stderr.String()))
+	}
+	for _, p := range strings.Split(stdout.String(), "\x00") {
+		// Paths with a new line can't be given to cat-file --batch
+		if p != "" && rules.MatchFile(p) && !strings.Contains(p, "\n") {
+			result.Checked = append(result.Checked, p)
+			paths = append(paths, p)
+			objects = append(objects, commitID+":"+p)
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if err := git.NewCommandContext(ctx, revListArgs...).
+		RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, stdout, stderr, nil); err != nil {
+		return nil, fmt.Errorf("rev-list: %v", git.ConcatenateError(err, stderr.String()))
+	}
+	checked := make(map[string]bool, len(result.Checked))
+	for _, p := range result.Checked {
+		checked[p] = true
+	}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		// Commits are listed without a path, trees and blobs with the first path they are found at
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || fields[1] == "" || !rules.MatchFile(fields[1]) {
+			continue
+		}
+		if !checked[fields[1]] {
+			checked[fields[1]] = true
+			result.Checked = append(result.Checked, fields[1])
+		}
+		paths = append(paths, fields[1])
+		objects = append(objects, fields[0])
+	}
+	if len(objects) == 0 {
+		return result, nil
+	}
+
+	input := new(strings.Builder)
+	for _, object := range objects {
+		input.WriteString(object + "\n")
+	}
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	done := make(chan error, 1)
+	go func() {
+		stderr := new(strings.Builder)
+		err := git.NewCommandContext(ctx, "cat-file", "--batch").
+			RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, writer, stderr, strings.NewReader(input.String()))
+		if err != nil {
+			err = git.ConcatenateError(err, stderr.String())
+		}
+		_ = writer.CloseWithError(err)
+		done <- err
+	}()
+
+	batch := bufio.NewReader(reader)
+	for i, p := range paths {
+		content, err := readBatchBlob(batch)
+		if err != nil {
+			_ = reader.CloseWithError(err)
+			<-done
+			return nil, fmt.Errorf("cat-file [path: %s]: %v", p, err)
+		}
+		if content == nil {
+			continue
+		}
+		_, found, err := rules.scrubContent(p, content)
+		if err != nil {
+			log.Debug("Unable to check %s (%s) for sensitive data: %v", p, objects[i], err)
+			continue
+		}
+		// The versions of a file may each have some of its sensitive data
+		for _, f := range found {
+			if !util.IsStringInSlice(f, result.Found[p]) {
+				result.Found[p] = append(result.Found[p], f)
+			}
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("cat-file: %v", err)
+	}
+	return result, nil
+}
+
+// RecordCheckResults records the sensitive data found by the checks of the refs of a repo for the admins' report,
+// forgetting that of the deleted refs
+func RecordCheckResults(repoID int64, results []*CheckResult) error {
+	for _, result := range results {
+		var err error
+		if result.CommitID == git.EmptySHA {
+			err = models.DeleteSensitiveData(repoID, result.RefName)
+		} else {
+			err = models.UpdateSensitiveData(repoID, result.RefName, result.CommitID, result.Checked, result.Found)
+		}
+		if err != nil {
+			return fmt.Errorf("UpdateSensitiveData [ref: %s]: %v", result.RefName, err)
+		}
+	}
+	return nil
+}
+
+// readBatchBlob reads the next object of the output of git cat-file --batch,
+// returning nil if it is missing, not a blob or too large to be checked
+func readBatchBlob(rd *bufio.Reader) ([]byte, error) {
+	header, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(header, " missing\n") {
+		// A deleted file
+		return nil, nil
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid header %q", header)
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header %q: %v", header, err)
+	}
+	var content []byte
+	if fields[1] == "blob" && size <= maxCheckFileSize {
+		content = make([]byte, size)
+		if _, err := io.ReadFull(rd, content); err != nil {
+			return nil, err
+		}
+	} else if _, err := io.CopyN(ioutil.Discard, rd, size); err != nil {
+		return nil, err
+	}
+	// Each object ends with a new line
+	if _, err := rd.Discard(1); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,109 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Tests for checking pushes for sensitive data ***/
+
+package scrubber_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/scrubber"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPush(t *testing.T) {
+	repoDir, err := ioutil.TempDir(os.TempDir(), "check_test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+	assert.NoError(t, git.InitRepository(repoDir, false))
+
+	sig := &git.Signature{Name: "John Smith", Email: "john@smith.com"}
+	commit := func(files map[string]string, removed ...string) string {
+		for name, content := range files {
+			assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDir, name)), os.ModePerm))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, name), []byte(content), 0666))
+		}
+		for _, name := range removed {
+			assert.NoError(t, os.Remove(filepath.Join(repoDir, name)))
+		}
+		assert.NoError(t, git.AddChanges(repoDir, true))
+		assert.NoError(t, git.CommitChanges(repoDir, git.CommitChangesOptions{Committer: sig, Author: sig, Message: "Update"}))
+		stdout, err := git.NewCommand("rev-parse", "HEAD").RunInDir(repoDir)
+		assert.NoError(t, err)
+		return strings.TrimSpace(stdout)
+	}
+
+	rules := &scrubber.Rules{
+		Files:      []string{"manifest.yaml", "**/*.usfm"},
+		Fields:     []string{"dublin_core.contributor"},
+		Redactions: scrubber.GetRedactions([]string{"email"}),
+	}
+	first := commit(map[string]string{
+		"manifest.yaml": "dublin_core:\n  contributor:\n  - John Smith\n",
+		"01-GEN.usfm":   "\\id GEN\n\\rem john@smith.com\n",
+		"README.md":     "john@smith.com",
+		"03-LEV.usfm":   "\\id LEV\n",
+	})
+
+	// A new branch has all its files checked
+	result, err := scrubber.CheckPush(context.Background(), repoDir, nil, rules, "refs/heads/master", git.EmptySHA, first)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"manifest.yaml", "01-GEN.usfm", "03-LEV.usfm"}, result.Checked)
+	assert.Equal(t, map[string][]string{
+		"manifest.yaml": {"dublin_core.contributor"},
+		"01-GEN.usfm":   {"line 2: email"},
+	}, result.Found)
+	assert.Contains(t, result.Message(), "  manifest.yaml: dublin_core.contributor\n")
+
+	// Otherwise only the changed files are, including deleted ones
+	second := commit(map[string]string{"manifest.yaml": "dublin_core:\n  contributor: []\n", "README.md": "jane@smith.com"}, "01-GEN.usfm")
+	result, err = scrubber.CheckPush(context.Background(), repoDir, nil, rules, "refs/heads/master", first, second)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"manifest.yaml", "01-GEN.usfm"}, result.Checked)
+	assert.Empty(t, result.Found)
+
+	// Files that can't be parsed are skipped
+	third := commit(map[string]string{"manifest.yaml": "dublin_core: [", "02-EXO.usfm": "\\rem jane@smith.com\n"})
+	result, err = scrubber.CheckPush(context.Background(), repoDir, nil, rules, "refs/heads/master", second, third)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"02-EXO.usfm": {"line 1: email"}}, result.Found)
+
+	// A file added by a commit of a push stays in its history even if a later commit of the push deletes it
+	commit(map[string]string{"04-NUM.usfm": "\\id NUM\n\\rem jane@smith.com\n"})
+	fifth := commit(nil, "04-NUM.usfm")
+	result, err = scrubber.CheckPush(context.Background(), repoDir, nil, rules, "refs/heads/master", third, fifth)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"04-NUM.usfm"}, result.Checked)
+	assert.Equal(t, map[string][]string{"04-NUM.usfm": {"line 2: email"}}, result.Found)
+
+	// The objects of the other refs were checked when they were pushed
+	_, err = git.NewCommand("branch", "other", fifth).RunInDir(repoDir)
+	assert.NoError(t, err)
+	result, err = scrubber.CheckPush(context.Background(), repoDir, nil, rules, "refs/heads/master", third, fifth)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Found)
+
+	// The whole history of a ref is checked to scan it
+	result, err = scrubber.CheckRef(context.Background(), repoDir, rules, "refs/heads/master", fifth)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"manifest.yaml": {"dublin_core.contributor"},
+		"01-GEN.usfm":   {"line 2: email"},
+		"02-EXO.usfm":   {"line 1: email"},
+		"04-NUM.usfm":   {"line 2: email"},
+	}, result.Found)
+
+	// Deleting a branch has nothing to check
+	result, err = scrubber.CheckPush(context.Background(), repoDir, nil, rules, "refs/heads/master", fifth, git.EmptySHA)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Checked)
+}
@@ -0,0 +1,308 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Format handlers for scrubbing repos ***/
+
+package scrubber
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Handler scrubs the files of a format
+type Handler interface {
+	// Name is the name of the format
+	Name() string
+	// Match returns true if the handler scrubs the file at treePath
+	Match(treePath string) bool
+	// Scrub returns the scrubbed content and what was changed, nothing if the content is unchanged
+	Scrub(rules *Rules, content []byte) ([]byte, []string, error)
+}
+
+// handlers are the registered handlers, in the order they are tried
+var handlers []Handler
+
+// RegisterHandler registers a handler, which is tried before the handlers registered before it
+func RegisterHandler(handler Handler) {
+	handlers = append([]Handler{handler}, handlers...)
+}
+
+// GetHandler returns the handler that scrubs the file at treePath
+func GetHandler(treePath string) Handler {
+	for _, handler := range handlers {
+		if handler.Match(treePath) {
+			return handler
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterHandler(&TextHandler{})
+	RegisterHandler(&MarkdownHandler{})
+	RegisterHandler(&USFMHandler{})
+	RegisterHandler(&YAMLHandler{})
+	RegisterHandler(&JSONHandler{})
+}
+
+// scrubContent scrubs the content of a file with the handler of its format
+func (r *Rules) scrubContent(fileName string, content []byte) ([]byte, []string, error) {
+	handler := GetHandler(fileName)
+	if handler == nil {
+		return content, nil, nil
+	}
+	return handler.Scrub(r, content)
+}
+
+func hasExt(treePath string, exts ...string) bool {
+	ext := strings.ToLower(path.Ext(treePath))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONHandler empties the fields of the rules in JSON files
+type JSONHandler struct{}
+
+// Name implements Handler
+func (h *JSONHandler) Name() string {
+	return "json"
+}
+
+// Match implements Handler
+func (h *JSONHandler) Match(treePath string) bool {
+	return hasExt(treePath, ".json")
+}
+
+// Scrub implements Handler
+func (h *JSONHandler) Scrub(rules *Rules, content []byte) ([]byte, []string, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, nil, err
+	}
+	changed := rules.ScrubMap(m)
+	if len(changed) == 0 {
+		return content, nil, nil
+	}
+	scrubbed, err := json.MarshalIndent(m, "", "  ")
+	return scrubbed, changed, err
+}
+
+// YAMLHandler empties the fields of the rules in YAML files, keeping the order of their keys,
+// and applies the redactions of the rules to their values
+type YAMLHandler struct{}
+
+// Name implements Handler
+func (h *YAMLHandler) Name() string {
+	return "yaml"
+}
+
+// Match implements Handler
+func (h *YAMLHandler) Match(treePath string) bool {
+	return hasExt(treePath, ".yaml", ".yml")
+}
+
+// Scrub implements Handler
+func (h *YAMLHandler) Scrub(rules *Rules, content []byte) ([]byte, []string, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, err
+	}
+	var changed []string
+	rules.scrubYAML(doc, nil, &changed)
+	if len(changed) == 0 {
+		return content, nil, nil
+	}
+	scrubbed, err := yaml.Marshal(doc)
+	return scrubbed, changed, err
+}
+
+func (r *Rules) scrubYAML(value interface{}, path []string, changed *[]string) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for i := range v {
+			fieldPath := append(append([]string{}, path...), fmt.Sprint(v[i].Key))
+			if r.MatchField(fieldPath) {
+				if !isEmptyList(v[i].Value) {
+					*changed = append(*changed, strings.Join(fieldPath, "."))
+				}
+				v[i].Value = []interface{}{}
+				continue
+			}
+			v[i].Value = r.scrubYAML(v[i].Value, fieldPath, changed)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = r.scrubYAML(v[i], path, changed)
+		}
+	case string:
+		redacted, matched := r.Redact(v)
+		for _, name := range matched {
+			*changed = append(*changed, strings.Join(path, ".")+": "+name)
+		}
+		return redacted
+	}
+	return value
+}
+
+var usfmRemarkRegexp = regexp.MustCompile(`^(\s*\\rem\s+)(.*)$`)
+
+// USFMHandler scrubs the \rem remarks of USFM files. The value of a "field: value" remark is removed if the
+// field is one of the rules' field names, in the singular or plural, and the redactions apply to all remarks.
+type USFMHandler struct{}
+
+// Name implements Handler
+func (h *USFMHandler) Name() string {
+	return "usfm"
+}
+
+// Match implements Handler
+func (h *USFMHandler) Match(treePath string) bool {
+	return hasExt(treePath, ".usfm", ".usfm3", ".sfm")
+}
+
+// Scrub implements Handler
+func (h *USFMHandler) Scrub(rules *Rules, content []byte) ([]byte, []string, error) {
+	var changed []string
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		matches := usfmRemarkRegexp.FindStringSubmatch(strings.TrimSuffix(line, "\r"))
+		if matches == nil {
+			continue
+		}
+		prefix, remark := matches[1], matches[2]
+		if colon := strings.Index(remark, ":"); colon > 0 && rules.matchRemarkField(remark[:colon]) {
+			if strings.TrimSpace(remark[colon+1:]) != "" {
+				remark = remark[:colon+1]
+				changed = append(changed, fmt.Sprintf("line %d: %s", i+1, strings.TrimSpace(remark[:colon])))
+			}
+		} else {
+			var matched []string
+			remark, matched = rules.Redact(remark)
+			for _, name := range matched {
+				changed = append(changed, fmt.Sprintf("line %d: %s", i+1, name))
+			}
+		}
+		scrubbed := prefix + remark
+		if strings.HasSuffix(line, "\r") {
+			scrubbed += "\r"
+		}
+		lines[i] = scrubbed
+	}
+	if len(changed) == 0 {
+		return content, nil, nil
+	}
+	return []byte(strings.Join(lines, "\n")), changed, nil
+}
+
+// matchRemarkField returns true if the field of a remark is one of the field names of the rules, in the singular or plural
+func (r *Rules) matchRemarkField(field string) bool {
+	field = strings.ToLower(strings.TrimSpace(field))
+	for _, name := range r.Fields {
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		name = strings.ToLower(name)
+		if field == name || field+"s" == name || field == name+"s" {
+			return true
+		}
+	}
+	return false
+}
+
+var frontMatterDelimiter = []byte("---")
+
+// MarkdownHandler scrubs the YAML front-matter of markdown files like the YAMLHandler, leaving their text as is.
+// A front-matter that is not YAML, such as a --- rule at the start of the text, is left as is too.
+type MarkdownHandler struct{}
+
+// Name implements Handler
+func (h *MarkdownHandler) Name() string {
+	return "markdown"
+}
+
+// Match implements Handler
+func (h *MarkdownHandler) Match(treePath string) bool {
+	return hasExt(treePath, ".md", ".markdown")
+}
+
+// Scrub implements Handler
+func (h *MarkdownHandler) Scrub(rules *Rules, content []byte) ([]byte, []string, error) {
+	frontMatter, body, ok := splitFrontMatter(content)
+	if !ok {
+		return content, nil, nil
+	}
+	scrubbed, changed, err := (&YAMLHandler{}).Scrub(rules, frontMatter)
+	if err != nil {
+		log.Warn("MarkdownHandler: skipping unparseable front-matter: %v", err)
+		return content, nil, nil
+	}
+	if len(changed) == 0 {
+		return content, nil, nil
+	}
+	var buf bytes.Buffer
+	buf.Write(frontMatterDelimiter)
+	buf.WriteByte('\n')
+	buf.Write(scrubbed)
+	buf.Write(frontMatterDelimiter)
+	buf.WriteByte('\n')
+	buf.Write(body)
+	return buf.Bytes(), changed, nil
+}
+
+// splitFrontMatter splits a markdown file that starts with a front-matter block delimited by --- lines
+func splitFrontMatter(content []byte) ([]byte, []byte, bool) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(content, append(frontMatterDelimiter, '\n')) {
+		return nil, nil, false
+	}
+	rest := content[len(frontMatterDelimiter)+1:]
+	end := bytes.Index(rest, append(append([]byte("\n"), frontMatterDelimiter...), '\n'))
+	if end < 0 {
+		if !bytes.HasSuffix(rest, append([]byte("\n"), frontMatterDelimiter...)) {
+			return nil, nil, false
+		}
+		return rest[:len(rest)-len(frontMatterDelimiter)], nil, true
+	}
+	return rest[:end+1], rest[end+len(frontMatterDelimiter)+2:], true
+}
+
+// TextHandler applies the redactions of the rules to any other text file, leaving binary files as they are
+type TextHandler struct{}
+
+// Name implements Handler
+func (h *TextHandler) Name() string {
+	return "text"
+}
+
+// Match implements Handler
+func (h *TextHandler) Match(treePath string) bool {
+	return true
+}
+
+// Scrub implements Handler
+func (h *TextHandler) Scrub(rules *Rules, content []byte) ([]byte, []string, error) {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return content, nil, nil
+	}
+	redacted, matched := rules.Redact(string(content))
+	if len(matched) == 0 {
+		return content, nil, nil
+	}
+	return []byte(redacted), matched, nil
+}
+
+/*** END DCS Customizations ***/
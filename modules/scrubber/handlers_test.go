@@ -0,0 +1,133 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Tests for the format handlers for scrubbing repos ***/
+
+package scrubber_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/scrubber"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRules() *scrubber.Rules {
+	return &scrubber.Rules{
+		Fields:     []string{"translators", "checking_entity", "dublin_core.contributor"},
+		Redactions: scrubber.GetRedactions([]string{"email", "phone"}),
+	}
+}
+
+func TestGetHandler(t *testing.T) {
+	for treePath, name := range map[string]string{
+		"manifest.json":   "json",
+		"manifest.yaml":   "yaml",
+		"media.yml":       "yaml",
+		"01-GEN.usfm":     "usfm",
+		"content/01.md":   "markdown",
+		"LICENSE.txt":     "text",
+		"content/01.JSON": "json",
+	} {
+		assert.Equal(t, name, scrubber.GetHandler(treePath).Name(), treePath)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	rules := testRules()
+
+	redacted, matched := rules.Redact("Contact john.smith@example.org or +1 (555) 555-1234, 555.555.1234")
+	assert.Equal(t, "Contact [email redacted] or [phone redacted], [phone redacted]", redacted)
+	assert.Equal(t, []string{"email", "phone"}, matched)
+
+	// Dates, versions and verse references are left alone
+	for _, text := range []string{"2021-05-01", "2021-05-01T12:30:00Z", "v12.1.3", "GEN 1:1-3", "12345"} {
+		redacted, matched = rules.Redact(text)
+		assert.Equal(t, text, redacted)
+		assert.Empty(t, matched)
+	}
+}
+
+func TestYAMLHandler(t *testing.T) {
+	content := `dublin_core:
+  title: unfoldingWord Literal Text
+  contributor:
+  - John Smith
+  - Jane Doe
+  rights: CC BY-SA 4.0, questions to help@example.org
+  issued: "2021-05-01"
+checking:
+  checking_entity:
+  - Wycliffe Associates
+`
+	scrubbed, changed, err := scrubber.GetHandler("manifest.yaml").Scrub(testRules(), []byte(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dublin_core.contributor", "dublin_core.rights: email"}, changed)
+	assert.Equal(t, `dublin_core:
+  title: unfoldingWord Literal Text
+  contributor: []
+  rights: CC BY-SA 4.0, questions to [email redacted]
+  issued: "2021-05-01"
+checking:
+  checking_entity:
+  - Wycliffe Associates
+`, string(scrubbed))
+
+	// Nothing is changed a second time
+	_, changed, err = scrubber.GetHandler("manifest.yaml").Scrub(testRules(), scrubbed)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	_, _, err = scrubber.GetHandler("manifest.yaml").Scrub(testRules(), []byte("a: [b"))
+	assert.Error(t, err)
+}
+
+func TestUSFMHandler(t *testing.T) {
+	content := "\\id GEN EN_ULT\r\n\\rem Translators: John Smith, Jane Doe\r\n\\rem Reviewed by john@example.org\r\n\\rem Aligned with the UGNT\r\n\\c 1\r\n\\v 1 In the beginning, call 555-555-1234\r\n"
+	scrubbed, changed, err := scrubber.GetHandler("01-GEN.usfm").Scrub(testRules(), []byte(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"line 2: Translators", "line 3: email"}, changed)
+	// Only the remarks are scrubbed, never the text
+	assert.Equal(t, "\\id GEN EN_ULT\r\n\\rem Translators:\r\n\\rem Reviewed by [email redacted]\r\n\\rem Aligned with the UGNT\r\n\\c 1\r\n\\v 1 In the beginning, call 555-555-1234\r\n", string(scrubbed))
+
+	// A singular field name matches too
+	_, changed, err = scrubber.GetHandler("01-GEN.usfm").Scrub(testRules(), []byte("\\rem contributor: Jane\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"line 1: contributor"}, changed)
+}
+
+func TestMarkdownHandler(t *testing.T) {
+	content := "---\ntitle: Story 1\ntranslators:\n- John Smith\n---\n# Story 1\n\nwrite to john@example.org\n"
+	scrubbed, changed, err := scrubber.GetHandler("content/01.md").Scrub(testRules(), []byte(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"translators"}, changed)
+	assert.Equal(t, "---\ntitle: Story 1\ntranslators: []\n---\n# Story 1\n\nwrite to john@example.org\n", string(scrubbed))
+
+	// Without front-matter nothing is changed
+	scrubbed, changed, err = scrubber.GetHandler("content/01.md").Scrub(testRules(), []byte("# Story 1\n\n---\ntranslators: [John]\n---\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Equal(t, "# Story 1\n\n---\ntranslators: [John]\n---\n", string(scrubbed))
+
+	// A front-matter that is not YAML is skipped rather than failing the scrub
+	content = "---\nNot: [yaml\n---\n# Story 1\n"
+	scrubbed, changed, err = scrubber.GetHandler("content/01.md").Scrub(testRules(), []byte(content))
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Equal(t, content, string(scrubbed))
+}
+
+func TestTextHandler(t *testing.T) {
+	scrubbed, changed, err := scrubber.GetHandler("NOTES.txt").Scrub(testRules(), []byte("Call 555 555 1234"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"phone"}, changed)
+	assert.Equal(t, "Call [phone redacted]", string(scrubbed))
+
+	binary := []byte{'j', 0, '@', 'x', '.', 'o', 'r', 'g'}
+	scrubbed, changed, err = scrubber.GetHandler("image.png").Scrub(testRules(), binary)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Equal(t, binary, scrubbed)
+}
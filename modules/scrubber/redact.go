@@ -0,0 +1,67 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Redaction rules for scrubbing repos ***/
+
+package scrubber
+
+import (
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Redaction is a rule that replaces the text matching a pattern
+type Redaction struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// redactions are the built-in redactions that can be enabled with the REDACT setting
+var redactions = map[string]*Redaction{
+	"email": {
+		Name:        "email",
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+		Replacement: "[email redacted]",
+	},
+	"phone": {
+		// Ten digit numbers such as +1 (555) 555-5555 or 555.555.5555, but not dates such as 2021-05-01
+		Name:        "phone",
+		Pattern:     regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)|\b\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`),
+		Replacement: "[phone redacted]",
+	},
+}
+
+// GetRedactions returns the built-in redactions of the given names, ignoring the unknown ones
+func GetRedactions(names []string) []*Redaction {
+	var list []*Redaction
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if redaction, ok := redactions[name]; ok {
+			list = append(list, redaction)
+		} else {
+			log.Warn("Unknown scrubber redaction: %s", name)
+		}
+	}
+	return list
+}
+
+// Redact applies the redactions of the rules to a text, returning the redacted text and the names of the redactions that matched
+func (r *Rules) Redact(text string) (string, []string) {
+	var matched []string
+	for _, redaction := range r.Redactions {
+		if redaction.Pattern.MatchString(text) {
+			text = redaction.Pattern.ReplaceAllLiteralString(text, redaction.Replacement)
+			matched = append(matched, redaction.Name)
+		}
+	}
+	return text, matched
+}
+
+/*** END DCS Customizations ***/
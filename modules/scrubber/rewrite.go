@@ -0,0 +1,248 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - History rewriter for scrubbing repos ***/
+
+package scrubber
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// progressInterval is how many commits are rewritten between updates of the progress of the process
+const progressInterval = 100
+
+// RewriteOptions options for rewriting the history of the branches, tags and pull requests of a repo
+type RewriteOptions struct {
+	// DropPaths are the paths of the files removed from every commit
+	DropPaths []string
+	// Name and Email, if not empty, replace every author, committer and tagger
+	Name  string
+	Email string
+	// Prune expires the reflog and removes the objects that are no longer reachable once rewritten
+	Prune bool
+	// Description describes the rewrite in the process list, defaulting to the repo path
+	Description string
+}
+
+// RewriteHistory rewrites the history of all branches, tags and pull requests of the repo at repoPath by streaming it through
+// git fast-export and git fast-import, dropping files and replacing identities on the way.
+// Commits keep their place in the history even if dropping files leaves them empty.
+// The rewrite is listed as a process that reports its progress and can be cancelled. The refs are only
+// updated once the whole history has been imported, so a cancelled or failed rewrite leaves the repo as it was.
+func RewriteHistory(ctx context.Context, repoPath string, opts RewriteOptions) error {
+	desc := opts.Description
+	if desc == "" {
+		desc = fmt.Sprintf("RewriteHistory [repo_path: %s]", repoPath)
+	}
+	timeout := time.Duration(setting.Git.Timeout.Migrate) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	pid := process.GetManager().Add(desc, cancel)
+	defer process.GetManager().Remove(pid)
+
+	stdout, err := git.NewCommandContext(ctx, "rev-list", "--count", "--branches", "--tags", "--glob=refs/pull/*").RunInDir(repoPath)
+	if err != nil {
+		return fmt.Errorf("rev-list: %v", err)
+	}
+	total, _ := strconv.Atoi(strings.TrimSpace(stdout))
+	if total == 0 {
+		return nil
+	}
+
+	exportReader, exportWriter := io.Pipe()
+	importReader, importWriter := io.Pipe()
+	defer func() {
+		_ = exportReader.Close()
+		_ = importWriter.Close()
+	}()
+
+	// --no-data leaves out the content of the files, which fast-import finds in the same repo
+	exportDone := make(chan error, 1)
+	go func() {
+		stderr := strings.Builder{}
+		err := git.NewCommandContext(ctx, "fast-export", "--no-data", "--signed-tags=strip", "--use-done-feature", "--branches", "--tags", "--glob=refs/pull/*").
+			SetDescription(fmt.Sprintf("%s: fast-export", desc)).
+			RunInDirTimeoutEnvFullPipeline(nil, timeout, repoPath, exportWriter, &stderr, nil)
+		if err != nil {
+			err = git.ConcatenateError(err, stderr.String())
+		}
+		_ = exportWriter.CloseWithError(err)
+		exportDone <- err
+	}()
+	importDone := make(chan error, 1)
+	go func() {
+		stderr := strings.Builder{}
+		err := git.NewCommandContext(ctx, "fast-import", "--force", "--quiet").
+			SetDescription(fmt.Sprintf("%s: fast-import", desc)).
+			RunInDirTimeoutEnvFullPipeline(nil, timeout, repoPath, ioutil.Discard, &stderr, importReader)
+		if err != nil {
+			err = git.ConcatenateError(err, stderr.String())
+		}
+		_ = importReader.CloseWithError(err)
+		importDone <- err
+	}()
+
+	filter := newStreamFilter(opts)
+	filter.onCommit = func(count int) {
+		if count%progressInterval == 0 || count == total {
+			process.GetManager().SetDescription(pid, fmt.Sprintf("%s: %d/%d commits", desc, count, total))
+		}
+	}
+	filterErr := filter.run(exportReader, importWriter)
+	_ = importWriter.CloseWithError(filterErr)
+	_ = exportReader.CloseWithError(filterErr)
+	exportErr := <-exportDone
+	importErr := <-importDone
+
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("rewrite cancelled: %v", ctx.Err())
+	case exportErr != nil:
+		return fmt.Errorf("fast-export: %v", exportErr)
+	case importErr != nil:
+		return fmt.Errorf("fast-import: %v", importErr)
+	case filterErr != nil:
+		return fmt.Errorf("filter: %v", filterErr)
+	}
+
+	// fast-import doesn't touch the index and working tree, so update them to the rewritten HEAD like filter-branch
+	isBare, err := git.NewCommandContext(ctx, "rev-parse", "--is-bare-repository").RunInDir(repoPath)
+	if err != nil {
+		return fmt.Errorf("rev-parse: %v", err)
+	}
+	if strings.TrimSpace(isBare) == "false" {
+		if _, err := git.NewCommandContext(ctx, "read-tree", "-u", "-m", "HEAD").RunInDir(repoPath); err != nil {
+			return fmt.Errorf("read-tree: %v", err)
+		}
+	}
+
+	if opts.Prune {
+		process.GetManager().SetDescription(pid, fmt.Sprintf("%s: pruning", desc))
+		return pruneHistory(ctx, repoPath)
+	}
+	return nil
+}
+
+// pruneHistory expires the reflog of the repo at repoPath and removes the objects that are no longer reachable
+func pruneHistory(ctx context.Context, repoPath string) error {
+	if _, err := git.NewCommandContext(ctx, "reflog", "expire", "--expire=now", "--all").RunInDir(repoPath); err != nil {
+		return fmt.Errorf("reflog expire: %v", err)
+	}
+	timeout := time.Duration(setting.Git.Timeout.Migrate) * time.Second
+	if _, err := git.NewCommandContext(ctx, "gc", "--prune=now").RunInDirTimeout(timeout, repoPath); err != nil {
+		return fmt.Errorf("gc: %v", err)
+	}
+	return nil
+}
+
+// streamFilter filters a git fast-export stream
+type streamFilter struct {
+	dropPaths map[string]bool
+	identity  string // "Name <email>", empty to keep the identities
+	commits   int
+	onCommit  func(count int)
+}
+
+func newStreamFilter(opts RewriteOptions) *streamFilter {
+	filter := &streamFilter{dropPaths: make(map[string]bool, len(opts.DropPaths))}
+	for _, p := range opts.DropPaths {
+		filter.dropPaths[strings.TrimPrefix(p, "/")] = true
+	}
+	if opts.Name != "" || opts.Email != "" {
+		// An identity ends at its > and its line, so neither may contain them
+		clean := strings.NewReplacer("<", "", ">", "", "\n", " ")
+		filter.identity = clean.Replace(opts.Name) + " <" + clean.Replace(opts.Email) + ">"
+	}
+	return filter
+}
+
+// run copies the stream from r to w, dropping the file commands of the dropped paths and replacing identities
+func (f *streamFilter) run(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				break
+			}
+			if err != io.EOF {
+				return err
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(line, "data "):
+			// Messages are copied as is, whatever lines they contain
+			size, err := strconv.ParseInt(strings.TrimSpace(line[len("data "):]), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid data command %q: %v", line, err)
+			}
+			if _, err := writer.WriteString(line); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(writer, reader, size); err != nil {
+				return err
+			}
+			continue
+		case strings.HasPrefix(line, "commit "):
+			f.commits++
+			if f.onCommit != nil {
+				f.onCommit(f.commits)
+			}
+		case strings.HasPrefix(line, "author "), strings.HasPrefix(line, "committer "), strings.HasPrefix(line, "tagger "):
+			line = f.rewriteIdentity(line)
+		case strings.HasPrefix(line, "M "):
+			// M <mode> <dataref> <path>
+			if parts := strings.SplitN(strings.TrimSuffix(line, "\n"), " ", 4); len(parts) == 4 && f.isDropped(parts[3]) {
+				continue
+			}
+		case strings.HasPrefix(line, "D "):
+			if f.isDropped(strings.TrimSuffix(line[len("D "):], "\n")) {
+				continue
+			}
+		}
+		if _, err := writer.WriteString(line); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// rewriteIdentity replaces the name and email of an author, committer or tagger line, keeping its date
+func (f *streamFilter) rewriteIdentity(line string) string {
+	if f.identity == "" {
+		return line
+	}
+	space := strings.IndexByte(line, ' ')
+	end := strings.LastIndexByte(line, '>')
+	if space < 0 || end < space {
+		return line
+	}
+	return line[:space+1] + f.identity + line[end+1:]
+}
+
+// isDropped returns true if the path, quoted by git if it has special characters, is one of the dropped paths
+func (f *streamFilter) isDropped(p string) bool {
+	if strings.HasPrefix(p, `"`) {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+	}
+	return f.dropPaths[p]
+}
+
+/*** END DCS Customizations ***/
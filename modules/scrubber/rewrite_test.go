@@ -0,0 +1,74 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Tests for the history rewriter ***/
+
+package scrubber_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/scrubber"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteHistory(t *testing.T) {
+	repoDir, err := ioutil.TempDir(os.TempDir(), "rewrite_test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+	assert.NoError(t, git.InitRepository(repoDir, false))
+
+	sig := &git.Signature{Name: "John Smith", Email: "john@smith.com"}
+	commit := func(message string, files map[string]string) {
+		for name, content := range files {
+			assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDir, name)), os.ModePerm))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, name), []byte(content), 0666))
+		}
+		assert.NoError(t, git.AddChanges(repoDir, true))
+		assert.NoError(t, git.CommitChanges(repoDir, git.CommitChangesOptions{Committer: sig, Author: sig, Message: message}))
+	}
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoDir)
+		assert.NoError(t, err)
+		return strings.TrimSpace(stdout)
+	}
+
+	run("config", "user.name", sig.Name)
+	run("config", "user.email", sig.Email)
+
+	commit("Initial commit\n\ndata 3\ncommitter not a header", map[string]string{"README.md": "readme", "manifest.json": `{"translators": ["John"]}`})
+	commit("Add a file with a space", map[string]string{"sub dir/project.json": "{}", "content/01.md": "story"})
+	run("tag", "-a", "v1", "-m", "Version 1")
+	run("checkout", "-b", "other")
+	commit("Update on other branch", map[string]string{"manifest.json": `{"translators": ["Jane"]}`})
+	run("checkout", "-")
+
+	assert.NoError(t, scrubber.RewriteHistory(context.Background(), repoDir, scrubber.RewriteOptions{
+		DropPaths: []string{"manifest.json", "sub dir/project.json"},
+		Name:      "Door43",
+		Email:     "commit@door43.org",
+	}))
+
+	// The files are gone from every commit of every ref, and the working tree
+	assert.Empty(t, run("log", "--all", "--format=%H", "--", "manifest.json", "sub dir/project.json"))
+	assert.Equal(t, "README.md\ncontent/01.md", run("ls-tree", "-r", "--name-only", "v1"))
+	_, err = os.Stat(filepath.Join(repoDir, "manifest.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Every identity is replaced while the messages are kept as they were
+	assert.Equal(t, "Door43 <commit@door43.org>", run("log", "--all", "--format=%an <%ae>", "--author=Door43", "-1"))
+	assert.Empty(t, run("log", "--all", "--format=%H", "--author=John"))
+	assert.Empty(t, run("log", "--all", "--format=%H", "--committer=John"))
+	assert.Equal(t, "Initial commit\n\ndata 3\ncommitter not a header", run("log", "--format=%B", "--reverse", "-1", "master~1"))
+	assert.Equal(t, "3", run("rev-list", "--count", "--branches", "--tags"))
+	assert.Equal(t, "Door43 <commit@door43.org>", run("for-each-ref", "--format=%(taggername) %(taggeremail)", "refs/tags/v1"))
+	assert.Equal(t, "Version 1", run("for-each-ref", "--format=%(contents:subject)", "refs/tags/v1"))
+}
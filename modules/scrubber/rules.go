@@ -0,0 +1,146 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Rules for scrubbing repos ***/
+
+package scrubber
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// anyDepthPrefix prefixes the fields that match at any depth
+const anyDepthPrefix = "**."
+
+// Rules are the rules for scrubbing sensitive data from a repo
+type Rules struct {
+	// Files are globs of the files to scrub, matched against the path from the root of the repo.
+	// A glob prefixed with **/ matches in any directory. Each file is scrubbed by the handler of its format.
+	Files []string
+	// Fields are emptied in the scrubbed files. A name or a dotted path such as dublin_core.contributor matches
+	// from the top of the file, and one prefixed with **. such as **.translators at any depth.
+	Fields []string
+	// CommitterName and CommitterEmail replace the author and committer of every commit
+	CommitterName  string
+	CommitterEmail string
+	// Redactions replace the text they match in the values of YAML files, USFM remarks, markdown front-matter and text files
+	Redactions []*Redaction
+}
+
+// DefaultRules returns the instance's rules from the [dcs.scrubber] config section
+func DefaultRules() *Rules {
+	return &Rules{
+		Files:          append([]string{}, setting.DCS.Scrubber.Files...),
+		Fields:         append([]string{}, setting.DCS.Scrubber.Fields...),
+		CommitterName:  setting.DCS.Scrubber.CommitterName,
+		CommitterEmail: setting.DCS.Scrubber.CommitterEmail,
+		Redactions:     GetRedactions(setting.DCS.Scrubber.Redact),
+	}
+}
+
+// GetRules returns the rules for scrubbing a repo, the instance's rules overridden by those of the repo's organization
+func GetRules(repo *models.Repository) (*Rules, error) {
+	rules := DefaultRules()
+	if err := repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return rules, nil
+	}
+	orgRules, err := models.GetScrubRulesByOrgID(repo.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orgRules.Files) > 0 {
+		rules.Files = orgRules.Files
+	}
+	if len(orgRules.Fields) > 0 {
+		rules.Fields = orgRules.Fields
+	}
+	if orgRules.CommitterName != "" {
+		rules.CommitterName = orgRules.CommitterName
+	}
+	if orgRules.CommitterEmail != "" {
+		rules.CommitterEmail = orgRules.CommitterEmail
+	}
+	return rules, nil
+}
+
+// MatchFile returns true if the file at treePath is to be scrubbed
+func (r *Rules) MatchFile(treePath string) bool {
+	treePath = strings.TrimPrefix(treePath, "/")
+	for _, glob := range r.Files {
+		if strings.HasPrefix(glob, "**/") {
+			// Match the glob against every trailing part of the path
+			glob = strings.TrimPrefix(glob, "**/")
+			parts := strings.Split(treePath, "/")
+			for i := range parts {
+				if matched, _ := path.Match(glob, strings.Join(parts[i:], "/")); matched {
+					return true
+				}
+			}
+		} else if matched, _ := path.Match(strings.TrimPrefix(glob, "/"), treePath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ScrubMap empties the fields of the rules in a map, returning the dotted paths of the fields that were changed
+func (r *Rules) ScrubMap(m map[string]interface{}) []string {
+	changed := r.scrubMap(m, nil)
+	sort.Strings(changed)
+	return changed
+}
+
+func (r *Rules) scrubMap(m map[string]interface{}, path []string) []string {
+	var changed []string
+	for k, v := range m {
+		fieldPath := append(append([]string{}, path...), k)
+		if r.MatchField(fieldPath) {
+			if !isEmptyList(v) {
+				changed = append(changed, strings.Join(fieldPath, "."))
+			}
+			m[k] = []string{}
+		} else if vm, ok := v.(map[string]interface{}); ok {
+			changed = append(changed, r.scrubMap(vm, fieldPath)...)
+		}
+	}
+	return changed
+}
+
+// MatchField returns true if the field at the given path from the top of a file is to be emptied
+func (r *Rules) MatchField(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	dotted := strings.Join(path, ".")
+	for _, field := range r.Fields {
+		if strings.HasPrefix(field, anyDepthPrefix) {
+			// Match the field against every trailing part of the path
+			field = strings.TrimPrefix(field, anyDepthPrefix)
+			for i := range path {
+				if field == strings.Join(path[i:], ".") {
+					return true
+				}
+			}
+		} else if field == dotted {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyList(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	return (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Len() == 0
+}
+
+/*** END DCS Customizations ***/
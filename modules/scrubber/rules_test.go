@@ -0,0 +1,65 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Tests for the rules for scrubbing repos ***/
+
+package scrubber_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/scrubber"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesMatchFile(t *testing.T) {
+	rules := &scrubber.Rules{Files: []string{"manifest.json", "**/project.json", "content/*.yaml"}}
+
+	assert.True(t, rules.MatchFile("manifest.json"))
+	assert.False(t, rules.MatchFile("sub/manifest.json"))
+	assert.True(t, rules.MatchFile("project.json"))
+	assert.True(t, rules.MatchFile("a/b/project.json"))
+	assert.True(t, rules.MatchFile("content/front.yaml"))
+	assert.False(t, rules.MatchFile("content/sub/front.yaml"))
+	assert.False(t, rules.MatchFile("README.md"))
+}
+
+func TestRulesMatchField(t *testing.T) {
+	rules := &scrubber.Rules{Fields: []string{"translators", "dublin_core.contributor", "**.checkers"}}
+
+	assert.True(t, rules.MatchField([]string{"translators"}))
+	assert.False(t, rules.MatchField([]string{"project", "translators"}))
+	assert.True(t, rules.MatchField([]string{"dublin_core", "contributor"}))
+	assert.False(t, rules.MatchField([]string{"contributor"}))
+	assert.False(t, rules.MatchField([]string{"project", "dublin_core", "contributor"}))
+	assert.True(t, rules.MatchField([]string{"checkers"}))
+	assert.True(t, rules.MatchField([]string{"project", "checkers"}))
+	assert.False(t, rules.MatchField(nil))
+}
+
+func TestRulesScrubMap(t *testing.T) {
+	rules := &scrubber.Rules{Fields: []string{"translators", "dublin_core.contributor"}}
+	m := map[string]interface{}{
+		"translators": []interface{}{"John Smith"},
+		"dublin_core": map[string]interface{}{
+			"contributor": []interface{}{"Jane Doe"},
+			"creator":     "Door43",
+		},
+		"project": map[string]interface{}{
+			"translators": []interface{}{"Not scrubbed"},
+			"contributor": []interface{}{"Not scrubbed"},
+		},
+	}
+
+	assert.Equal(t, []string{"dublin_core.contributor", "translators"}, rules.ScrubMap(m))
+	assert.Equal(t, []string{}, m["translators"])
+	assert.Equal(t, []string{}, m["dublin_core"].(map[string]interface{})["contributor"])
+	assert.Equal(t, "Door43", m["dublin_core"].(map[string]interface{})["creator"])
+	assert.Equal(t, []interface{}{"Not scrubbed"}, m["project"].(map[string]interface{})["translators"])
+	assert.Equal(t, []interface{}{"Not scrubbed"}, m["project"].(map[string]interface{})["contributor"])
+
+	// Nothing changes the second time
+	assert.Empty(t, rules.ScrubMap(m))
+}
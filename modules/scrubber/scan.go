@@ -0,0 +1,111 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Scanning repos for sensitive data ***/
+
+package scrubber
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
+)
+
+// ScanSensitiveData checks every file of the history of every branch and tag of all repos whose pushes are checked
+// for the sensitive data their scrub rules would scrub, replacing what was recorded for the admins' report.
+// It finds what was pushed before the checks were turned on.
+func ScanSensitiveData(ctx context.Context) error {
+	log.Trace("Doing: ScanSensitiveData")
+
+	if err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before scanning %s for sensitive data", repo.FullName())
+			default:
+			}
+			if repo.IsEmpty || repo.GetSensitiveDataCheck() == models.OffSensitiveDataCheck {
+				return nil
+			}
+			if err := ScanRepoSensitiveData(ctx, repo); err != nil {
+				// One broken repo should not keep the others from being scanned
+				log.Error("Unable to scan %-v for sensitive data: %v", repo, err)
+			}
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	log.Trace("Finished: ScanSensitiveData")
+	return nil
+}
+
+// ScanRepoSensitiveData checks every file of the history of every branch and tag of a repo for the sensitive data
+// its scrub rules would scrub, replacing what was recorded for the admins' report
+func ScanRepoSensitiveData(ctx context.Context, repo *models.Repository) error {
+	rules, err := GetRules(repo)
+	if err != nil {
+		return fmt.Errorf("GetRules: %v", err)
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	branches, _, err := gitRepo.GetBranches(0, 0)
+	if err != nil {
+		return fmt.Errorf("GetBranches: %v", err)
+	}
+	tags, err := gitRepo.GetTags()
+	if err != nil {
+		return fmt.Errorf("GetTags: %v", err)
+	}
+
+	results := make([]*CheckResult, 0, len(branches)+len(tags))
+	checkRef := func(refName, commitID string) error {
+		result, err := CheckRef(ctx, repo.RepoPath(), rules, refName, commitID)
+		if err != nil {
+			return fmt.Errorf("CheckRef [ref: %s]: %v", refName, err)
+		}
+		results = append(results, result)
+		return nil
+	}
+	for _, branch := range branches {
+		commitID, err := gitRepo.GetBranchCommitID(branch)
+		if err != nil {
+			return fmt.Errorf("GetBranchCommitID [branch: %s]: %v", branch, err)
+		}
+		if err := checkRef(git.BranchPrefix+branch, commitID); err != nil {
+			return err
+		}
+	}
+	for _, tag := range tags {
+		commitID, err := gitRepo.GetTagCommitID(tag)
+		if err != nil {
+			return fmt.Errorf("GetTagCommitID [tag: %s]: %v", tag, err)
+		}
+		if err := checkRef(git.TagPrefix+tag, commitID); err != nil {
+			return err
+		}
+	}
+
+	// The records of refs that no longer exist go too
+	if err := models.DeleteSensitiveDataByRepoID(repo.ID); err != nil {
+		return fmt.Errorf("DeleteSensitiveDataByRepoID: %v", err)
+	}
+	return RecordCheckResults(repo.ID, results)
+}
+
+/*** END DCS Customizations ***/
@@ -7,45 +7,240 @@
 package scrubber
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path"
-	"reflect"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
 	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/util"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
-var jsonFilesToScrub = [...]string{
-	"project.json",
-	"package.json",
-	"manifest.json",
-	"status.json",
+// ScrubSensitiveDataOptions options for scrubbing sensitive data
+type ScrubSensitiveDataOptions struct {
+	CommitMessage string
+	DryRun        bool
 }
 
-var jsonFieldsToScrub = [...]string{
-	"translators",
-	"contributors",
-	"checking_entity",
+// Report is what a scrub of a repo changes, or would change if it is a dry run
+type Report struct {
+	Rules *Rules
+	Refs  []*RefReport
+	// DroppedFiles are the paths of the files matching the rules anywhere in the history of the branches, tags and
+	// pull requests. They are all dropped from every commit, so sensitive data that was cleaned at every tip goes too.
+	DroppedFiles []string
 }
 
-// ScrubSensitiveDataOptions options for scrubbing sensitive data
-type ScrubSensitiveDataOptions struct {
-	LastCommitID  string
-	CommitMessage string
+// RefReport is what a scrub changes in a branch or tag. Every ref is rewritten
+// to replace the authors and committers of its commits, even if none of its files are scrubbed.
+type RefReport struct {
+	RefName  string // full name, e.g. refs/heads/master
+	CommitID string
+	Files    []*FileReport
+	// cleanPaths are the files matching the rules that have nothing to scrub in this ref. They are dropped
+	// from the history with the others, so they are committed back to this ref.
+	cleanPaths []string
+}
+
+// FileReport is a file that is scrubbed in a ref and the fields of it that are emptied
+type FileReport struct {
+	Path    string
+	Fields  []string
+	content []byte
+}
+
+// Name returns the short name of the branch or tag
+func (r *RefReport) Name() string {
+	return git.RefEndName(r.RefName)
+}
+
+// IsTag returns true if the ref is a tag
+func (r *RefReport) IsTag() bool {
+	return strings.HasPrefix(r.RefName, git.TagPrefix)
+}
+
+// Files returns the distinct paths of the files scrubbed in all refs
+func (r *Report) Files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, ref := range r.Refs {
+		for _, file := range ref.Files {
+			if !seen[file.Path] {
+				seen[file.Path] = true
+				files = append(files, file.Path)
+			}
+		}
+	}
+	return files
+}
+
+// ScrubbedFiles returns ref:path of every file scrubbed in every ref
+func (r *Report) ScrubbedFiles() []string {
+	var files []string
+	for _, ref := range r.Refs {
+		for _, file := range ref.Files {
+			files = append(files, ref.Name()+":"+file.Path)
+		}
+	}
+	return files
+}
+
+// readBlob reads the whole content of a blob
+func readBlob(blob *git.Blob) ([]byte, error) {
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer dataRc.Close()
+	return ioutil.ReadAll(dataRc)
+}
+
+// FindSensitiveData reports the files in every branch and tag of a repo that the rules would scrub.
+// The default branch is always the first ref of the report.
+func FindSensitiveData(repo *models.Repository, rules *Rules) (*Report, error) {
+	return FindSensitiveDataInPath(repo.RepoPath(), repo.DefaultBranch, rules)
+}
+
+// FindSensitiveDataInPath reports the files in every branch and tag of the git repo at repoPath that the rules would scrub,
+// and the files matching the rules in their history that would be dropped from it
+func FindSensitiveDataInPath(repoPath, defaultBranch string, rules *Rules) (*Report, error) {
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	branches, _, err := gitRepo.GetBranches(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("GetBranches: %v", err)
+	}
+	tags, err := gitRepo.GetTags()
+	if err != nil {
+		return nil, fmt.Errorf("GetTags: %v", err)
+	}
+	refNames := make([]string, 0, len(branches)+len(tags))
+	for _, branch := range branches {
+		if branch == defaultBranch {
+			refNames = append([]string{git.BranchPrefix + branch}, refNames...)
+		} else {
+			refNames = append(refNames, git.BranchPrefix+branch)
+		}
+	}
+	for _, tag := range tags {
+		refNames = append(refNames, git.TagPrefix+tag)
+	}
+
+	report := &Report{Rules: rules}
+	// The same blob is usually in many refs, so each is only scrubbed once
+	scrubbedBlobs := make(map[string]*FileReport)
+	for _, refName := range refNames {
+		var commit *git.Commit
+		if strings.HasPrefix(refName, git.TagPrefix) {
+			commit, err = gitRepo.GetTagCommit(git.RefEndName(refName))
+		} else {
+			commit, err = gitRepo.GetBranchCommit(git.RefEndName(refName))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("GetCommit [ref: %s]: %v", refName, err)
+		}
+		refReport := &RefReport{
+			RefName:  refName,
+			CommitID: commit.ID.String(),
+		}
+		report.Refs = append(report.Refs, refReport)
+
+		entries, err := commit.Tree.ListEntriesRecursive()
+		if err != nil {
+			return nil, fmt.Errorf("ListEntriesRecursive [ref: %s]: %v", refName, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsRegular() || !rules.MatchFile(entry.Name()) {
+				continue
+			}
+			key := entry.ID.String() + ":" + entry.Name()
+			fileReport, ok := scrubbedBlobs[key]
+			if !ok {
+				content, err := readBlob(entry.Blob())
+				if err != nil {
+					return nil, fmt.Errorf("readBlob [ref: %s, path: %s]: %v", refName, entry.Name(), err)
+				}
+				scrubbed, fields, err := rules.scrubContent(entry.Name(), content)
+				if err != nil {
+					return nil, fmt.Errorf("unable to scrub %s in %s: %v", entry.Name(), git.RefEndName(refName), err)
+				}
+				if len(fields) > 0 {
+					fileReport = &FileReport{Path: entry.Name(), Fields: fields, content: scrubbed}
+				}
+				scrubbedBlobs[key] = fileReport
+			}
+			if fileReport != nil {
+				refReport.Files = append(refReport.Files, fileReport)
+			} else {
+				refReport.cleanPaths = append(refReport.cleanPaths, entry.Name())
+			}
+		}
+	}
+
+	report.DroppedFiles, err = findHistoryFiles(repoPath, rules)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// findHistoryFiles returns the sorted paths of the files matching the rules in any commit of the branches, tags
+// and pull requests of the git repo at repoPath
+func findHistoryFiles(repoPath string, rules *Rules) ([]string, error) {
+	stdout, err := git.NewCommand("rev-list", "--objects", "--branches", "--tags", "--glob=refs/pull/*").RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("rev-list: %v", err)
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(stdout, "\n") {
+		// <sha> <path> of every tree and blob, the same path being listed once for each of its versions
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || fields[1] == "" || seen[fields[1]] || !rules.MatchFile(fields[1]) {
+			continue
+		}
+		seen[fields[1]] = true
+		files = append(files, fields[1])
+	}
+	sort.Strings(files)
+	return files, nil
 }
 
-// ScrubSensitiveData removes names and email addresses from the manifest|project|package|status.json files and scrubs previous history.
-func ScrubSensitiveData(repo *models.Repository, doer *models.User, opts ScrubSensitiveDataOptions) error {
+// ScrubSensitiveData removes names and email addresses from the files matching the repo's rules in every branch and tag,
+// drops them from the history of the branches, tags and pull requests, replaces the authors and committers of all commits
+// and prunes what is no longer reachable. The scrub is recorded in the audit log.
+// With DryRun nothing is changed and only the report of what would be changed is returned.
+func ScrubSensitiveData(repo *models.Repository, doer *models.User, opts ScrubSensitiveDataOptions) (*Report, error) {
+	rules, err := GetRules(repo)
+	if err != nil {
+		return nil, fmt.Errorf("GetRules: %v", err)
+	}
+	report, err := FindSensitiveData(repo, rules)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(report.Refs) == 0 {
+		return report, nil
+	}
+
 	localPath, err := models.CreateTemporaryPath("repo-scrubber")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err := models.RemoveTemporaryPath(localPath); err != nil {
@@ -55,148 +250,300 @@ func ScrubSensitiveData(repo *models.Repository, doer *models.User, opts ScrubSe
 
 	if err := git.Clone(repo.RepoPath(), localPath, git.CloneRepoOptions{}); err != nil {
 		log.Error("Failed to clone repository: %s (%v)", repo.FullName(), err)
-		return fmt.Errorf("failed to clone repository: %s (%v)", repo.FullName(), err)
+		return nil, fmt.Errorf("failed to clone repository: %s (%v)", repo.FullName(), err)
+	}
+	// Every branch, tag and pull request is rewritten, not only the branch checked out by the clone
+	if _, err := git.NewCommand("fetch", "--update-head-ok", "origin", "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*").RunInDir(localPath); err != nil {
+		return nil, fmt.Errorf("Fetch: %v", err)
 	}
 
-	if err := ScrubJSONFiles(localPath); err == nil {
-		if err := git.AddChanges(localPath, true); err != nil {
-			return fmt.Errorf("AddChanges: %v", err)
-		} else if err := git.CommitChanges(localPath, git.CommitChangesOptions{
-			Committer: doer.NewGitSig(),
-			Message:   opts.CommitMessage,
-		}); err != nil {
-			return fmt.Errorf("CommitChanges: %v", err)
-		} else if err := git.Push(localPath, git.PushOptions{
+	// The scrub can be cancelled from the process list until it is pushed
+	ctx, cancel := context.WithCancel(graceful.GetManager().ShutdownContext())
+	defer cancel()
+	desc := fmt.Sprintf("ScrubSensitiveData [repo: %s]", repo.FullName())
+	pid := process.GetManager().Add(desc, cancel)
+	defer process.GetManager().Remove(pid)
+
+	if err := ScrubLocalRepo(ctx, localPath, report, opts.CommitMessage, desc); err != nil {
+		return nil, err
+	}
+
+	env := models.InternalPushingEnvironment(doer, repo)
+	for _, refspec := range []string{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"} {
+		if err := git.Push(localPath, git.PushOptions{
 			Remote: "origin",
-			Branch: "master",
+			Branch: refspec,
 			Force:  true,
+			Env:    env,
 		}); err != nil {
-			return fmt.Errorf("PushForce: %v", err)
-		}
-		gitRepo, err := git.OpenRepository(repo.RepoPath())
-		if err != nil {
-			return fmt.Errorf("OpenRepository: %v", err)
+			return nil, fmt.Errorf("PushForce [%s]: %v", refspec, err)
 		}
-		commit, err := gitRepo.GetBranchCommit("master")
-		if err != nil {
-			return fmt.Errorf("GetBranchCommit [branch: %s]: %v", "master", err)
-		}
-		oldCommitID := opts.LastCommitID
-		if err := repo_service.PushUpdate(
-			&repo_module.PushUpdateOptions{
-				PusherID:     doer.ID,
-				PusherName:   doer.Name,
-				RepoUserName: repo.MustOwner().Name,
-				RepoName:     repo.Name,
-				RefFullName:  git.BranchPrefix + "master",
-				OldCommitID:  oldCommitID,
-				NewCommitID:  commit.ID.String(),
-			}); err != nil {
-			return fmt.Errorf("PushCommits: %v", err)
-		}
-	} else {
-		return err
+	}
+	// The refs of the pull requests can't be pushed, so the repo fetches them instead
+	if _, err := git.NewCommand("fetch", "--force", "--no-tags", localPath, "+refs/pull/*:refs/pull/*").RunInDir(repo.RepoPath()); err != nil {
+		return nil, fmt.Errorf("Fetch [refs/pull]: %v", err)
+	}
+	// The commits from before the rewrite still hold the sensitive data until they are removed
+	process.GetManager().SetDescription(pid, desc+": pruning")
+	if err := pruneHistory(ctx, repo.RepoPath()); err != nil {
+		return nil, fmt.Errorf("pruneHistory: %v", err)
+	}
+
+	if err := pushUpdates(repo, doer, report); err != nil {
+		return nil, err
+	}
+
+	refNames := make([]string, len(report.Refs))
+	for i, ref := range report.Refs {
+		refNames[i] = ref.RefName
+	}
+	if err := models.InsertScrubLog(&models.ScrubLog{
+		RepoID:         repo.ID,
+		DoerID:         doer.ID,
+		Refs:           refNames,
+		Files:          report.ScrubbedFiles(),
+		CommitterName:  rules.CommitterName,
+		CommitterEmail: rules.CommitterEmail,
+	}); err != nil {
+		return nil, fmt.Errorf("InsertScrubLog: %v", err)
+	}
+	// What the checks of pushes found has now been scrubbed
+	if err := models.DeleteSensitiveDataByRepoID(repo.ID); err != nil {
+		return nil, fmt.Errorf("DeleteSensitiveDataByRepoID: %v", err)
 	}
 
-	return ScrubCommitNameAndEmail(localPath, "Door43", "commit@door43.org")
+	return report, nil
 }
 
-// ScrubJSONFiles will scrub all JSON files
-func ScrubJSONFiles(localPath string) error {
-	for _, fileName := range jsonFilesToScrub {
-		if err := scrubJSONFile(localPath, fileName); err != nil {
+// ScrubLocalRepo scrubs a clone at localPath that has every branch, tag and pull request of the repo of the report.
+// The dropped files of the report and the files scrubbed in any ref are dropped from the whole history, then every
+// branch and tag that had one of them gets a commit of its scrubbed files and of its clean copies of the dropped files.
+func ScrubLocalRepo(ctx context.Context, localPath string, report *Report, message, desc string) error {
+	// The commits of the scrubbed files are made by the replacement identity, as are all rewritten commits
+	dropPaths := append([]string{}, report.DroppedFiles...)
+	for _, p := range report.Files() {
+		if !util.IsStringInSlice(p, dropPaths) {
+			dropPaths = append(dropPaths, p)
+		}
+	}
+	if err := RewriteHistory(ctx, localPath, RewriteOptions{
+		DropPaths:   dropPaths,
+		Name:        report.Rules.CommitterName,
+		Email:       report.Rules.CommitterEmail,
+		Description: desc + ": rewrite history",
+	}); err != nil {
+		return fmt.Errorf("RewriteHistory: %v", err)
+	}
+	dropped := make(map[string]bool, len(dropPaths))
+	for _, p := range dropPaths {
+		dropped[p] = true
+	}
+	sig := &git.Signature{
+		Name:  report.Rules.CommitterName,
+		Email: report.Rules.CommitterEmail,
+		When:  time.Now(),
+	}
+	for _, ref := range report.Refs {
+		var restorePaths []string
+		for _, p := range ref.cleanPaths {
+			if dropped[p] {
+				restorePaths = append(restorePaths, p)
+			}
+		}
+		if len(ref.Files) == 0 && len(restorePaths) == 0 {
+			continue
+		}
+		if err := commitScrubbedFiles(localPath, sig, ref, restorePaths, message); err != nil {
 			return err
 		}
 	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("scrub cancelled: %v", ctx.Err())
+	}
 	return nil
 }
 
-func scrubJSONFile(localPath, fileName string) error {
-	jsonPath := path.Join(localPath, fileName)
-
-	var jsonData interface{}
-	if _, err := os.Stat(jsonPath); os.IsNotExist(err) {
-		return nil // path does not exist, nothing to scrub!
-	} else if fileContent, err := ioutil.ReadFile(jsonPath); err != nil {
-		log.Error("%v", err)
-		return err // error reading file
-	} else if err = json.Unmarshal(fileContent, &jsonData); err != nil {
-		log.Error("%v", err)
-		return err // error unmarhalling file
+// commitScrubbedFiles writes the scrubbed files of a ref back to it in a new commit with the files at restorePaths
+// as they were before the rewrite, moving a tag to the new commit
+func commitScrubbedFiles(localPath string, sig *git.Signature, ref *RefReport, restorePaths []string, message string) error {
+	// A tag is checked out by its full ref name, detaching HEAD, and a branch by its name so the commit advances it
+	target := ref.Name()
+	if ref.IsTag() {
+		target = ref.RefName
 	}
-
-	m := jsonData.(map[string]interface{})
-	ScrubMap(m)
-
-	if fileContent, err := json.MarshalIndent(m, "", "  "); err != nil {
-		return err
-	} else if err := ScrubFile(localPath, fileName); err != nil {
-		return err
-	} else if err := ioutil.WriteFile(jsonPath, []byte(fileContent), 0666); err != nil {
-		return err
+	if _, err := git.NewCommand("checkout", "-f", target).RunInDir(localPath); err != nil {
+		return fmt.Errorf("Checkout [%s]: %v", ref.RefName, err)
+	}
+	for _, file := range ref.Files {
+		filePath := filepath.Join(localPath, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filePath, file.content, 0666); err != nil {
+			return err
+		}
+	}
+	if len(restorePaths) > 0 {
+		// The commits from before the rewrite are still in the clone
+		args := append([]string{"checkout", ref.CommitID, "--"}, restorePaths...)
+		if _, err := git.NewCommand(args...).RunInDir(localPath); err != nil {
+			return fmt.Errorf("Checkout [%s]: %v", ref.CommitID, err)
+		}
+	}
+	if err := git.AddChanges(localPath, true); err != nil {
+		return fmt.Errorf("AddChanges: %v", err)
+	}
+	if err := git.CommitChanges(localPath, git.CommitChangesOptions{
+		Committer: sig,
+		Author:    sig,
+		Message:   message,
+	}); err != nil {
+		return fmt.Errorf("CommitChanges [%s]: %v", ref.RefName, err)
+	}
+	if ref.IsTag() {
+		if err := moveTag(localPath, sig, ref.Name()); err != nil {
+			return err
+		}
 	}
-
 	return nil
 }
 
-// ScrubMap will scrub a map
-func ScrubMap(m map[string]interface{}) {
-	for _, field := range jsonFieldsToScrub {
-		if _, ok := m[field]; ok {
-			m[field] = []string{}
-		}
+// moveTag moves a tag to HEAD. An annotated tag is recreated with its message, tagged by sig.
+func moveTag(localPath string, sig *git.Signature, name string) error {
+	objectType, err := git.NewCommand("cat-file", "-t", git.TagPrefix+name).RunInDir(localPath)
+	if err != nil {
+		return fmt.Errorf("CatFile [%s]: %v", name, err)
 	}
-	for _, v := range m {
-		if reflect.ValueOf(v).Kind() == reflect.Map {
-			vm := v.(map[string]interface{})
-			ScrubMap(vm)
+	if strings.TrimSpace(objectType) != "tag" {
+		if _, err := git.NewCommand("tag", "-f", name, "HEAD").RunInDir(localPath); err != nil {
+			return fmt.Errorf("Tag [%s]: %v", name, err)
 		}
+		return nil
 	}
+	tagMessage, err := git.NewCommand("for-each-ref", "--format=%(contents)", git.TagPrefix+name).RunInDir(localPath)
+	if err != nil {
+		return fmt.Errorf("ForEachRef [%s]: %v", name, err)
+	}
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email,
+		"GIT_COMMITTER_DATE="+sig.When.Format(time.RFC3339),
+	)
+	if _, err := git.NewCommand("tag", "-f", "-a", "-m", tagMessage, name, "HEAD").RunInDirWithEnv(localPath, env); err != nil {
+		return fmt.Errorf("Tag [%s]: %v", name, err)
+	}
+	return nil
 }
 
-// ScrubFile completely removes a file from a repository's history
-func ScrubFile(repoPath string, fileName string) error {
-	gitPath, err := exec.LookPath("git")
+// pushUpdates runs the push updates of every rewritten ref, as the hooks are skipped by the internal push
+func pushUpdates(repo *models.Repository, doer *models.User, report *Report) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
 	if err != nil {
-		return err
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	updates := make([]*repo_module.PushUpdateOptions, 0, len(report.Refs))
+	for _, ref := range report.Refs {
+		var newCommitID string
+		if ref.IsTag() {
+			newCommitID, err = gitRepo.GetTagCommitID(ref.Name())
+		} else {
+			newCommitID, err = gitRepo.GetBranchCommitID(ref.Name())
+		}
+		if err != nil {
+			return fmt.Errorf("GetCommitID [ref: %s]: %v", ref.RefName, err)
+		}
+		if newCommitID == ref.CommitID {
+			continue
+		}
+		updates = append(updates, &repo_module.PushUpdateOptions{
+			PusherID:     doer.ID,
+			PusherName:   doer.Name,
+			RepoUserName: repo.OwnerName,
+			RepoName:     repo.Name,
+			RefFullName:  ref.RefName,
+			OldCommitID:  ref.CommitID,
+			NewCommitID:  newCommitID,
+		})
 	}
-	cmd := git.NewCommand("filter-branch", "--force", "--prune-empty", "--tag-name-filter", "cat",
-		"--index-filter", "\""+gitPath+"\" rm --cached --ignore-unmatch "+fileName,
-		"--", "--all")
-	_, err = cmd.RunInDir(repoPath)
-	if err != nil && err.Error() == "exit status 1" {
-		err := os.RemoveAll(path.Join(repoPath, ".git/refs/original/"))
+	if err := repo_service.PushUpdates(updates); err != nil {
+		return fmt.Errorf("PushUpdates: %v", err)
+	}
+	return nil
+}
+
+// ScrubJSONFiles will scrub all files matching the instance's rules in the working tree of localPath
+// with the handlers of their formats, removing the files that are changed from the history
+func ScrubJSONFiles(localPath string) error {
+	rules := DefaultRules()
+	return filepath.Walk(localPath, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		cmd = git.NewCommand("reflog", "expire", "--all")
-		_, err = cmd.RunInDir(repoPath)
-		if err != nil && err.Error() == "exit status 1" {
-			cmd = git.NewCommand("gc", "--aggressive", "--prune")
-			_, err = cmd.RunInDir(repoPath)
+		relPath, err := filepath.Rel(localPath, filePath)
+		if err != nil {
 			return err
 		}
-	}
-	return err
+		relPath = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			if relPath == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !rules.MatchFile(relPath) {
+			return nil
+		}
+		return rules.scrubFile(localPath, relPath)
+	})
 }
 
-// ScrubCommitNameAndEmail scrubs all commit names and emails
-func ScrubCommitNameAndEmail(localPath, newName, newEmail string) error {
-	if err := os.RemoveAll(path.Join(localPath, ".git/refs/original/")); err != nil {
-		return err
+func (r *Rules) scrubFile(localPath, fileName string) error {
+	filePath := path.Join(localPath, fileName)
+
+	fileContent, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		log.Error("%v", err)
+		return err // error reading file
 	}
-	if _, err := git.NewCommand("filter-branch", "-f", "--env-filter", `
-export GIT_COMMITTER_NAME="`+newName+`"
-export GIT_COMMITTER_EMAIL="`+newEmail+`"
-export GIT_AUTHOR_NAME="`+newName+`"
-export GIT_AUTHOR_EMAIL="`+newEmail+`"
-`, "--tag-name-filter", "cat", "--", "--branches", "--tags").RunInDir(localPath); err != nil {
-		return err
+	scrubbed, changed, err := r.scrubContent(fileName, fileContent)
+	if err != nil {
+		log.Error("%v", err)
+		return err // error parsing file
+	}
+	if len(changed) == 0 {
+		return nil // nothing to scrub
 	}
-	if _, err := git.NewCommand("push", "--force", "--tags", "origin", "refs/heads/*").RunInDir(localPath); err != nil {
+
+	if err := ScrubFile(localPath, fileName); err != nil {
+		return err
+	} else if err := ioutil.WriteFile(filePath, scrubbed, 0666); err != nil {
 		return err
 	}
+
 	return nil
 }
 
+// ScrubMap will scrub a map with the instance's rules
+func ScrubMap(m map[string]interface{}) {
+	DefaultRules().ScrubMap(m)
+}
+
+// ScrubFile completely removes a file from a repository's history
+func ScrubFile(repoPath string, fileName string) error {
+	return RewriteHistory(graceful.GetManager().ShutdownContext(), repoPath, RewriteOptions{
+		DropPaths: []string{fileName},
+		Prune:     true,
+	})
+}
+
+// ScrubCommitNameAndEmail replaces the author and committer of all commits of the branches and tags with the given name and email
+func ScrubCommitNameAndEmail(localPath, newName, newEmail string) error {
+	return RewriteHistory(graceful.GetManager().ShutdownContext(), localPath, RewriteOptions{
+		Name:  newName,
+		Email: newEmail,
+	})
+}
+
 /*** END DCS Customizations ***/
@@ -7,12 +7,14 @@
 package scrubber_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -62,6 +64,74 @@ func TestScrubJsonFiles(t *testing.T) {
 	}
 }
 
+func TestScrubLocalRepo(t *testing.T) {
+	repoDir, err := ioutil.TempDir(os.TempDir(), "scrub_local_test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+	assert.NoError(t, git.InitRepository(repoDir, false))
+
+	sig := &git.Signature{Name: "John Smith", Email: "john@smith.com"}
+	commit := func(message string, files map[string]string) {
+		for name, content := range files {
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, name), []byte(content), 0666))
+		}
+		assert.NoError(t, git.AddChanges(repoDir, true))
+		assert.NoError(t, git.CommitChanges(repoDir, git.CommitChangesOptions{Committer: sig, Author: sig, Message: message}))
+	}
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoDir)
+		assert.NoError(t, err)
+		return strings.TrimSpace(stdout)
+	}
+
+	run("config", "user.name", sig.Name)
+	run("config", "user.email", sig.Email)
+
+	commit("Initial commit", map[string]string{"README.md": "readme", "manifest.json": `{"title": "Clean"}`})
+	run("branch", "clean")
+	commit("Add translators", map[string]string{"manifest.json": `{"title": "Clean", "translators": ["John"]}`, "credits.json": `{"translators": ["Jane"]}`})
+	run("update-ref", "refs/pull/1/head", "HEAD")
+	run("rm", "-q", "credits.json")
+	commit("Remove credits", nil)
+	run("tag", "-a", "v1", "-m", "Version 1")
+	run("tag", "v1-light")
+
+	rules := &scrubber.Rules{
+		Files:          []string{"manifest.json", "credits.json"},
+		Fields:         []string{"translators"},
+		CommitterName:  "Door43",
+		CommitterEmail: "commit@door43.org",
+	}
+	report, err := scrubber.FindSensitiveDataInPath(repoDir, "master", rules)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master:manifest.json", "v1:manifest.json", "v1-light:manifest.json"}, report.ScrubbedFiles())
+	assert.Equal(t, []string{"credits.json", "manifest.json"}, report.DroppedFiles)
+
+	assert.NoError(t, scrubber.ScrubLocalRepo(context.Background(), repoDir, report, "Scrub sensitive data", "TestScrubLocalRepo"))
+
+	// The scrubbed file is gone from the history, and committed back scrubbed to the refs that had it
+	assert.Empty(t, run("log", "--all", "--format=%H", "-G", "John"))
+
+	// A file with sensitive data only in the history is gone from it too, including from the pull requests
+	assert.Empty(t, run("log", "--all", "--format=%H", "--", "credits.json"))
+	assert.Equal(t, "README.md", run("ls-tree", "-r", "--name-only", "refs/pull/1/head"))
+	assert.Equal(t, "Door43", run("log", "--format=%an", "-1", "refs/pull/1/head"))
+	for _, ref := range []string{"master", "v1", "v1-light"} {
+		assert.NotContains(t, run("show", ref+":manifest.json"), "John", ref)
+		assert.Equal(t, "Scrub sensitive data", run("log", "--format=%s", "-1", ref), ref)
+	}
+
+	// The branch that only had a clean copy of it keeps that copy
+	assert.Equal(t, `{"title": "Clean"}`, run("show", "clean:manifest.json"))
+	assert.Equal(t, "README.md\nmanifest.json", run("ls-tree", "-r", "--name-only", "clean"))
+
+	// The annotated tag is recreated with its message and the lightweight tag stays lightweight
+	assert.Equal(t, "tag", run("cat-file", "-t", "v1"))
+	assert.Equal(t, "Version 1", run("for-each-ref", "--format=%(contents:subject)", "refs/tags/v1"))
+	assert.Equal(t, "Door43 <commit@door43.org>", run("for-each-ref", "--format=%(taggername) %(taggeremail)", "refs/tags/v1"))
+	assert.Equal(t, "commit", run("cat-file", "-t", "v1-light"))
+}
+
 // The below code to copy directories was retrieved by Richard Mahn from https://gist.github.com/m4ng0squ4sh/92462b38df26839a3ca324697c8cba04
 
 // CopyFile copies the contents of the file named src to the file named
@@ -427,9 +427,38 @@ var (
 	InternalToken string // internal access token
 
 	/*** DCS Customizations ***/
-	DCS struct {
-		GATrackingID     string
-		Door43PreviewURL string
+	DCS = struct {
+		GATrackingID      string
+		Door43PreviewURL  string
+		CatalogStaleAfter time.Duration
+		ReleaseArtifacts  bool
+		OAIAdminEmail     string
+		Scrubber          struct {
+			Files          []string
+			Fields         []string
+			CommitterName  string
+			CommitterEmail string
+			Redact         []string
+			PushCheck      string
+		}
+	}{
+		CatalogStaleAfter: 365 * 24 * time.Hour,
+		ReleaseArtifacts:  true,
+		Scrubber: struct {
+			Files          []string
+			Fields         []string
+			CommitterName  string
+			CommitterEmail string
+			Redact         []string
+			PushCheck      string
+		}{
+			Files:          []string{"project.json", "package.json", "manifest.json", "status.json", "manifest.yaml", "**/*.usfm", "**/*.md"},
+			Fields:         []string{"translators", "contributors", "checking_entity", "dublin_core.contributor"},
+			CommitterName:  "Door43",
+			CommitterEmail: "commit@door43.org",
+			Redact:         []string{"email", "phone"},
+			PushCheck:      "warn",
+		},
 	}
 	/*** END DCS Customizations ***/
 )
@@ -982,6 +1011,22 @@ func NewContext() {
 	/*** DCS Customizations ***/
 	DCS.GATrackingID = Cfg.Section("dcs").Key("GA_TRACKING_ID").MustString("UA-60106521-5")
 	DCS.Door43PreviewURL = Cfg.Section("dcs").Key("DOOR43_PREVIEW_URL").MustString("https://door43.org")
+	DCS.CatalogStaleAfter = Cfg.Section("dcs").Key("CATALOG_STALE_AFTER").MustDuration(DCS.CatalogStaleAfter)
+	DCS.ReleaseArtifacts = Cfg.Section("dcs").Key("RELEASE_ARTIFACTS").MustBool(DCS.ReleaseArtifacts)
+	DCS.OAIAdminEmail = Cfg.Section("dcs").Key("OAI_ADMIN_EMAIL").MustString("")
+	sec = Cfg.Section("dcs.scrubber")
+	if files := sec.Key("FILES").Strings(","); len(files) > 0 {
+		DCS.Scrubber.Files = files
+	}
+	if fields := sec.Key("FIELDS").Strings(","); len(fields) > 0 {
+		DCS.Scrubber.Fields = fields
+	}
+	DCS.Scrubber.CommitterName = sec.Key("COMMITTER_NAME").MustString(DCS.Scrubber.CommitterName)
+	DCS.Scrubber.CommitterEmail = sec.Key("COMMITTER_EMAIL").MustString(DCS.Scrubber.CommitterEmail)
+	if sec.HasKey("REDACT") {
+		DCS.Scrubber.Redact = sec.Key("REDACT").Strings(",")
+	}
+	DCS.Scrubber.PushCheck = sec.Key("PUSH_CHECK").In(DCS.Scrubber.PushCheck, []string{"off", "warn", "reject"})
 	/*** END DCS Customizations ***/
 
 	HasRobotsTxt, err = util.IsFile(path.Join(CustomPath, "robots.txt"))
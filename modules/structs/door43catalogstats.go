@@ -0,0 +1,43 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// CatalogStatsCount is the number of catalog entries with a value, such as those of the language en
+type CatalogStatsCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CatalogStatsV5 are the statistics of the latest catalog entries of the repositories at a stage
+type CatalogStatsV5 struct {
+	Stage string `json:"stage"`
+	// the number of repositories with an entry at the stage
+	Total          int64                `json:"total"`
+	Languages      []*CatalogStatsCount `json:"languages"`
+	Subjects       []*CatalogStatsCount `json:"subjects"`
+	CheckingLevels []*CatalogStatsCount `json:"checking_levels"`
+	// the number of releases of each month, e.g. 2021-03, oldest first
+	ReleasesPerMonth []*CatalogStatsCount `json:"releases_per_month"`
+	// swagger:strfmt date-time
+	StaleBefore time.Time `json:"stale_before"`
+	// the number of entries released before stale_before
+	StaleCount int64 `json:"stale_count"`
+	// the oldest of the entries released before stale_before
+	Stale []*Door43MetadataV5 `json:"stale"`
+}
+
+// CatalogStatsSnapshot is the number of production catalog entries of a kind as recorded at a given time
+type CatalogStatsSnapshot struct {
+	// total, language, subject or checking_level
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+	// swagger:strfmt date-time
+	Date time.Time `json:"date"`
+}
@@ -0,0 +1,16 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// RepoArchiveImport is the result of the import of a zip or translationStudio archive into a repository
+// swagger:model
+type RepoArchiveImport struct {
+	Repository *Repository `json:"repository"`
+	// Layout of the archive: rc for a resource container, ts for translationStudio projects converted
+	// to a resource container, or files for any other files
+	Layout string `json:"layout"`
+	// SHA of the commit of the imported files
+	CommitID string `json:"commit_id"`
+}
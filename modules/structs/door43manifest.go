@@ -0,0 +1,21 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// GenerateManifestOptions options for generating and committing a manifest.yaml file for a repo that lacks one
+type GenerateManifestOptions struct {
+	FileOptions
+	// open a pull request from `new_branch` into `branch` for the new manifest.yaml file. requires `new_branch`
+	PullRequest bool `json:"pull_request"`
+}
+
+// GeneratedManifest represents a manifest.yaml file generated from the files of a repo
+type GeneratedManifest struct {
+	Manifest map[string]interface{} `json:"manifest"`
+	// the manifest as YAML
+	Content     string        `json:"content"`
+	File        *FileResponse `json:"file,omitempty"`
+	PullRequest *PullRequest  `json:"pull_request,omitempty"`
+}
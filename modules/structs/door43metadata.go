@@ -51,6 +51,72 @@ type Door43MetadataV5 struct {
 	Released               string        `json:"released"`
 	Books                  []string      `json:"books"`
 	Ingredients            []interface{} `json:"ingredients,omitempty"`
+	// the SHA-256 checksums of the files of a release, in the format of sha256sum
+	ChecksumsURL string `json:"checksums_url,omitempty"`
+	// the ASCII armored detached signature of the checksums, if the instance signs them
+	ChecksumsSignatureURL string `json:"checksums_signature_url,omitempty"`
+	SigningKeyURL         string `json:"signing_key_url,omitempty"`
+	// the inconsistencies of the manifest.yaml, e.g. with the name of the repository or the release tag
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CatalogVerificationV5 is the result of the verification of a copy of a release against its checksums
+type CatalogVerificationV5 struct {
+	// whether every file of the copy has a matching checksum and every checksum has a file
+	Valid bool `json:"valid"`
+	// the paths of the files whose checksums match
+	Matched []string `json:"matched"`
+	// the paths of the files whose checksums do not match
+	Mismatched []string `json:"mismatched"`
+	// the paths of the files of the release that are not in the copy, only those listed in the checksums.sha256
+	// file of a copy that has one, e.g. an RC export
+	Missing []string `json:"missing"`
+	// the paths of the files of the copy that are not in the release
+	Extra                 []string `json:"extra"`
+	ChecksumsURL          string   `json:"checksums_url"`
+	ChecksumsSignatureURL string   `json:"checksums_signature_url,omitempty"`
+	// the ID of the key the checksums were signed with
+	SigningKey    string `json:"signing_key,omitempty"`
+	SigningKeyURL string `json:"signing_key_url,omitempty"`
+}
+
+// CatalogPreviewV5 is the catalog entry a ref of a repository, e.g. a branch or the head of a pull request,
+// would have, with the changes of its metadata from the production entry of the repository
+type CatalogPreviewV5 struct {
+	Ref       string `json:"ref"`
+	CommitSHA string `json:"commit_sha"`
+	// whether the ref has a valid manifest.yaml
+	Valid bool `json:"valid"`
+	// the entry, null if the ref has no valid manifest.yaml
+	Entry *Door43MetadataV5 `json:"entry"`
+	// the manifest.yaml in JSON format, null if the ref has none or it cannot be read
+	Metadata *map[string]interface{} `json:"metadata"`
+	// why the manifest.yaml is not valid
+	Errors []string `json:"errors"`
+	// the inconsistencies of a valid manifest.yaml, e.g. with the name of the repository
+	Warnings []string `json:"warnings"`
+	// the latest production entry of the repository, null if there is none
+	Prod *Door43MetadataV5 `json:"prod"`
+	// the changes from the metadata of the production entry to the manifest.yaml
+	Changes []*CatalogMetadataChangeV5 `json:"changes"`
+}
+
+// CatalogMetadataChangeV5 is a change of a value of the metadata at a path such as dublin_core.version or
+// projects[0].identifier
+type CatalogMetadataChangeV5 struct {
+	Path string `json:"path"`
+	// "added", "removed" or "changed"
+	Type    string      `json:"type"`
+	Prod    interface{} `json:"prod"`
+	Preview interface{} `json:"preview"`
+}
+
+// BookProgress is the translation progress of a book: the number of verses of its USFM file with text,
+// of the verses of the book in the versification
+type BookProgress struct {
+	Verses      int `json:"verses"`
+	TotalVerses int `json:"total_verses"`
+	Percent     int `json:"percent"`
 }
 
 // CatalogSearchResultsV4 results of a successful search for V4
@@ -0,0 +1,51 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// ReferenceSearchResult is what was found at a Bible reference in a repository and in its related resources
+type ReferenceSearchResult struct {
+	// the reference that was searched for, e.g. TIT 1:3
+	Reference string `json:"reference"`
+	// the repository first, then its related resources
+	Repos []*ReferenceRepoMatches `json:"repos"`
+}
+
+// ReferenceRepoMatches is what was found at a Bible reference in a branch, tag or commit of a repository
+type ReferenceRepoMatches struct {
+	FullName string `json:"full_name"`
+	// the branch, tag or commit ID that was searched
+	Ref     string `json:"ref"`
+	HTMLURL string `json:"html_url"`
+	// the relation of the manifest of the repository that was searched through which this is related, if any
+	Relation string `json:"relation,omitempty"`
+	// the verses found in the USFM files
+	Verses []*ReferenceVerse `json:"verses"`
+	// the rows found in the TSV files, such as those of tN, tQ and tWL
+	Tables []*ReferenceTable `json:"tables"`
+}
+
+// ReferenceVerse is a verse found in a USFM file, without its markup
+type ReferenceVerse struct {
+	Path string `json:"path"`
+	// chapter:verse, e.g. 1:3 or 1:3-4
+	Ref     string `json:"ref"`
+	Text    string `json:"text"`
+	HTMLURL string `json:"html_url"`
+}
+
+// ReferenceTable is the rows found in a TSV file
+type ReferenceTable struct {
+	Path    string               `json:"path"`
+	HTMLURL string               `json:"html_url"`
+	Header  []string             `json:"header"`
+	Rows    []*ReferenceTableRow `json:"rows"`
+}
+
+// ReferenceTableRow is a row found in a TSV file
+type ReferenceTableRow struct {
+	Line    int      `json:"line"`
+	Values  []string `json:"values"`
+	HTMLURL string   `json:"html_url"`
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// TranslateRepoOption options when creating a repository to translate a catalog entry of another one into a language
+// swagger:model
+type TranslateRepoOption struct {
+	// The organization or person who will own the new repository
+	//
+	// required: true
+	Owner string `json:"owner" binding:"Required"`
+	// Identifier of the language to translate into, e.g. fr. The repository is named {language}_{subject}.
+	//
+	// required: true
+	Language string `json:"language" binding:"Required;MaxSize(50)"`
+	// Name of the language, looked up if empty
+	LanguageTitle string `json:"language_title" binding:"MaxSize(100)"`
+	// Direction of the language, ltr or rtl, looked up if empty
+	LanguageDirection string `json:"language_direction" binding:"MaxSize(3)"`
+	// Release tag or default branch of the catalog entry to translate, the latest production entry if empty
+	// and else that of the default branch
+	Ref string `json:"ref"`
+	// Description of the repository to create
+	Description string `json:"description" binding:"MaxSize(255)"`
+	// Whether the repository is private
+	Private bool `json:"private"`
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// ScrubRules are the rules for scrubbing sensitive data from the repos of an organization
+type ScrubRules struct {
+	// globs of the JSON and YAML files to scrub, prefix a glob with **/ to match it in any directory
+	Files []string `json:"files"`
+	// fields to empty, a name or dotted path matches from the top of the file, prefixed with **. at any depth
+	Fields []string `json:"fields"`
+	// name and email that replace the author and committer of every commit
+	CommitterName  string `json:"committer_name"`
+	CommitterEmail string `json:"committer_email"`
+}
+
+// EditScrubRulesOption options for editing the scrub rules of an organization.
+// Empty values use the rules of the instance.
+type EditScrubRulesOption struct {
+	Files          []string `json:"files"`
+	Fields         []string `json:"fields"`
+	CommitterName  string   `json:"committer_name" binding:"MaxSize(255)"`
+	CommitterEmail string   `json:"committer_email" binding:"MaxSize(255)"`
+}
+
+// ScrubOptions options for scrubbing sensitive data from a repo
+type ScrubOptions struct {
+	// only report what would be scrubbed without changing the repo
+	DryRun bool `json:"dry_run"`
+	// message of the commits of the scrubbed files
+	Message string `json:"message"`
+}
+
+// ScrubReport represents what a scrub of a repo changed, or would change if it is a dry run
+type ScrubReport struct {
+	DryRun         bool              `json:"dry_run"`
+	CommitterName  string            `json:"committer_name"`
+	CommitterEmail string            `json:"committer_email"`
+	Refs           []*ScrubRefReport `json:"refs"`
+	// paths of the files matching the rules anywhere in the history, which are dropped from every commit
+	DroppedFiles []string `json:"dropped_files"`
+}
+
+// ScrubRefReport represents a branch or tag of a scrubbed repo. Every ref is rewritten
+// to replace the authors and committers of its commits.
+type ScrubRefReport struct {
+	Ref  string `json:"ref"`
+	Name string `json:"name"`
+	// commit of the ref before the scrub
+	CommitSHA string             `json:"commit_sha"`
+	Files     []*ScrubFileReport `json:"files"`
+}
+
+// ScrubFileReport represents a scrubbed file and its emptied fields
+type ScrubFileReport struct {
+	Path   string   `json:"path"`
+	Fields []string `json:"fields"`
+}
+
+// ScrubLog represents the audit log entry of a scrub of a repo
+type ScrubLog struct {
+	ID   int64    `json:"id"`
+	Doer *User    `json:"doer"`
+	Refs []string `json:"refs"`
+	// ref:path of each scrubbed file
+	Files          []string `json:"files"`
+	CommitterName  string   `json:"committer_name"`
+	CommitterEmail string   `json:"committer_email"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - YAML rendering options ***/
+
+package structs
+
+// YamlOption options for rendering YAML
+type YamlOption struct {
+	// Text YAML to render, which can be a multi-document stream
+	//
+	// in: body
+	Text string
+	// Mode to render, `html` (default) for HTML tables or `json` for the documents as JSON,
+	// an array of them for a multi-document stream
+	//
+	// in: body
+	Mode string `binding:"In(,html,json)"`
+}
+
+// YamlRender is a rendered YAML document, HTML or JSON
+// swagger:response YamlRender
+type YamlRender string
+
+/*** END DCS Customizations ***/
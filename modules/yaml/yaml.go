@@ -7,7 +7,14 @@
 package yaml
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/microcosm-cc/bluemonday"
 	"gopkg.in/yaml.v2"
@@ -15,146 +22,182 @@ import (
 
 var sanitizer = bluemonday.UGCPolicy()
 
-func renderHorizontalHTMLTable(m yaml.MapSlice) (string, error) {
-	var err error
-	var thead, tbody, table string
-	var mi yaml.MapItem
-	for _, mi = range m {
-		key := mi.Key
-		value := mi.Value
-
-		switch slice := key.(type) {
-		case yaml.MapSlice:
-			key, err = renderHorizontalHTMLTable(slice)
-			if err != nil {
-				return "", err
+// node decodes any YAML value, keeping the order of the keys of its mappings
+type node struct {
+	value interface{}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (n *node) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	switch raw.(type) {
+	case map[interface{}]interface{}:
+		// A MapSlice has the keys in their order, but loses those merged in with <<
+		var ms yaml.MapSlice
+		if err := unmarshal(&ms); err != nil {
+			return err
+		}
+		var values map[interface{}]*node
+		if err := unmarshal(&values); err != nil {
+			return err
+		}
+		n.value = orderMapping(ms, values)
+	case []interface{}:
+		var list []*node
+		if err := unmarshal(&list); err != nil {
+			return err
+		}
+		values := make([]interface{}, len(list))
+		for i, item := range list {
+			if item != nil {
+				values[i] = item.value
 			}
 		}
-		thead += fmt.Sprintf("<th>%v</th>", key)
+		n.value = values
+	default:
+		n.value = raw
+	}
+	return nil
+}
 
-		switch switchedValue := value.(type) {
-		case yaml.MapSlice:
-			value, err = renderHorizontalHTMLTable(switchedValue)
-			if err != nil {
-				return "", err
-			}
-		case []interface{}:
-			v := make([]yaml.MapSlice, len(switchedValue))
-			for i, vs := range switchedValue {
-				switch vs := vs.(type) {
-				case yaml.MapSlice:
-					v[i] = vs
-				default:
-					return "", fmt.Errorf("Unexpected type %T, expected MapSlice", vs)
-				}
-			}
-			value, err = renderVerticalHTMLTable(v)
-			if err != nil {
-				return "", err
-			}
+// orderMapping returns the values of a mapping in the order of its keys, those merged in with << coming first
+func orderMapping(ms yaml.MapSlice, values map[interface{}]*node) yaml.MapSlice {
+	explicit := make(map[interface{}]bool, len(ms))
+	for _, mi := range ms {
+		explicit[mi.Key] = true
+	}
+	merged := make(yaml.MapSlice, 0, len(values)-len(ms))
+	for key := range values {
+		if !explicit[key] {
+			merged = append(merged, yaml.MapItem{Key: key})
 		}
-		tbody += fmt.Sprintf("<td>%v</td>", value)
 	}
+	sort.Slice(merged, func(i, j int) bool {
+		return fmt.Sprint(merged[i].Key) < fmt.Sprint(merged[j].Key)
+	})
 
-	table = ""
-	if len(thead) > 0 {
-		table = fmt.Sprintf(`<table data="yaml-metadata"><thead><tr>%s</tr></thead><tbody><tr>%s</tr></table>`, thead, tbody)
+	ordered := append(merged, ms...)
+	for i := range ordered {
+		if value := values[ordered[i].Key]; value != nil {
+			ordered[i].Value = value.value
+		} else {
+			ordered[i].Value = nil
+		}
 	}
-	return table, nil
+	return ordered
 }
 
-func renderVerticalHTMLTable(m []yaml.MapSlice) (string, error) {
-	var err error
-	var ms yaml.MapSlice
-	var mi yaml.MapItem
-	var table string
-
-	for _, ms = range m {
-		table += `<table data="yaml-metadata">`
-		for _, mi = range ms {
-			key := mi.Key
-			value := mi.Value
-
-			table += `<tr>`
-			switch switchedKey := key.(type) {
-			case yaml.MapSlice:
-				key, err = renderHorizontalHTMLTable(switchedKey)
-				if err != nil {
-					return "", err
-				}
-			case []interface{}:
-				var ks string
-				for _, ki := range switchedKey {
-					switch ki := ki.(type) {
-					case yaml.MapSlice:
-						horiz, err := renderHorizontalHTMLTable(ki)
-						if err != nil {
-							return "", err
-						}
-						ks += horiz
-					default:
-						return "", fmt.Errorf("Unexpected type %T, expected MapSlice", ki)
-					}
-				}
-				key = ks
-			}
-			table += fmt.Sprintf("<td>%v</td>", key)
+// Parse parses the documents of a YAML stream, resolving anchors, aliases and merge keys.
+// Mappings are yaml.MapSlice to keep the order of their keys and sequences are []interface{}.
+func Parse(data []byte) ([]interface{}, error) {
+	var docs []interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc node
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc.value)
+	}
+	return docs, nil
+}
 
-			switch switchedValue := value.(type) {
-			case yaml.MapSlice:
-				value, err = renderHorizontalHTMLTable(switchedValue)
-				if err != nil {
-					return "", err
-				}
-			case []interface{}:
-				v := make([]yaml.MapSlice, len(switchedValue))
-				for i, vs := range switchedValue {
-					switch vs := vs.(type) {
-					case yaml.MapSlice:
-						v[i] = vs
-					default:
-						return "", fmt.Errorf("Unexpected type %T, expected MapSlice", vs)
-					}
-				}
-				value, err = renderVerticalHTMLTable(v)
-				if err != nil {
-					return "", err
-				}
-			}
+func isMapSliceList(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item.(yaml.MapSlice); !ok {
+			return false
+		}
+	}
+	return true
+}
 
-			switch key {
-			case "slug":
-				value = fmt.Sprintf(`<a href="content/%v.md">%v</a>`, value, value)
-			case "link":
-				value = fmt.Sprintf(`<a href="%v/01.md">%v</a>`, value, value)
-			}
-			table += fmt.Sprintf("<td>%v</td>", value)
-			table += `</tr>`
+// renderValue renders a mapping as a horizontal table, a sequence of mappings as vertical tables,
+// any other sequence as a list, and scalars as escaped text
+func renderValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case yaml.MapSlice:
+		return renderHorizontalHTMLTable(v)
+	case []interface{}:
+		if isMapSliceList(v) {
+			return renderVerticalHTMLTable(v)
+		}
+		if len(v) == 0 {
+			return ""
 		}
-		table += "</table>"
+		var sb strings.Builder
+		sb.WriteString("<ul>")
+		for _, item := range v {
+			sb.WriteString("<li>" + renderValue(item) + "</li>")
+		}
+		sb.WriteString("</ul>")
+		return sb.String()
+	default:
+		return html.EscapeString(fmt.Sprint(v))
+	}
+}
+
+func renderHorizontalHTMLTable(m yaml.MapSlice) string {
+	if len(m) == 0 {
+		return ""
+	}
+	var thead, tbody strings.Builder
+	for _, mi := range m {
+		thead.WriteString("<th>" + renderValue(mi.Key) + "</th>")
+		tbody.WriteString("<td>" + renderValue(mi.Value) + "</td>")
 	}
+	return fmt.Sprintf(`<table data="yaml-metadata"><thead><tr>%s</tr></thead><tbody><tr>%s</tr></tbody></table>`, thead.String(), tbody.String())
+}
 
-	return table, nil
+func renderVerticalHTMLTable(list []interface{}) string {
+	var sb strings.Builder
+	for _, item := range list {
+		sb.WriteString(`<table data="yaml-metadata">`)
+		for _, mi := range item.(yaml.MapSlice) {
+			value := renderValue(mi.Value)
+			// The slugs and links of a toc.yaml link to their content
+			if s, ok := mi.Value.(string); ok {
+				switch mi.Key {
+				case "slug":
+					value = fmt.Sprintf(`<a href="content/%s.md">%s</a>`, url.PathEscape(s), value)
+				case "link":
+					value = fmt.Sprintf(`<a href="%s/01.md">%s</a>`, url.PathEscape(s), value)
+				}
+			}
+			sb.WriteString("<tr><td>" + renderValue(mi.Key) + "</td><td>" + value + "</td></tr>")
+		}
+		sb.WriteString("</table>")
+	}
+	return sb.String()
 }
 
-// Render render yaml contents as html
+// Render render yaml contents as html, each document of a multi-document stream separated by a rule
 func Render(data []byte) ([]byte, error) {
-	mss := []yaml.MapSlice{}
-
 	if len(data) < 1 {
 		return data, nil
 	}
 
-	if err := yaml.Unmarshal(data, &mss); err != nil {
-		ms := yaml.MapSlice{}
-		if err := yaml.Unmarshal(data, &ms); err != nil {
-			return nil, err
+	docs, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for i, doc := range docs {
+		if i > 0 {
+			sb.WriteString("<hr>")
 		}
-		table, err := renderHorizontalHTMLTable(ms)
-		return []byte(table), err
+		sb.WriteString(renderValue(doc))
 	}
-	table, err := renderVerticalHTMLTable(mss)
-	return []byte(table), err
+	return []byte(sb.String()), nil
 }
 
 // RenderSanitized render yaml as sanitized html
@@ -165,3 +208,63 @@ func RenderSanitized(rawBytes []byte) ([]byte, error) {
 	}
 	return sanitizer.SanitizeBytes(result), nil
 }
+
+// toJSONValue converts a parsed YAML value to one that encoding/json can marshal
+func toJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		return orderedMap(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = toJSONValue(item)
+		}
+		return list
+	}
+	return value
+}
+
+// orderedMap marshals a mapping to a JSON object with the keys in their order
+type orderedMap yaml.MapSlice
+
+// MarshalJSON implements json.Marshaler
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, mi := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(fmt.Sprint(mi.Key))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(toJSONValue(mi.Value))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ToJSON converts YAML contents to JSON with anchors and aliases resolved and the keys in their order.
+// A multi-document stream is converted to an array of its documents.
+func ToJSON(data []byte) ([]byte, error) {
+	docs, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return []byte("null"), nil
+	case 1:
+		return json.Marshal(toJSONValue(docs[0]))
+	}
+	return json.Marshal(toJSONValue(docs))
+}
+
+/*** END DCS Customizations ***/
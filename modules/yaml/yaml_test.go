@@ -26,7 +26,47 @@ func TestRenderYaml(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, strings.HasSuffix(string(rendered), "</table>"))
 
-	contents = "misformatted&"
+	contents = "a: [misformatted"
 	_, err = Render([]byte(contents))
 	assert.Error(t, err)
 }
+
+func TestRenderYamlValues(t *testing.T) {
+	// Lists of scalars, with the values escaped
+	rendered, err := Render([]byte("books:\n- gen\n- <b>exo</b>\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `<table data="yaml-metadata"><thead><tr><th>books</th></tr></thead><tbody><tr><td><ul><li>gen</li><li>&lt;b&gt;exo&lt;/b&gt;</li></ul></td></tr></tbody></table>`, string(rendered))
+
+	// Lists of mappings, such as a toc.yaml
+	rendered, err = Render([]byte("- title: Introduction\n  link: ta-intro\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `<table data="yaml-metadata"><tr><td>title</td><td>Introduction</td></tr><tr><td>link</td><td><a href="ta-intro/01.md">ta-intro</a></td></tr></table>`, string(rendered))
+
+	// Scalars and multi-document streams
+	rendered, err = Render([]byte("just text\n---\n- 1\n- 2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `just text<hr><ul><li>1</li><li>2</li></ul>`, string(rendered))
+
+	// Anchors, aliases and merge keys
+	rendered, err = Render([]byte("base: &base\n  a: 1\nother:\n  <<: *base\n  b: 2\n"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(rendered), `<th>other</th>`)
+	assert.Contains(t, string(rendered), `<thead><tr><th>a</th><th>b</th></tr></thead><tbody><tr><td>1</td><td>2</td></tr></tbody>`)
+}
+
+func TestToJSON(t *testing.T) {
+	json, err := ToJSON([]byte("z: &list\n- 1\n- two\na:\n  c: true\n  b: *list\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"z":[1,"two"],"a":{"c":true,"b":[1,"two"]}}`, string(json))
+
+	json, err = ToJSON([]byte("- a: 1\n---\nb: null\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `[[{"a":1}],{"b":null}]`, string(json))
+
+	json, err = ToJSON([]byte(""))
+	assert.NoError(t, err)
+	assert.Equal(t, `null`, string(json))
+
+	_, err = ToJSON([]byte("a: [misformatted"))
+	assert.Error(t, err)
+}
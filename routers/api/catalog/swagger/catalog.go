@@ -36,6 +36,20 @@ type swaggerResponseCatalogEntryV5 struct {
 	Body api.Door43MetadataV5 `json:"body"`
 }
 
+// CatalogVerificationV5
+// swagger:response CatalogVerificationV5
+type swaggerResponseCatalogVerificationV5 struct {
+	// in:body
+	Body api.CatalogVerificationV5 `json:"body"`
+}
+
+// CatalogPreviewV5
+// swagger:response CatalogPreviewV5
+type swaggerResponseCatalogPreviewV5 struct {
+	// in:body
+	Body api.CatalogPreviewV5 `json:"body"`
+}
+
 // CatalogMetadata
 // swagger:response CatalogMetadata
 type swaggerResponseCatalogMetadata struct {
@@ -49,3 +63,17 @@ type swaggerResponseCatalogVersionEndpointsResponse struct {
 	// in:body
 	Body api.CatalogVersionEndpointsResponse `json:"body"`
 }
+
+// CatalogStatsV5
+// swagger:response CatalogStatsV5
+type swaggerResponseCatalogStatsV5 struct {
+	// in:body
+	Body api.CatalogStatsV5 `json:"body"`
+}
+
+// CatalogStatsSnapshotList
+// swagger:response CatalogStatsSnapshotList
+type swaggerResponseCatalogStatsSnapshotList struct {
+	// in:body
+	Body []api.CatalogStatsSnapshot `json:"body"`
+}
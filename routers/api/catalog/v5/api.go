@@ -223,9 +223,25 @@ func Routes() *web.Route {
 				}, repoAssignment())
 			})
 		})
+		m.Group("/stats", func() {
+			m.Get("", GetStats)
+			m.Get("/history", GetStatsHistory)
+		})
+		m.Combo("/oai").Get(OAIPMH).Post(OAIPMH)
+		m.Group("/opds/{version}", func() {
+			m.Get("", GetOPDSRoot)
+			m.Get("/languages", GetOPDSLanguages)
+			m.Get("/subjects", GetOPDSSubjects)
+			m.Get("/search", SearchOPDS)
+		}, opdsVersion())
+		m.Get("/entry/{username}/{reponame}", repoAssignment(), GetCatalogEntryByVersion)
+		m.Get("/preview/{username}/{reponame}", repoAssignment(), PreviewCatalogEntry)
 		m.Group("/entry/{username}/{reponame}/{tag}", func() {
 			m.Get("", GetCatalogEntry)
 			m.Get("/metadata", GetCatalogMetadata)
+			m.Get("/checksums", GetCatalogChecksums)
+			m.Get("/checksums.asc", GetCatalogChecksumsSignature)
+			m.Post("/verify", VerifyCatalogEntry)
 		}, repoAssignment())
 	}, sudo())
 
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/dcs"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
@@ -26,6 +27,7 @@ var searchOrderByMap = map[string]map[string]models.CatalogOrderBy{
 		"stars":    models.CatalogOrderByStars,
 		"forks":    models.CatalogOrderByForks,
 		"tag":      models.CatalogOrderByTag,
+		"version":  models.CatalogOrderByVersion,
 	},
 	"desc": {
 		"title":    models.CatalogOrderByTitleReverse,
@@ -36,6 +38,7 @@ var searchOrderByMap = map[string]map[string]models.CatalogOrderBy{
 		"stars":    models.CatalogOrderByStarsReverse,
 		"forks":    models.CatalogOrderByForksReverse,
 		"tag":      models.CatalogOrderByTagReverse,
+		"version":  models.CatalogOrderByVersionReverse,
 	},
 }
 
@@ -63,6 +66,12 @@ func Search(ctx *context.APIContext) {
 	//   in: query
 	//   description: search only for entries with the given release tag(s)
 	//   type: string
+	// - name: version
+	//   in: query
+	//   description: 'search only for entries whose version (release tag, or dublin_core.version if the tag is not a version)
+	//                satisfies the given constraint(s), e.g. ">=20" or ">=20,<30". Supported operators are
+	//                "=", "!=", ">", ">=", "<", "<=", "~>" and "^". Without includeHistory, the latest matching release is returned'
+	//   type: string
 	// - name: lang
 	//   in: query
 	//   description: search only for entries with the given language(s)
@@ -102,7 +111,7 @@ func Search(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos alphanumerically by attribute. Supported values are
-	//                "subject", "title", "tag", "released", "lang", "releases", "stars", "forks".
+	//                "subject", "title", "tag", "version", "released", "lang", "releases", "stars", "forks".
 	//                Default is by "language", "subject" and then "tag"
 	//   type: string
 	// - name: order
@@ -152,6 +161,12 @@ func SearchOwner(ctx *context.APIContext) {
 	//   in: query
 	//   description: search only for entries with the given release tag(s)
 	//   type: string
+	// - name: version
+	//   in: query
+	//   description: 'search only for entries whose version (release tag, or dublin_core.version if the tag is not a version)
+	//                satisfies the given constraint(s), e.g. ">=20" or ">=20,<30". Supported operators are
+	//                "=", "!=", ">", ">=", "<", "<=", "~>" and "^". Without includeHistory, the latest matching release is returned'
+	//   type: string
 	// - name: lang
 	//   in: query
 	//   description: search only for entries with the given language(s)
@@ -191,7 +206,7 @@ func SearchOwner(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos alphanumerically by attribute. Supported values are
-	//                "subject", "title", "tag", "released", "lang", "releases", "stars", "forks".
+	//                "subject", "title", "tag", "version", "released", "lang", "releases", "stars", "forks".
 	//                Default is by "language", "subject" and then "tag"
 	//   type: string
 	// - name: order
@@ -242,6 +257,12 @@ func SearchRepo(ctx *context.APIContext) {
 	//   in: query
 	//   description: search only for entries with the given release tag(s)
 	//   type: string
+	// - name: version
+	//   in: query
+	//   description: 'search only for entries whose version (release tag, or dublin_core.version if the tag is not a version)
+	//                satisfies the given constraint(s), e.g. ">=20" or ">=20,<30". Supported operators are
+	//                "=", "!=", ">", ">=", "<", "<=", "~>" and "^". Without includeHistory, the latest matching release is returned'
+	//   type: string
 	// - name: lang
 	//   in: query
 	//   description: search only for entries with the given language(s)
@@ -281,7 +302,7 @@ func SearchRepo(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos alphanumerically by attribute. Supported values are
-	//                "subject", "title", "tag", "released", "lang", "releases", "stars", "forks".
+	//                "subject", "title", "tag", "version", "released", "lang", "releases", "stars", "forks".
 	//                Default is language,subject,tag
 	//   type: string
 	// - name: order
@@ -361,6 +382,80 @@ func GetCatalogEntry(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToDoor43MetadataV5(dm, accessMode))
 }
 
+// GetCatalogEntryByVersion Get the catalog entry from the given ownername and reponame with the highest version that satisfies the version constraint(s)
+func GetCatalogEntryByVersion(ctx *context.APIContext) {
+	// swagger:operation GET /v5/entry/{owner}/{repo} v5 v5GetCatalogEntryByVersion
+	// ---
+	// summary: Catalog entry with the highest version satisfying the given version constraint(s)
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: version
+	//   in: query
+	//   description: 'version constraint(s) the entry's version (release tag, or dublin_core.version if the tag is not a version)
+	//                must satisfy, e.g. ">=20" or ">=20,<30". Supported operators are "=", "!=", ">", ">=", "<", "<=", "~>" and "^".
+	//                If not given, the entry with the highest version is returned'
+	//   type: string
+	// - name: stage
+	//   in: query
+	//   description: 'specifies which release stage to be return of these stages:
+	//                "prod" - return only the production releases (default);
+	//                "preprod" - return the pre-production release if it exists instead of the production release;
+	//                "draft" - return the draft release if it exists instead of pre-production or production release;
+	//                "latest" -return the default branch (e.g. master) if it is a valid RC instead of the above'
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogEntryV5"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	versions, err := dcs.ParseVersionConstraints(ctx.Query("version"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	stageStr := ctx.Query("stage")
+	var stage models.Stage
+	if stageStr != "" {
+		var ok bool
+		stage, ok = models.StageMap[stageStr]
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid stage: \"%s\"", stageStr))
+			return
+		}
+	}
+
+	dm, err := models.GetDoor43MetadataByRepoIDAndVersion(ctx.Repo.Repository.ID, stage, versions)
+	if err != nil {
+		if models.IsErrDoor43MetadataNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataByRepoIDAndVersion", err)
+		}
+		return
+	}
+	dm.Repo = ctx.Repo.Repository
+	if err := dm.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToDoor43MetadataV5(dm, ctx.Repo.AccessMode))
+}
+
 // GetCatalogMetadata Get the metadata (RC 0.2.0 manifest) in JSON format for the given ownername, reponame and ref
 func GetCatalogMetadata(ctx *context.APIContext) {
 	// swagger:operation GET /v5/entry/{owner}/{repo}/{tag}/metadata v5 v5GetMetadata
@@ -411,7 +506,9 @@ func QueryStrings(ctx *context.APIContext, name string) []string {
 	return newStrs
 }
 
-func searchCatalog(ctx *context.APIContext) {
+// getSearchCatalogOptions returns the options of a catalog search from the query of the request, writing the
+// error and returning nil if they are invalid
+func getSearchCatalogOptions(ctx *context.APIContext) *models.SearchCatalogOptions {
 	var repoID int64
 	var owners, repos []string
 	includeMetadata := true
@@ -436,10 +533,16 @@ func searchCatalog(ctx *context.APIContext) {
 		stage, ok = models.StageMap[stageStr]
 		if !ok {
 			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid stage: \"%s\"", stageStr))
-			return
+			return nil
 		}
 	}
 
+	versions, err := dcs.ParseVersionConstraints(ctx.Query("version"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return nil
+	}
+
 	keywords := []string{}
 	query := strings.Trim(ctx.Query("q"), " ")
 	if query != "" {
@@ -459,6 +562,7 @@ func searchCatalog(ctx *context.APIContext) {
 		Subjects:        QueryStrings(ctx, "subject"),
 		CheckingLevels:  QueryStrings(ctx, "checkingLevel"),
 		Books:           QueryStrings(ctx, "book"),
+		Versions:        versions,
 		IncludeHistory:  ctx.QueryBool("includeHistory"),
 		ShowIngredients: ctx.QueryBool("showIngredients"),
 		IncludeMetadata: includeMetadata,
@@ -476,16 +580,24 @@ func searchCatalog(ctx *context.APIContext) {
 					opts.OrderBy = append(opts.OrderBy, orderBy)
 				} else {
 					ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid sort mode: \"%s\"", sortMode))
-					return
+					return nil
 				}
 			}
 		} else {
 			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid sort order: \"%s\"", sortOrder))
-			return
+			return nil
 		}
 	} else {
 		opts.OrderBy = []models.CatalogOrderBy{models.CatalogOrderByLangCode, models.CatalogOrderBySubject, models.CatalogOrderByTagReverse}
 	}
+	return opts
+}
+
+func searchCatalog(ctx *context.APIContext) {
+	opts := getSearchCatalogOptions(ctx)
+	if opts == nil {
+		return
+	}
 
 	dms, count, err := models.SearchCatalog(opts)
 	if err != nil {
@@ -0,0 +1,209 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package v5
+
+import (
+	"archive/zip"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/door43metadata"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// getEntryChecksums returns the catalog entry of the tag of the request and its checksums, computing them if they
+// have not been yet, writing an error if it has none
+func getEntryChecksums(ctx *context.APIContext) (*models.Door43Metadata, *models.Door43MetadataChecksums) {
+	dm, err := models.GetDoor43MetadataByRepoIDAndTagName(ctx.Repo.Repository.ID, ctx.Params("tag"))
+	if err != nil {
+		if models.IsErrDoor43MetadataNotExist(err) || models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataByRepoIDAndTagName", err)
+		}
+		return nil, nil
+	}
+	if !dm.HasChecksums() {
+		ctx.NotFound()
+		return nil, nil
+	}
+	dm.Repo = ctx.Repo.Repository
+	if err := dm.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil, nil
+	}
+	dmc, err := door43metadata.GetDoor43MetadataChecksums(dm)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDoor43MetadataChecksums", err)
+		return nil, nil
+	}
+	return dm, dmc
+}
+
+// GetCatalogChecksums Get the SHA-256 checksums of the files of a release
+func GetCatalogChecksums(ctx *context.APIContext) {
+	// swagger:operation GET /v5/entry/{owner}/{repo}/{tag}/checksums v5 v5GetChecksums
+	// ---
+	// summary: SHA-256 checksums of the files of a catalog entry's release, in the format of sha256sum
+	// produces:
+	// - text/plain
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: tag
+	//   in: path
+	//   description: release tag
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: "checksums"
+	//     schema:
+	//       type: string
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, dmc := getEntryChecksums(ctx)
+	if dmc == nil {
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	ctx.PlainText(http.StatusOK, []byte(dmc.Checksums))
+}
+
+// GetCatalogChecksumsSignature Get the signature of the checksums of the files of a release
+func GetCatalogChecksumsSignature(ctx *context.APIContext) {
+	// swagger:operation GET /v5/entry/{owner}/{repo}/{tag}/checksums.asc v5 v5GetChecksumsSignature
+	// ---
+	// summary: ASCII armored detached GPG signature of the checksums of a catalog entry's release
+	// produces:
+	// - application/pgp-signature
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: tag
+	//   in: path
+	//   description: release tag
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: "signature"
+	//     schema:
+	//       type: string
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, dmc := getEntryChecksums(ctx)
+	if dmc == nil {
+		return
+	}
+	if dmc.Signature == "" {
+		ctx.NotFound()
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/pgp-signature")
+	ctx.PlainText(http.StatusOK, []byte(dmc.Signature))
+}
+
+// VerifyCatalogEntry Verify a zip of a release against the checksums of its files
+func VerifyCatalogEntry(ctx *context.APIContext) {
+	// swagger:operation POST /v5/entry/{owner}/{repo}/{tag}/verify v5 v5VerifyEntry
+	// ---
+	// summary: Verify a zip of a catalog entry's release, e.g. its zipball or RC export, against the checksums of its files
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: tag
+	//   in: path
+	//   description: release tag
+	//   type: string
+	//   required: true
+	// - name: archive
+	//   in: formData
+	//   description: zip of the release to verify
+	//   type: file
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogVerificationV5"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	dm, dmc := getEntryChecksums(ctx)
+	if dmc == nil {
+		return
+	}
+	checksums, err := dcs.ParseChecksums(dmc.Checksums)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ParseChecksums", err)
+		return
+	}
+
+	file, header, err := ctx.Req.FormFile("archive")
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "FormFile", err)
+		return
+	}
+	defer file.Close()
+	reader, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", "The archive is not a zip: "+err.Error())
+		return
+	}
+	verification, err := dcs.VerifyZipChecksums(reader, checksums)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", "The archive cannot be read: "+err.Error())
+		return
+	}
+
+	var signatureURL, signingKeyURL string
+	if dmc.Signature != "" {
+		signatureURL, signingKeyURL = dm.GetChecksumsSignatureURL(), dm.GetSigningKeyURL()
+	}
+	ctx.JSON(http.StatusOK, &api.CatalogVerificationV5{
+		Valid:                 verification.Valid(),
+		Matched:               verification.Matched,
+		Mismatched:            verification.Mismatched,
+		Missing:               verification.Missing,
+		Extra:                 verification.Extra,
+		ChecksumsURL:          dm.GetChecksumsURL(),
+		ChecksumsSignatureURL: signatureURL,
+		SigningKey:            dmc.SigningKey,
+		SigningKeyURL:         signingKeyURL,
+	})
+}
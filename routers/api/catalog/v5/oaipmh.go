@@ -0,0 +1,382 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package v5
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/oaipmh"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// Sets of the records by language, e.g. lang:en, and by subject, e.g. subject:Aligned_Bible
+const (
+	oaiLanguageSet = "lang"
+	oaiSubjectSet  = "subject"
+)
+
+func oaiBaseURL() string {
+	return setting.AppURL + "api/catalog/v5/oai"
+}
+
+// oaiIdentifier returns the OAI identifier of a catalog entry, e.g. oai:git.door43.org:unfoldingWord/en_ult/v5
+func oaiIdentifier(dm *models.Door43Metadata) string {
+	return fmt.Sprintf("oai:%s:%s/%s", setting.Domain, dm.Repo.FullName(), dm.BranchOrTag)
+}
+
+// parseOAIIdentifier returns the owner, repo and tag of an OAI identifier of a catalog entry
+func parseOAIIdentifier(identifier string) (owner, repo, tag string, ok bool) {
+	prefix := "oai:" + setting.Domain + ":"
+	if !strings.HasPrefix(identifier, prefix) {
+		return "", "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(identifier, prefix), "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// oaiSetSpec returns the spec of the set of a language or a subject, whose spaces aren't allowed in a spec
+func oaiSetSpec(set, value string) string {
+	return set + ":" + strings.ReplaceAll(value, " ", "_")
+}
+
+// parseOAISet returns the languages and subjects of the catalog entries of a set
+func parseOAISet(spec string) (languages, subjects []string, ok bool) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) == 1 {
+		return nil, nil, spec == "" || spec == oaiLanguageSet || spec == oaiSubjectSet
+	}
+	value := strings.ReplaceAll(parts[1], "_", " ")
+	switch parts[0] {
+	case oaiLanguageSet:
+		return []string{value}, nil, true
+	case oaiSubjectSet:
+		return nil, []string{value}, true
+	}
+	return nil, nil, false
+}
+
+// toOAIHeader converts a catalog entry to the header of its record
+func toOAIHeader(dm *models.Door43Metadata) *oaipmh.Header {
+	header := &oaipmh.Header{
+		Identifier: oaiIdentifier(dm),
+		Datestamp:  oaipmh.FormatDatestamp(dm.UpdatedUnix.AsTime()),
+	}
+	if lang := dcs.GetDublinCoreString(dm.Metadata, "language", "identifier"); lang != "" {
+		header.SetSpecs = append(header.SetSpecs, oaiSetSpec(oaiLanguageSet, strings.ToLower(lang)))
+	}
+	if subject := dcs.GetDublinCoreString(dm.Metadata, "subject"); subject != "" {
+		header.SetSpecs = append(header.SetSpecs, oaiSetSpec(oaiSubjectSet, subject))
+	}
+	return header
+}
+
+// toOAIRecord converts a catalog entry to its record, identified by the URLs of its release and of its zip
+func toOAIRecord(dm *models.Door43Metadata) *oaipmh.Record {
+	htmlURL := dm.Repo.HTMLURL()
+	if dm.Release != nil {
+		htmlURL = dm.Release.HTMLURL()
+	}
+	return &oaipmh.Record{
+		Header:   toOAIHeader(dm),
+		Metadata: &oaipmh.Metadata{DC: oaipmh.NewDublinCore(dm.Metadata, htmlURL, dm.GetZipballURL())},
+	}
+}
+
+// getOAIEntry returns the production release of the catalog with an OAI identifier
+func getOAIEntry(identifier string) (*models.Door43Metadata, *oaipmh.Error, error) {
+	notExist := &oaipmh.Error{Code: oaipmh.ErrorIDDoesNotExist, Message: fmt.Sprintf("No matching identifier: %s", identifier)}
+	owner, name, tag, ok := parseOAIIdentifier(identifier)
+	if !ok {
+		return nil, notExist, nil
+	}
+	repo, err := models.GetRepositoryByOwnerAndName(owner, name)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, notExist, nil
+		}
+		return nil, nil, err
+	}
+	if repo.IsPrivate || repo.IsArchived {
+		return nil, notExist, nil
+	}
+	dm, err := models.GetDoor43MetadataByRepoIDAndTagName(repo.ID, tag)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) || models.IsErrDoor43MetadataNotExist(err) {
+			return nil, notExist, nil
+		}
+		return nil, nil, err
+	}
+	if dm.ReleaseID == 0 || dm.Stage != models.StageProd {
+		return nil, notExist, nil
+	}
+	dm.Repo = repo
+	return dm, nil, dm.LoadAttributes()
+}
+
+func oaiIdentify(response *oaipmh.Response) (*oaipmh.Error, error) {
+	earliest, err := models.GetCatalogEarliestChange()
+	if err != nil {
+		return nil, err
+	}
+	identify := &oaipmh.Identify{
+		RepositoryName:    setting.AppName + " Catalog",
+		BaseURL:           oaiBaseURL(),
+		ProtocolVersion:   "2.0",
+		EarliestDatestamp: oaipmh.FormatDatestamp(earliest.AsTime()),
+		DeletedRecord:     "no",
+		Granularity:       oaipmh.Granularity,
+	}
+	if setting.DCS.OAIAdminEmail != "" {
+		identify.AdminEmails = []string{setting.DCS.OAIAdminEmail}
+	} else if setting.MailService != nil && setting.MailService.FromEmail != "" {
+		identify.AdminEmails = []string{setting.MailService.FromEmail}
+	}
+	response.Identify = identify
+	return nil, nil
+}
+
+func oaiListMetadataFormats(response *oaipmh.Response, args url.Values) (*oaipmh.Error, error) {
+	if identifier := args.Get("identifier"); identifier != "" {
+		if _, oaiErr, err := getOAIEntry(identifier); oaiErr != nil || err != nil {
+			return oaiErr, err
+		}
+	}
+	response.ListMetadataFormats = &oaipmh.ListMetadataFormats{Formats: []*oaipmh.MetadataFormat{oaipmh.DCFormat}}
+	return nil, nil
+}
+
+func oaiListSets(response *oaipmh.Response, args url.Values) (*oaipmh.Error, error) {
+	if args.Get("resumptionToken") != "" {
+		return &oaipmh.Error{Code: oaipmh.ErrorBadResumptionToken, Message: "The list of sets is always complete"}, nil
+	}
+	sets := []*oaipmh.Set{
+		{Spec: oaiLanguageSet, Name: "By language"},
+		{Spec: oaiSubjectSet, Name: "By subject"},
+	}
+	for _, set := range []struct {
+		spec string
+		kind string
+	}{
+		{oaiLanguageSet, models.CatalogStatsKindLanguage},
+		{oaiSubjectSet, models.CatalogStatsKindSubject},
+	} {
+		counts, err := models.GetCatalogCounts(models.StageProd, set.kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, count := range counts {
+			if count.Value != "" {
+				sets = append(sets, &oaipmh.Set{Spec: oaiSetSpec(set.spec, count.Value), Name: count.Value})
+			}
+		}
+	}
+	response.ListSets = &oaipmh.ListSets{Sets: sets}
+	return nil, nil
+}
+
+// getOAIListToken returns the state of a list from its resumption token, or from the arguments of its first request
+func getOAIListToken(args url.Values) (*oaipmh.ResumptionToken, *oaipmh.Error) {
+	if token := args.Get("resumptionToken"); token != "" {
+		t, err := oaipmh.DecodeResumptionToken(token)
+		if err != nil {
+			return nil, &oaipmh.Error{Code: oaipmh.ErrorBadResumptionToken, Message: fmt.Sprintf("Invalid resumptionToken: %s", token)}
+		}
+		return t, nil
+	}
+
+	t := &oaipmh.ResumptionToken{
+		MetadataPrefix: args.Get("metadataPrefix"),
+		Set:            args.Get("set"),
+	}
+	from, until := args.Get("from"), args.Get("until")
+	if from != "" && until != "" && len(from) != len(until) {
+		return nil, &oaipmh.Error{Code: oaipmh.ErrorBadArgument, Message: "from and until have different granularities"}
+	}
+	if from != "" {
+		date, err := oaipmh.ParseDatestamp(from, false)
+		if err != nil {
+			return nil, &oaipmh.Error{Code: oaipmh.ErrorBadArgument, Message: fmt.Sprintf("Invalid from: %s", from)}
+		}
+		t.From = date.Unix()
+	}
+	if until != "" {
+		date, err := oaipmh.ParseDatestamp(until, true)
+		if err != nil {
+			return nil, &oaipmh.Error{Code: oaipmh.ErrorBadArgument, Message: fmt.Sprintf("Invalid until: %s", until)}
+		}
+		t.Until = date.Unix()
+		if t.Until < t.From {
+			return nil, &oaipmh.Error{Code: oaipmh.ErrorBadArgument, Message: "until is before from"}
+		}
+	}
+	return t, nil
+}
+
+// oaiList lists the headers, or the records, of the production releases of the catalog in the order they changed,
+// a page at a time
+func oaiList(response *oaipmh.Response, args url.Values, records bool) (*oaipmh.Error, error) {
+	t, oaiErr := getOAIListToken(args)
+	if oaiErr != nil {
+		return oaiErr, nil
+	}
+	if t.MetadataPrefix != oaipmh.MetadataPrefixDC {
+		return &oaipmh.Error{Code: oaipmh.ErrorCannotDisseminateFormat, Message: fmt.Sprintf("Unsupported metadataPrefix: %s", t.MetadataPrefix)}, nil
+	}
+	languages, subjects, ok := parseOAISet(t.Set)
+	if !ok {
+		return &oaipmh.Error{Code: oaipmh.ErrorNoRecordsMatch, Message: fmt.Sprintf("No such set: %s", t.Set)}, nil
+	}
+
+	dms, count, err := models.GetCatalogChanges(&models.CatalogChangesOptions{
+		Languages: languages,
+		Subjects:  subjects,
+		From:      timeutil.TimeStamp(t.From),
+		Until:     timeutil.TimeStamp(t.Until),
+		AfterUnix: timeutil.TimeStamp(t.AfterUnix),
+		AfterID:   t.AfterID,
+		PageSize:  setting.API.MaxResponseItems,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(dms) == 0 && t.Cursor == 0 {
+		return &oaipmh.Error{Code: oaipmh.ErrorNoRecordsMatch, Message: "No records match the request"}, nil
+	}
+
+	// The last response of an incomplete list has an empty resumption token
+	var token *oaipmh.ResumptionTokenElement
+	if t.Cursor+len(dms) < int(count) && len(dms) > 0 {
+		last := dms[len(dms)-1]
+		next := *t
+		next.AfterUnix = int64(last.UpdatedUnix)
+		next.AfterID = last.ID
+		next.Cursor = t.Cursor + len(dms)
+		token = &oaipmh.ResumptionTokenElement{CompleteListSize: count, Cursor: t.Cursor, Token: next.Encode()}
+	} else if t.Cursor > 0 {
+		token = &oaipmh.ResumptionTokenElement{CompleteListSize: count, Cursor: t.Cursor}
+	}
+
+	if records {
+		list := &oaipmh.ListRecords{ResumptionToken: token}
+		for _, dm := range dms {
+			list.Records = append(list.Records, toOAIRecord(dm))
+		}
+		response.ListRecords = list
+	} else {
+		list := &oaipmh.ListIdentifiers{ResumptionToken: token}
+		for _, dm := range dms {
+			list.Headers = append(list.Headers, toOAIHeader(dm))
+		}
+		response.ListIdentifiers = list
+	}
+	return nil, nil
+}
+
+func oaiGetRecord(response *oaipmh.Response, args url.Values) (*oaipmh.Error, error) {
+	dm, oaiErr, err := getOAIEntry(args.Get("identifier"))
+	if oaiErr != nil || err != nil {
+		return oaiErr, err
+	}
+	if prefix := args.Get("metadataPrefix"); prefix != oaipmh.MetadataPrefixDC {
+		return &oaipmh.Error{Code: oaipmh.ErrorCannotDisseminateFormat, Message: fmt.Sprintf("Unsupported metadataPrefix: %s", prefix)}, nil
+	}
+	response.GetRecord = &oaipmh.GetRecord{Record: toOAIRecord(dm)}
+	return nil, nil
+}
+
+// OAIPMH handles the OAI-PMH requests of harvesters
+func OAIPMH(ctx *context.APIContext) {
+	// swagger:operation GET /v5/oai v5 v5OAIPMH
+	// ---
+	// summary: OAI-PMH 2.0 provider of the production releases of the catalog, in the oai_dc (Dublin Core) format
+	// produces:
+	// - text/xml
+	// parameters:
+	// - name: verb
+	//   in: query
+	//   description: '"Identify", "ListMetadataFormats", "ListSets", "ListIdentifiers", "ListRecords" or "GetRecord"'
+	//   type: string
+	//   required: true
+	// - name: identifier
+	//   in: query
+	//   description: identifier of a record, e.g. oai:git.door43.org:unfoldingWord/en_ult/v5
+	//   type: string
+	// - name: metadataPrefix
+	//   in: query
+	//   description: metadata format of the records, only "oai_dc" is supported
+	//   type: string
+	// - name: from
+	//   in: query
+	//   description: only list the records that changed since this day (YYYY-MM-DD) or time (YYYY-MM-DDThh:mm:ssZ)
+	//   type: string
+	// - name: until
+	//   in: query
+	//   description: only list the records that changed until this day (YYYY-MM-DD) or time (YYYY-MM-DDThh:mm:ssZ)
+	//   type: string
+	// - name: set
+	//   in: query
+	//   description: only list the records of this set, a language such as "lang:en" or a subject such as "subject:Aligned_Bible"
+	//   type: string
+	// - name: resumptionToken
+	//   in: query
+	//   description: token of the next page of a list, as returned in the previous page
+	//   type: string
+	// responses:
+	//   "200":
+	//     description: "OAI-PMH response, including its errors"
+
+	if err := ctx.Req.ParseForm(); err != nil {
+		ctx.Error(http.StatusBadRequest, "ParseForm", err)
+		return
+	}
+	args := ctx.Req.Form
+	response := oaipmh.NewResponse(oaipmh.NewRequest(oaiBaseURL(), args))
+
+	oaiErr := oaipmh.CheckArguments(args)
+	var err error
+	if oaiErr == nil {
+		switch args.Get("verb") {
+		case oaipmh.VerbIdentify:
+			oaiErr, err = oaiIdentify(response)
+		case oaipmh.VerbListMetadataFormats:
+			oaiErr, err = oaiListMetadataFormats(response, args)
+		case oaipmh.VerbListSets:
+			oaiErr, err = oaiListSets(response, args)
+		case oaipmh.VerbListIdentifiers:
+			oaiErr, err = oaiList(response, args, false)
+		case oaipmh.VerbListRecords:
+			oaiErr, err = oaiList(response, args, true)
+		case oaipmh.VerbGetRecord:
+			oaiErr, err = oaiGetRecord(response, args)
+		}
+	}
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, args.Get("verb"), err)
+		return
+	}
+	if oaiErr != nil {
+		response.SetError(oaiErr)
+	}
+
+	content, err := response.Marshal()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Marshal", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "text/xml;charset=utf-8")
+	if _, err := ctx.Write(content); err != nil {
+		ctx.Error(http.StatusInternalServerError, "Unable to write OAI-PMH response", err)
+	}
+}
@@ -0,0 +1,433 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package v5
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/opds"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Versions of OPDS the feeds are available in, as in their URLs
+const (
+	opdsVersion1 = "1.2"
+	opdsVersion2 = "2.0"
+)
+
+// opdsVersion checks the OPDS version of the URL of the feed
+func opdsVersion() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if version := ctx.Params("version"); version != opdsVersion1 && version != opdsVersion2 {
+			ctx.NotFound()
+		}
+	}
+}
+
+// opdsBaseURL returns the URL of the root feed of the OPDS version of the request
+func opdsBaseURL(ctx *context.APIContext) string {
+	return setting.AppURL + "api/catalog/v5/opds/" + ctx.Params("version")
+}
+
+// opdsFeedType returns the media type of a feed of the OPDS version of the request
+func opdsFeedType(ctx *context.APIContext, acquisition bool) string {
+	if ctx.Params("version") == opdsVersion2 {
+		return opds.MediaTypeJSON
+	}
+	if acquisition {
+		return opds.MediaTypeAcquisition
+	}
+	return opds.MediaTypeNavigation
+}
+
+// opdsStartLinks returns the links to a feed itself and to the root feed
+func opdsStartLinks(ctx *context.APIContext, self string, acquisition bool) []*opds.Link {
+	return []*opds.Link{
+		{Rel: opds.RelSelf, Href: self, Type: opdsFeedType(ctx, acquisition)},
+		{Rel: opds.RelStart, Href: opdsBaseURL(ctx), Type: opdsFeedType(ctx, false)},
+	}
+}
+
+// writeOPDSFeed writes a feed in the OPDS version of the request
+func writeOPDSFeed(ctx *context.APIContext, feed *opds.Feed) {
+	var content []byte
+	var err error
+	if ctx.Params("version") == opdsVersion2 {
+		content, err = feed.JSON()
+		ctx.Resp.Header().Set("Content-Type", opds.MediaTypeJSON+";charset=utf-8")
+	} else {
+		content, err = feed.Atom()
+		ctx.Resp.Header().Set("Content-Type", feed.MediaType()+";charset=utf-8")
+	}
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Unable to render OPDS feed", err)
+		return
+	}
+	if _, err := ctx.Write(content); err != nil {
+		ctx.Error(http.StatusInternalServerError, "Unable to write OPDS feed", err)
+	}
+}
+
+// GetOPDSRoot gets the root navigation feed of the catalog
+func GetOPDSRoot(ctx *context.APIContext) {
+	// swagger:operation GET /v5/opds/{version} v5 v5GetOPDSRoot
+	// ---
+	// summary: Root OPDS navigation feed of the catalog, linking to the feeds by language and by subject
+	// produces:
+	// - application/atom+xml
+	// - application/opds+json
+	// parameters:
+	// - name: version
+	//   in: path
+	//   description: version of OPDS, "1.2" (Atom) or "2.0" (JSON)
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: "OPDS navigation feed"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	base := opdsBaseURL(ctx)
+	now := time.Now()
+	entry := func(id, title, summary, href string, acquisition bool) *opds.Entry {
+		return &opds.Entry{
+			ID:      base + "/" + id,
+			Title:   title,
+			Summary: summary,
+			Updated: now,
+			Links: []*opds.Link{
+				{Rel: opds.RelSubsection, Href: href, Type: opdsFeedType(ctx, acquisition)},
+			},
+		}
+	}
+	writeOPDSFeed(ctx, &opds.Feed{
+		ID:      base,
+		Title:   setting.AppName + " Catalog",
+		Updated: now,
+		Links:   opdsStartLinks(ctx, base, false),
+		Entries: []*opds.Entry{
+			entry("languages", "By Language", "Resources of the catalog by language", base+"/languages", false),
+			entry("subjects", "By Subject", "Resources of the catalog by subject", base+"/subjects", false),
+			entry("search", "All Resources", "All the resources of the catalog, by language and subject", base+"/search", true),
+		},
+	})
+}
+
+// getOPDSCounts writes the navigation feed at a path of the catalog by a field, each entry linking to the
+// acquisition feed of the catalog entries having one of its values
+func getOPDSCounts(ctx *context.APIContext, path, kind, param, title string) {
+	stage := models.StageProd
+	query := url.Values{}
+	if stageStr := ctx.Query("stage"); stageStr != "" {
+		var ok bool
+		stage, ok = models.StageMap[stageStr]
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid stage: \"%s\"", stageStr))
+			return
+		}
+		query.Set("stage", stageStr)
+	}
+
+	counts, err := models.GetCatalogCounts(stage, kind)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCatalogCounts", err)
+		return
+	}
+
+	base := opdsBaseURL(ctx)
+	self := base + "/" + path
+	now := time.Now()
+	feed := &opds.Feed{
+		ID:      self,
+		Title:   title,
+		Updated: now,
+		Links: append(opdsStartLinks(ctx, self, false),
+			&opds.Link{Rel: opds.RelUp, Href: base, Type: opdsFeedType(ctx, false)}),
+		Entries: make([]*opds.Entry, 0, len(counts)),
+	}
+	for _, count := range counts {
+		if count.Value == "" {
+			continue
+		}
+		query.Set(param, count.Value)
+		href := base + "/search?" + query.Encode()
+		feed.Entries = append(feed.Entries, &opds.Entry{
+			ID:      href,
+			Title:   count.Value,
+			Summary: fmt.Sprintf("%d resources", count.Count),
+			Updated: now,
+			Links: []*opds.Link{
+				{Rel: opds.RelSubsection, Href: href, Type: opdsFeedType(ctx, true), Count: count.Count},
+			},
+		})
+	}
+	writeOPDSFeed(ctx, feed)
+}
+
+// GetOPDSLanguages gets the navigation feed of the languages of the catalog
+func GetOPDSLanguages(ctx *context.APIContext) {
+	// swagger:operation GET /v5/opds/{version}/languages v5 v5GetOPDSLanguages
+	// ---
+	// summary: OPDS navigation feed of the languages of the catalog, each linking to the acquisition feed of its resources
+	// produces:
+	// - application/atom+xml
+	// - application/opds+json
+	// parameters:
+	// - name: version
+	//   in: path
+	//   description: version of OPDS, "1.2" (Atom) or "2.0" (JSON)
+	//   type: string
+	//   required: true
+	// - name: stage
+	//   in: query
+	//   description: 'specifies which release stage the latest entry of each repo is listed at:
+	//                "prod" - the production releases (default);
+	//                "preprod" - the pre-production release if it exists instead of the production release;
+	//                "draft" - the draft release if it exists instead of pre-production or production release;
+	//                "latest" - the default branch (e.g. master) if it is a valid RC instead of the above'
+	//   type: string
+	// responses:
+	//   "200":
+	//     description: "OPDS navigation feed"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	getOPDSCounts(ctx, "languages", models.CatalogStatsKindLanguage, "lang", "Languages")
+}
+
+// GetOPDSSubjects gets the navigation feed of the subjects of the catalog
+func GetOPDSSubjects(ctx *context.APIContext) {
+	// swagger:operation GET /v5/opds/{version}/subjects v5 v5GetOPDSSubjects
+	// ---
+	// summary: OPDS navigation feed of the subjects of the catalog, each linking to the acquisition feed of its resources
+	// produces:
+	// - application/atom+xml
+	// - application/opds+json
+	// parameters:
+	// - name: version
+	//   in: path
+	//   description: version of OPDS, "1.2" (Atom) or "2.0" (JSON)
+	//   type: string
+	//   required: true
+	// - name: stage
+	//   in: query
+	//   description: 'specifies which release stage the latest entry of each repo is listed at:
+	//                "prod" - the production releases (default);
+	//                "preprod" - the pre-production release if it exists instead of the production release;
+	//                "draft" - the draft release if it exists instead of pre-production or production release;
+	//                "latest" - the default branch (e.g. master) if it is a valid RC instead of the above'
+	//   type: string
+	// responses:
+	//   "200":
+	//     description: "OPDS navigation feed"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	getOPDSCounts(ctx, "subjects", models.CatalogStatsKindSubject, "subject", "Subjects")
+}
+
+// toOPDSEntry converts a catalog entry to a publication of an acquisition feed, with links to its zip and to the
+// EPUBs attached to its release
+func toOPDSEntry(dm *models.Door43Metadata) *opds.Entry {
+	entry := &opds.Entry{
+		ID:        dm.APIURLV5(),
+		Title:     dcs.GetDublinCoreString(dm.Metadata, "title"),
+		Summary:   dcs.GetDublinCoreString(dm.Metadata, "description"),
+		Updated:   dm.UpdatedUnix.AsTime(),
+		Published: dm.ReleaseDateUnix.AsTime(),
+		Language:  dcs.GetDublinCoreString(dm.Metadata, "language", "identifier"),
+		Publisher: dcs.GetDublinCoreString(dm.Metadata, "publisher"),
+	}
+	if entry.Title == "" {
+		entry.Title = dm.Repo.Name
+	}
+	if dm.BranchOrTag != "" {
+		entry.Title += " (" + dm.BranchOrTag + ")"
+	}
+	if entry.Publisher == "" {
+		entry.Publisher = dm.Repo.OwnerName
+	}
+	if subject := dcs.GetDublinCoreString(dm.Metadata, "subject"); subject != "" {
+		entry.Subjects = []string{subject}
+	}
+
+	htmlURL := dm.Repo.HTMLURL()
+	if dm.Release != nil {
+		htmlURL = dm.Release.HTMLURL()
+	}
+	entry.Links = append(entry.Links,
+		&opds.Link{Rel: opds.RelAlternate, Href: htmlURL, Type: opds.MediaTypeHTML},
+		&opds.Link{Rel: opds.RelAcquisition, Href: dm.GetZipballURL(), Type: opds.MediaTypeZip, Title: "Zip"})
+	if dm.Release != nil {
+		for _, attach := range dm.Release.Attachments {
+			if strings.HasSuffix(strings.ToLower(attach.Name), ".epub") {
+				entry.Links = append(entry.Links,
+					&opds.Link{Rel: opds.RelAcquisition, Href: attach.DownloadURL(), Type: opds.MediaTypeEPUB, Title: attach.Name})
+			}
+		}
+	}
+	return entry
+}
+
+// opdsPageLinks returns the links to the first, previous, next and last pages of a paginated feed
+func opdsPageLinks(ctx *context.APIContext, self *url.URL, total int64, pageSize, page int) []*opds.Link {
+	if pageSize <= 0 {
+		return nil
+	}
+	lastPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+	pageLink := func(rel string, p int) *opds.Link {
+		u := *self
+		query := u.Query()
+		query.Set("page", strconv.Itoa(p))
+		u.RawQuery = query.Encode()
+		return &opds.Link{Rel: rel, Href: u.String(), Type: opdsFeedType(ctx, true)}
+	}
+	var links []*opds.Link
+	if page > 1 {
+		links = append(links, pageLink(opds.RelFirst, 1), pageLink(opds.RelPrevious, page-1))
+	}
+	if page < lastPage {
+		links = append(links, pageLink(opds.RelNext, page+1), pageLink(opds.RelLast, lastPage))
+	}
+	return links
+}
+
+// SearchOPDS searches the catalog, returning the entries as an acquisition feed
+func SearchOPDS(ctx *context.APIContext) {
+	// swagger:operation GET /v5/opds/{version}/search v5 v5SearchOPDS
+	// ---
+	// summary: OPDS acquisition feed of a catalog search, linking to the zip of each entry and to the EPUBs attached to its release
+	// produces:
+	// - application/atom+xml
+	// - application/opds+json
+	// parameters:
+	// - name: version
+	//   in: path
+	//   description: version of OPDS, "1.2" (Atom) or "2.0" (JSON)
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: keyword(s). Can use multiple `q=<keyword>`s or commas for more than one keyword
+	//   type: string
+	// - name: owner
+	//   in: query
+	//   description: search only for entries with the given owner name(s).
+	//   type: string
+	// - name: repo
+	//   in: query
+	//   description: search only for entries with the given repo name(s).
+	//   type: string
+	// - name: tag
+	//   in: query
+	//   description: search only for entries with the given release tag(s)
+	//   type: string
+	// - name: lang
+	//   in: query
+	//   description: search only for entries with the given language(s)
+	//   type: string
+	// - name: stage
+	//   in: query
+	//   description: 'specifies which release stage to be return of these stages:
+	//                "prod" - return only the production releases (default);
+	//                "preprod" - return the pre-production release if it exists instead of the production release;
+	//                "draft" - return the draft release if it exists instead of pre-production or production release;
+	//                "latest" -return the default branch (e.g. master) if it is a valid RC instead of the above'
+	//   type: string
+	// - name: subject
+	//   in: query
+	//   description: search only for entries with the given subject(s). Must match the entire string (case insensitive)
+	//   type: string
+	// - name: checkingLevel
+	//   in: query
+	//   description: search only for entries with the given checking level(s). Can be 1, 2 or 3
+	//   type: string
+	// - name: book
+	//   in: query
+	//   description: search only for entries with the given book(s) (project ids)
+	//   type: string
+	// - name: sort
+	//   in: query
+	//   description: sort repos alphanumerically by attribute. Supported values are
+	//                "subject", "title", "tag", "version", "released", "lang", "releases", "stars", "forks".
+	//                Default is by "language", "subject" and then "tag"
+	//   type: string
+	// - name: order
+	//   in: query
+	//   description: sort order, either "asc" (ascending) or "desc" (descending).
+	//                Default is "asc", ignored if "sort" is not specified.
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     description: "OPDS acquisition feed"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := getSearchCatalogOptions(ctx)
+	if opts == nil {
+		return
+	}
+	dms, count, err := models.SearchCatalog(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchCatalog", err)
+		return
+	}
+
+	base := opdsBaseURL(ctx)
+	self, err := url.Parse(base + "/search?" + ctx.Req.URL.RawQuery)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Parse", err)
+		return
+	}
+	feed := &opds.Feed{
+		ID:           self.String(),
+		Title:        "Resources",
+		Acquisition:  true,
+		Links:        opdsStartLinks(ctx, self.String(), true),
+		Entries:      make([]*opds.Entry, 0, len(dms)),
+		Total:        count,
+		ItemsPerPage: opts.PageSize,
+		Page:         opts.Page,
+	}
+	feed.Links = append(feed.Links, &opds.Link{Rel: opds.RelUp, Href: base, Type: opdsFeedType(ctx, false)})
+	feed.Links = append(feed.Links, opdsPageLinks(ctx, self, count, opts.PageSize, opts.Page)...)
+	for _, dm := range dms {
+		entry := toOPDSEntry(dm)
+		if entry.Updated.After(feed.Updated) {
+			feed.Updated = entry.Updated
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now()
+	}
+
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	writeOPDSFeed(ctx, feed)
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package v5
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/door43metadata"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// PreviewCatalogEntry Preview the catalog entry of a ref or pull request of a repo without saving it
+func PreviewCatalogEntry(ctx *context.APIContext) {
+	// swagger:operation GET /v5/preview/{owner}/{repo} v5 v5PreviewEntry
+	// ---
+	// summary: Preview the catalog entry a branch, tag, commit or pull request would have, with the validation
+	//          errors of its manifest.yaml and the changes of its metadata from the production entry
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: name of the owner
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: branch, tag or commit SHA to preview, the default branch if neither it nor pull is given
+	//   type: string
+	// - name: pull
+	//   in: query
+	//   description: index of the pull request whose head to preview
+	//   type: integer
+	//   format: int64
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogPreviewV5"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !ctx.Repo.CanRead(models.UnitTypeCode) {
+		ctx.NotFound()
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(ctx.Repo.Repository.RepoPath())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
+		return
+	}
+	defer gitRepo.Close()
+
+	var preview *door43metadata.Preview
+	if index := ctx.QueryInt64("pull"); index > 0 {
+		if !ctx.Repo.CanRead(models.UnitTypePullRequests) {
+			ctx.NotFound()
+			return
+		}
+		pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, index)
+		if err != nil {
+			if models.IsErrPullRequestNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+			}
+			return
+		}
+		preview, err = door43metadata.PreviewDoor43MetadataForPullRequest(pr, gitRepo)
+	} else {
+		ref := ctx.Query("ref")
+		if ref == "" {
+			ref = ctx.Repo.Repository.DefaultBranch
+		}
+		preview, err = door43metadata.PreviewDoor43Metadata(ctx.Repo.Repository, gitRepo, ref, "")
+	}
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "PreviewDoor43Metadata", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCatalogPreviewV5(preview, ctx.Repo.AccessMode))
+}
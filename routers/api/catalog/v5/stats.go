@@ -0,0 +1,144 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package v5
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+var catalogStatsKinds = []string{
+	models.CatalogStatsKindTotal,
+	models.CatalogStatsKindLanguage,
+	models.CatalogStatsKindSubject,
+	models.CatalogStatsKindCheckingLevel,
+}
+
+// GetStats gets the statistics of the catalog by language, subject and checking level
+func GetStats(ctx *context.APIContext) {
+	// swagger:operation GET /v5/stats v5 v5GetStats
+	// ---
+	// summary: Catalog statistics by language, subject and checking level, with the releases per month and the stale entries
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: stage
+	//   in: query
+	//   description: 'specifies which release stage the latest entry of each repo is counted at:
+	//                "prod" - the production releases (default);
+	//                "preprod" - the pre-production release if it exists instead of the production release;
+	//                "draft" - the draft release if it exists instead of pre-production or production release;
+	//                "latest" - the default branch (e.g. master) if it is a valid RC instead of the above'
+	//   type: string
+	// - name: months
+	//   in: query
+	//   description: number of months, including the current one, to count the releases of (default 12)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: maximum number of stale entries to return, all of them being counted (default 50)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogStatsV5"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	stage := models.StageProd
+	if stageStr := ctx.Query("stage"); stageStr != "" {
+		var ok bool
+		stage, ok = models.StageMap[stageStr]
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid stage: \"%s\"", stageStr))
+			return
+		}
+	}
+	limit := ctx.QueryInt("limit")
+	if limit <= 0 {
+		limit = 50
+	}
+
+	stats, err := models.GetCatalogStats(&models.CatalogStatsOptions{
+		Stage:         stage,
+		Months:        ctx.QueryInt("months"),
+		StaleAfter:    setting.DCS.CatalogStaleAfter,
+		StalePageSize: limit,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCatalogStats", err)
+		return
+	}
+	result, err := convert.ToCatalogStatsV5(stats, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToCatalogStatsV5", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetStatsHistory gets the recorded number of production catalog entries of a kind over time
+func GetStatsHistory(ctx *context.APIContext) {
+	// swagger:operation GET /v5/stats/history v5 v5GetStatsHistory
+	// ---
+	// summary: Number of production catalog entries in total, or by language, subject or checking level, as recorded each day
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: kind
+	//   in: query
+	//   description: '"total" (default), "language", "subject" or "checking_level"'
+	//   type: string
+	// - name: value
+	//   in: query
+	//   description: the language, subject or checking level, e.g. "en", or all of them if not given
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show the statistics recorded after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CatalogStatsSnapshotList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	kind := ctx.Query("kind")
+	if kind == "" {
+		kind = models.CatalogStatsKindTotal
+	}
+	valid := false
+	for _, k := range catalogStatsKinds {
+		valid = valid || k == kind
+	}
+	if !valid {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid kind: \"%s\"", kind))
+		return
+	}
+	_, since, err := utils.GetQueryBeforeSince(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	snapshots, err := models.GetCatalogStatsHistory(kind, ctx.Query("value"), timeutil.TimeStamp(since))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCatalogStatsHistory", err)
+		return
+	}
+	result := make([]*api.CatalogStatsSnapshot, len(snapshots))
+	for i, snapshot := range snapshots {
+		result[i] = convert.ToCatalogStatsSnapshot(snapshot)
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -723,6 +723,7 @@ func Routes() *web.Route {
 			m.Get("/issues/search", repo.SearchIssues)
 
 			m.Post("/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
+			m.Post("/import", reqToken(), repo.CreateFromArchive) // DCS Customizations
 
 			m.Group("/{username}/{reponame}", func() {
 				m.Combo("").Get(reqAnyRepoReader(), repo.Get).
@@ -894,6 +895,7 @@ func Routes() *web.Route {
 								Patch(reqToken(), reqRepoWriter(models.UnitTypeReleases), bind(api.EditAttachmentOptions{}), repo.EditReleaseAttachment).
 								Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseAttachment)
 						})
+						m.Post("/artifacts", reqToken(), reqSiteAdmin(), repo.RegenerateReleaseArtifacts) // DCS Customizations
 					})
 					m.Group("/tags", func() {
 						m.Combo("/{tag}").
@@ -965,6 +967,19 @@ func Routes() *web.Route {
 						m.Delete("", bind(api.DeleteFileOptions{}), repo.DeleteFile)
 					}, reqRepoWriter(models.UnitTypeCode), reqToken())
 				}, reqRepoReader(models.UnitTypeCode))
+				/*** DCS Customizations ***/
+				m.Group("/manifest/generate", func() {
+					m.Get("", repo.GenerateManifest)
+					m.Post("", reqToken(), reqRepoWriter(models.UnitTypeCode), bind(api.GenerateManifestOptions{}), repo.CreateGeneratedManifest)
+				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/scrub", func() {
+					m.Post("", bind(api.ScrubOptions{}), repo.Scrub)
+					m.Get("/logs", repo.ListScrubLogs)
+				}, reqToken(), reqOwner())
+				m.Get("/reference", reqRepoReader(models.UnitTypeCode), repo.SearchReference)
+				m.Post("/translate", reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.TranslateRepoOption{}), repo.Translate)
+				m.Post("/import", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.ImportArchive)
+				/*** END DCS Customizations ***/
 				m.Get("/signing-key.gpg", misc.SigningKey)
 				m.Group("/topics", func() {
 					m.Combo("").Get(repo.ListTopics).
@@ -1020,6 +1035,10 @@ func Routes() *web.Route {
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
 			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
+			/*** DCS Customizations ***/
+			m.Combo("/scrub_rules", reqToken(), reqOrgOwnership()).Get(org.GetScrubRules).
+				Put(bind(api.EditScrubRulesOption{}), org.EditScrubRules)
+			/*** END DCS Customizations ***/
 		}, orgAssignment(true))
 		m.Group("/teams/{teamid}", func() {
 			m.Combo("").Get(org.GetTeam).
@@ -1073,7 +1092,7 @@ func Routes() *web.Route {
 		})
 
 		/*** DCS Customizations ***/
-		m.Post("/yaml", bind(misc.YamlOption{}), misc.Yaml)
+		m.Post("/yaml", bind(api.YamlOption{}), misc.Yaml)
 		/*** END DCS Customizations ***/
 	}, sudo())
 
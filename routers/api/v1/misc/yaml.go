@@ -7,35 +7,69 @@
 package misc
 
 import (
+	"net/http"
+
 	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/yaml"
 )
 
-// YamlOption options for YAML
-type YamlOption struct {
-	Text string
-}
-
-// Yaml https://github.com/gogits/go-gogs-client/wiki/Miscellaneous#render-an-arbitrary-markdown-document
+// Yaml render a YAML document as HTML tables or JSON
 func Yaml(ctx *context.APIContext) {
-	form := web.GetForm(ctx).(*YamlOption)
+	// swagger:operation POST /yaml miscellaneous renderYaml
+	// ---
+	// summary: Render a YAML document as HTML tables or as JSON
+	// description: Lists of scalars, multi-document streams, anchors, aliases and merge keys are supported.
+	//   With the json mode the keys keep their order and a multi-document stream is an array of its documents.
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/YamlOption"
+	// consumes:
+	// - application/json
+	// produces:
+	// - text/html
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/YamlRender"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.YamlOption)
 
 	if ctx.HasAPIError() {
-		ctx.Error(422, "", ctx.GetErrMsg())
+		ctx.Error(http.StatusUnprocessableEntity, "", ctx.GetErrMsg())
+		return
+	}
+
+	if form.Mode == "json" {
+		rendered, err := yaml.ToJSON([]byte(form.Text))
+		if err != nil {
+			ctx.Error(http.StatusBadRequest, "Unable to parse YAML", err)
+			return
+		}
+		ctx.Resp.Header().Set("Content-Type", "application/json;charset=utf-8")
+		if _, err := ctx.Write(rendered); err != nil {
+			ctx.Error(http.StatusInternalServerError, "Unable to write JSON", err)
+		}
 		return
 	}
 
 	if len(form.Text) == 0 {
 		_, err := ctx.Write([]byte(""))
 		if err != nil {
-			ctx.Error(400, "Unable to write YAML", err)
+			ctx.Error(http.StatusBadRequest, "Unable to write YAML", err)
 		}
 		return
 	}
 	if rendered, err := yaml.RenderSanitized([]byte(form.Text)); err != nil {
-		ctx.Error(400, "Unable to parse YAML", err)
+		ctx.Error(http.StatusBadRequest, "Unable to parse YAML", err)
 	} else if _, err := ctx.Write(rendered); err != nil {
-		ctx.Error(400, "Unable to write YAML", err)
+		ctx.Error(http.StatusBadRequest, "Unable to write YAML", err)
 	}
 }
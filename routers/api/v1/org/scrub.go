@@ -0,0 +1,97 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for the scrub rules of an organization ***/
+
+package org
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// GetScrubRules gets the rules for scrubbing sensitive data from the repos of an organization
+func GetScrubRules(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/scrub_rules organization orgGetScrubRules
+	// ---
+	// summary: Get the rules for scrubbing sensitive data from the repos of an organization. Empty values use the rules of the instance
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ScrubRules"
+
+	rules, err := models.GetScrubRulesByOrgID(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetScrubRulesByOrgID", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToScrubRules(rules))
+}
+
+// EditScrubRules edits the rules for scrubbing sensitive data from the repos of an organization
+func EditScrubRules(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/scrub_rules organization orgEditScrubRules
+	// ---
+	// summary: Edit the rules for scrubbing sensitive data from the repos of an organization. Empty values use the rules of the instance
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EditScrubRulesOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ScrubRules"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	form := web.GetForm(ctx).(*api.EditScrubRulesOption)
+	rules := &models.ScrubRules{
+		OrgID:          ctx.Org.Organization.ID,
+		Files:          cleanList(form.Files),
+		Fields:         cleanList(form.Fields),
+		CommitterName:  strings.TrimSpace(form.CommitterName),
+		CommitterEmail: strings.TrimSpace(form.CommitterEmail),
+	}
+	if err := models.UpdateScrubRules(rules); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateScrubRules", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToScrubRules(rules))
+}
+
+// cleanList trims the items of a list, dropping the empty ones
+func cleanList(list []string) []string {
+	cleaned := make([]string, 0, len(list))
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			cleaned = append(cleaned, item)
+		}
+	}
+	return cleaned
+}
+
+/*** END DCS Customizations ***/
@@ -488,6 +488,13 @@ func GetTeamRepos(ctx *context.APIContext) {
 	}); err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetTeamRepos", err)
 	}
+	/*** DCS Customizations ***/
+	if err := models.RepositoryList(team.Repos).LoadCatalogMetadata(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadCatalogMetadata", err)
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	repos := make([]*api.Repository, len(team.Repos))
 	for i, repo := range team.Repos {
 		access, err := models.AccessLevel(ctx.User, repo)
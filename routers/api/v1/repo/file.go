@@ -109,7 +109,7 @@ func GetArchive(ctx *context.APIContext) {
 	//   required: true
 	// - name: archive
 	//   in: path
-	//   description: the git reference for download with attached archive format (e.g. master.zip)
+	//   description: the git reference for download with attached archive format (e.g. master.zip, or master.rc.zip for the resource container of the manifest)
 	//   type: string
 	//   required: true
 	// responses:
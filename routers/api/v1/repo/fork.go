@@ -53,6 +53,13 @@ func ListForks(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "GetForks", err)
 		return
 	}
+	/*** DCS Customizations ***/
+	if err := models.RepositoryList(forks).LoadCatalogMetadata(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadCatalogMetadata", err)
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	apiForks := make([]*api.Repository, len(forks))
 	for i, fork := range forks {
 		access, err := models.AccessLevel(ctx.User, fork)
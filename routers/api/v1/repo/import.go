@@ -0,0 +1,238 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for importing repositories from zip and translationStudio archives ***/
+
+package repo
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// getOwnerToCreateRepo returns the user or organization of the given name that the signed in user may create
+// a repository for, the signed in user if no name is given
+func getOwnerToCreateRepo(ctx *context.APIContext, ownerName string) *models.User {
+	if ownerName == "" || ownerName == ctx.User.Name {
+		return ctx.User
+	}
+	owner, err := models.GetUserByName(ownerName)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.JSON(http.StatusNotFound, map[string]interface{}{
+				"error": "request owner `" + ownerName + "` does not exist",
+			})
+			return nil
+		}
+		ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+		return nil
+	}
+
+	if !ctx.User.IsAdmin && !owner.IsOrganization() {
+		ctx.Error(http.StatusForbidden, "", "Only admin can create a repository for another user.")
+		return nil
+	}
+
+	if !ctx.User.IsAdmin {
+		canCreate, err := owner.CanCreateOrgRepo(ctx.User.ID)
+		if err != nil {
+			ctx.ServerError("CanCreateOrgRepo", err)
+			return nil
+		} else if !canCreate {
+			ctx.Error(http.StatusForbidden, "", "Given user is not allowed to create repository in organization.")
+			return nil
+		}
+	}
+	return owner
+}
+
+// getArchiveFile returns the archive file of the request, which must be closed
+func getArchiveFile(ctx *context.APIContext) (multipart.File, *multipart.FileHeader) {
+	file, header, err := ctx.Req.FormFile("archive")
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "FormFile", err)
+		return nil, nil
+	}
+	return file, header
+}
+
+// handleImportArchiveError writes the response for an error of the import of an archive
+func handleImportArchiveError(ctx *context.APIContext, name string, err error) {
+	switch {
+	case repo_service.IsErrInvalidArchive(err):
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+	case models.IsErrRepoAlreadyExist(err):
+		ctx.Error(http.StatusConflict, "", "The repository with the same name already exists.")
+	case models.IsErrReachLimitOfRepo(err), models.IsErrUserCannotCommit(err):
+		ctx.Error(http.StatusForbidden, "", err)
+	case models.IsErrNameReserved(err), models.IsErrNamePatternNotAllowed(err), models.IsErrNameCharsNotAllowed(err),
+		models.IsErrFilenameInvalid(err), models.IsErrFilePathInvalid(err):
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+	case git.IsErrBranchNotExist(err):
+		ctx.Error(http.StatusNotFound, "", err)
+	default:
+		ctx.Error(http.StatusInternalServerError, name, err)
+	}
+}
+
+// CreateFromArchive creates a repository from a zip or translationStudio archive
+func CreateFromArchive(ctx *context.APIContext) {
+	// swagger:operation POST /repos/import repository repoCreateFromArchive
+	// ---
+	// summary: Create a repository from a zip or translationStudio archive, converting translationStudio projects to a resource container
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: formData
+	//   description: organization or person who will own the repository, the authenticated user if empty
+	//   type: string
+	// - name: name
+	//   in: formData
+	//   description: name of the repository to create
+	//   type: string
+	//   required: true
+	// - name: description
+	//   in: formData
+	//   description: description of the repository to create
+	//   type: string
+	// - name: private
+	//   in: formData
+	//   description: whether the repository is private
+	//   type: boolean
+	// - name: message
+	//   in: formData
+	//   description: message of the commit of the imported files
+	//   type: string
+	// - name: archive
+	//   in: formData
+	//   description: zip or .tstudio archive to import
+	//   type: file
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/RepoArchiveImport"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     description: The repository with the same name already exists.
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	name := ctx.QueryTrim("name")
+	if name == "" {
+		ctx.Error(http.StatusUnprocessableEntity, "", "name is required")
+		return
+	}
+	owner := getOwnerToCreateRepo(ctx, ctx.QueryTrim("owner"))
+	if ctx.Written() {
+		return
+	}
+	file, header := getArchiveFile(ctx)
+	if ctx.Written() {
+		return
+	}
+	defer file.Close()
+
+	repo, result, err := repo_service.CreateRepositoryFromArchive(ctx.User, owner, file, header.Size, repo_service.CreateRepositoryFromArchiveOptions{
+		Name:        name,
+		Description: ctx.Query("description"),
+		Private:     ctx.QueryBool("private") || setting.Repository.ForcePrivate,
+		Message:     ctx.Query("message"),
+	})
+	if err != nil {
+		handleImportArchiveError(ctx, "CreateRepositoryFromArchive", err)
+		return
+	}
+	log.Trace("Repository created from %s archive [%d]: %s/%s", result.Layout, repo.ID, owner.Name, repo.Name)
+
+	ctx.JSON(http.StatusCreated, &api.RepoArchiveImport{
+		Repository: convert.ToRepo(repo, models.AccessModeOwner),
+		Layout:     result.Layout,
+		CommitID:   result.CommitID,
+	})
+}
+
+// ImportArchive commits the content of a zip or translationStudio archive to a branch of a repository
+func ImportArchive(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/import repository repoImportArchive
+	// ---
+	// summary: Commit the content of a zip or translationStudio archive as the whole tree of a branch of a repository
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: branch
+	//   in: formData
+	//   description: branch to commit to, the default branch if empty
+	//   type: string
+	// - name: message
+	//   in: formData
+	//   description: message of the commit of the imported files
+	//   type: string
+	// - name: archive
+	//   in: formData
+	//   description: zip or .tstudio archive to import
+	//   type: file
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/RepoArchiveImport"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if ctx.Repo.Repository.IsMirror || ctx.Repo.Repository.IsArchived {
+		ctx.Error(http.StatusForbidden, "", "The repository is a mirror or archived.")
+		return
+	}
+	file, header := getArchiveFile(ctx)
+	if ctx.Written() {
+		return
+	}
+	defer file.Close()
+
+	result, err := repo_service.ImportArchive(ctx.User, ctx.Repo.Repository, file, header.Size, repo_service.ImportArchiveOptions{
+		Branch:  ctx.QueryTrim("branch"),
+		Message: ctx.Query("message"),
+	})
+	if err != nil {
+		handleImportArchiveError(ctx, "ImportArchive", err)
+		return
+	}
+	log.Trace("Archive imported into %s: %s", ctx.Repo.Repository.FullName(), result.CommitID)
+
+	ctx.JSON(http.StatusCreated, &api.RepoArchiveImport{
+		Repository: convert.ToRepo(ctx.Repo.Repository, ctx.Repo.AccessMode),
+		Layout:     result.Layout,
+		CommitID:   result.CommitID,
+	})
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,259 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for generating a manifest.yaml file ***/
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/door43metadata"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+const manifestTreePath = "manifest.yaml"
+
+// getCommitByRef gets the commit of a branch, tag or commit ID, defaulting to the default branch
+func getCommitByRef(ctx *context.APIContext, ref string) (*git.Commit, error) {
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	if ctx.Repo.GitRepo.IsBranchExist(ref) {
+		return ctx.Repo.GitRepo.GetBranchCommit(ref)
+	}
+	if ctx.Repo.GitRepo.IsTagExist(ref) {
+		return ctx.Repo.GitRepo.GetTagCommit(ref)
+	}
+	return ctx.Repo.GitRepo.GetCommit(ref)
+}
+
+// GenerateManifest generates a manifest.yaml file from the files of a repo without committing it
+func GenerateManifest(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/manifest/generate repository repoGenerateManifest
+	// ---
+	// summary: Generate a manifest.yaml file from the files of a repository without committing it
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/GeneratedManifest"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	commit, err := getCommitByRef(ctx, ctx.QueryTrim("ref"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "getCommitByRef", err)
+		}
+		return
+	}
+
+	manifest, err := door43metadata.GenerateManifest(ctx.Repo.Repository, commit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GenerateManifest", err)
+		return
+	}
+	content, err := door43metadata.MarshalManifest(nil, manifest)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "MarshalManifest", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.GeneratedManifest{
+		Manifest: manifest,
+		Content:  string(content),
+	})
+}
+
+// CreateGeneratedManifest generates a manifest.yaml file from the files of a repo and commits it or opens a pull request
+func CreateGeneratedManifest(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/manifest/generate repository repoCreateGeneratedManifest
+	// ---
+	// summary: Generate a manifest.yaml file from the files of a repository and commit it or open a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/GenerateManifestOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/GeneratedManifest"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/error"
+
+	apiOpts := web.GetForm(ctx).(*api.GenerateManifestOptions)
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.Error(http.StatusUnprocessableEntity, "RepoIsEmpty", errors.New("repo is empty"))
+		return
+	}
+	if apiOpts.BranchName == "" {
+		apiOpts.BranchName = ctx.Repo.Repository.DefaultBranch
+	}
+	if apiOpts.PullRequest {
+		if apiOpts.NewBranchName == "" || apiOpts.NewBranchName == apiOpts.BranchName {
+			ctx.Error(http.StatusUnprocessableEntity, "NewBranchRequired", errors.New("new_branch is required to open a pull request"))
+			return
+		}
+		if !ctx.Repo.Repository.UnitEnabled(models.UnitTypePullRequests) {
+			ctx.Error(http.StatusUnprocessableEntity, "PullRequestsDisabled", errors.New("pull requests are disabled for this repo"))
+			return
+		}
+	}
+	if !canWriteFiles(ctx.Repo) {
+		ctx.Error(http.StatusForbidden, "Access", models.ErrUserDoesNotHaveAccessToRepo{
+			UserID:   ctx.User.ID,
+			RepoName: ctx.Repo.Repository.LowerName,
+		})
+		return
+	}
+
+	commit, err := ctx.Repo.GitRepo.GetBranchCommit(apiOpts.BranchName)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBranchCommit", err)
+		}
+		return
+	}
+
+	manifest, err := door43metadata.GenerateManifest(ctx.Repo.Repository, commit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GenerateManifest", err)
+		return
+	}
+	content, err := door43metadata.MarshalManifest(nil, manifest)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "MarshalManifest", err)
+		return
+	}
+
+	opts := &repofiles.UpdateRepoFileOptions{
+		Content:      string(content),
+		IsNewFile:    true,
+		Message:      apiOpts.Message,
+		TreePath:     manifestTreePath,
+		OldBranch:    apiOpts.BranchName,
+		NewBranch:    apiOpts.NewBranchName,
+		LastCommitID: commit.ID.String(),
+		Committer: &repofiles.IdentityOptions{
+			Name:  apiOpts.Committer.Name,
+			Email: apiOpts.Committer.Email,
+		},
+		Author: &repofiles.IdentityOptions{
+			Name:  apiOpts.Author.Name,
+			Email: apiOpts.Author.Email,
+		},
+		Dates: &repofiles.CommitDateOptions{
+			Author:    apiOpts.Dates.Author,
+			Committer: apiOpts.Dates.Committer,
+		},
+		Signoff: apiOpts.Signoff,
+	}
+	if opts.Dates.Author.IsZero() {
+		opts.Dates.Author = time.Now()
+	}
+	if opts.Dates.Committer.IsZero() {
+		opts.Dates.Committer = time.Now()
+	}
+	if opts.Message == "" {
+		opts.Message = ctx.Tr("repo.editor.add", manifestTreePath)
+	}
+
+	fileResponse, err := repofiles.CreateOrUpdateRepoFile(ctx.Repo.Repository, ctx.User, opts)
+	if err != nil {
+		handleCreateOrUpdateFileError(ctx, err)
+		return
+	}
+	generated := &api.GeneratedManifest{
+		Manifest: manifest,
+		Content:  string(content),
+		File:     fileResponse,
+	}
+
+	if apiOpts.PullRequest {
+		repo := ctx.Repo.Repository
+		prIssue := &models.Issue{
+			RepoID:   repo.ID,
+			Title:    opts.Message,
+			PosterID: ctx.User.ID,
+			Poster:   ctx.User,
+			IsPull:   true,
+			Content:  ctx.Tr("repo.editor.generated_manifest_pull_request_body"),
+		}
+		pr := &models.PullRequest{
+			HeadRepoID: repo.ID,
+			BaseRepoID: repo.ID,
+			HeadBranch: apiOpts.NewBranchName,
+			BaseBranch: apiOpts.BranchName,
+			HeadRepo:   repo,
+			BaseRepo:   repo,
+			MergeBase:  commit.ID.String(),
+			Type:       models.PullRequestGitea,
+		}
+		if err := pull_service.NewPullRequest(repo, prIssue, nil, []string{}, pr, nil); err != nil {
+			ctx.Error(http.StatusInternalServerError, "NewPullRequest", err)
+			return
+		}
+		log.Trace("Pull request created for generated manifest: %d/%d", repo.ID, prIssue.ID)
+		generated.PullRequest = convert.ToAPIPullRequest(pr)
+	}
+
+	ctx.JSON(http.StatusCreated, generated)
+}
+
+/*** END DCS Customizations ***/
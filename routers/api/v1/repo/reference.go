@@ -0,0 +1,83 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for searching by Bible reference ***/
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/services/scripture"
+)
+
+// SearchReference searches the USFM and TSV files of a repo and its related resources by Bible reference
+func SearchReference(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/reference repository repoSearchReference
+	// ---
+	// summary: Search the USFM and TSV files of a repository and its related resources by Bible reference
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: "The Bible reference, e.g. TIT 1:3, Titus 1:3-5 or TIT 1"
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// - name: related
+	//   in: query
+	//   description: "Whether to also search the resources related by the manifest of the repository, in the same owner. Default true"
+	//   type: boolean
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReferenceSearchResult"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/error"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	reference, err := dcs.ParseReference(ctx.QueryTrim("q"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseReference", err)
+		return
+	}
+	withRelated := ctx.QueryTrim("related") == "" || ctx.QueryBool("related")
+
+	result, err := scripture.Search(ctx.User, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.QueryTrim("ref"), reference, withRelated)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "Search", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+/*** END DCS Customizations ***/
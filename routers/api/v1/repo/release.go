@@ -178,6 +178,8 @@ func CreateRelease(ctx *context.APIContext) {
 	//     "$ref": "#/responses/notFound"
 	//   "409":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	form := web.GetForm(ctx).(*api.CreateReleaseOption)
 	rel, err := models.GetRelease(ctx.Repo.Repository.ID, form.TagName)
 	if err != nil {
@@ -205,6 +207,8 @@ func CreateRelease(ctx *context.APIContext) {
 		if err := releaseservice.CreateRelease(ctx.Repo.GitRepo, rel, nil, ""); err != nil {
 			if models.IsErrReleaseAlreadyExist(err) {
 				ctx.Error(http.StatusConflict, "ReleaseAlreadyExist", err)
+			} else if models.IsErrInconsistentManifest(err) { // DCS Customizations
+				ctx.Error(http.StatusUnprocessableEntity, "InconsistentManifest", err)
 			} else {
 				ctx.Error(http.StatusInternalServerError, "CreateRelease", err)
 			}
@@ -226,6 +230,10 @@ func CreateRelease(ctx *context.APIContext) {
 		rel.Publisher = ctx.User
 
 		if err = releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, nil, nil, nil); err != nil {
+			if models.IsErrInconsistentManifest(err) { // DCS Customizations
+				ctx.Error(http.StatusUnprocessableEntity, "InconsistentManifest", err)
+				return
+			}
 			ctx.Error(http.StatusInternalServerError, "UpdateRelease", err)
 			return
 		}
@@ -268,6 +276,8 @@ func EditRelease(ctx *context.APIContext) {
 	//     "$ref": "#/responses/Release"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.EditReleaseOption)
 	id := ctx.ParamsInt64(":id")
@@ -301,6 +311,10 @@ func EditRelease(ctx *context.APIContext) {
 		rel.IsPrerelease = *form.IsPrerelease
 	}
 	if err := releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, nil, nil, nil); err != nil {
+		if models.IsErrInconsistentManifest(err) { // DCS Customizations
+			ctx.Error(http.StatusUnprocessableEntity, "InconsistentManifest", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "UpdateRelease", err)
 		return
 	}
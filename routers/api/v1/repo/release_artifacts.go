@@ -0,0 +1,78 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for regenerating the HTML and EPUB artifacts of catalog releases ***/
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/artifact"
+)
+
+// RegenerateReleaseArtifacts queues the generation of the HTML and EPUB artifacts of a catalog release
+func RegenerateReleaseArtifacts(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/artifacts repository repoRegenerateReleaseArtifacts
+	// ---
+	// summary: Regenerate the HTML of each book and the EPUB attached to a catalog release, replacing the ones generated before
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	release, err := models.GetReleaseByID(ctx.ParamsInt64(":id"))
+	if err != nil && !models.IsErrReleaseNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		return
+	}
+	if err != nil && models.IsErrReleaseNotExist(err) ||
+		release.IsTag || release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+	exist, err := models.IsDoor43MetadataExist(release.RepoID, release.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsDoor43MetadataExist", err)
+		return
+	}
+	if !exist || release.IsDraft {
+		ctx.Error(http.StatusUnprocessableEntity, "", artifact.ErrNotCatalogRelease{ReleaseID: release.ID})
+		return
+	}
+
+	if err := artifact.StartGeneration(release.ID, true); err != nil {
+		ctx.Error(http.StatusInternalServerError, "StartGeneration", err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+/*** END DCS Customizations ***/
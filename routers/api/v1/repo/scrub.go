@@ -0,0 +1,153 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for scrubbing sensitive data from a repo ***/
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/scrubber"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// Scrub scrubs sensitive data from all branches and tags of a repo, or reports what would be scrubbed
+func Scrub(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/scrub repository repoScrub
+	// ---
+	// summary: Scrub names and email addresses from the files and history of all branches and tags of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/ScrubOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ScrubReport"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/error"
+
+	form := web.GetForm(ctx).(*api.ScrubOptions)
+	repo := ctx.Repo.Repository
+	if repo.IsEmpty {
+		ctx.Error(http.StatusUnprocessableEntity, "RepoIsEmpty", errors.New("repo is empty"))
+		return
+	}
+	if repo.IsMirror {
+		ctx.Error(http.StatusUnprocessableEntity, "RepoIsMirror", errors.New("a mirror cannot be scrubbed"))
+		return
+	}
+	if form.Message == "" {
+		form.Message = ctx.Tr("repo.settings.scrub_commit_message")
+	}
+
+	report, err := scrubber.ScrubSensitiveData(repo, ctx.User, scrubber.ScrubSensitiveDataOptions{
+		CommitMessage: form.Message,
+		DryRun:        form.DryRun,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ScrubSensitiveData", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toScrubReport(report, form.DryRun))
+}
+
+// ListScrubLogs lists the audit log of the scrubs of a repo
+func ListScrubLogs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/scrub/logs repository repoListScrubLogs
+	// ---
+	// summary: List the audit log of the scrubs of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ScrubLogList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	logs, err := models.GetScrubLogsByRepoID(ctx.Repo.Repository.ID, utils.GetListOptions(ctx))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetScrubLogsByRepoID", err)
+		return
+	}
+	apiLogs := make([]*api.ScrubLog, len(logs))
+	for i, l := range logs {
+		if err := l.LoadDoer(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadDoer", err)
+			return
+		}
+		apiLogs[i] = convert.ToScrubLog(l)
+	}
+	ctx.JSON(http.StatusOK, apiLogs)
+}
+
+func toScrubReport(report *scrubber.Report, dryRun bool) *api.ScrubReport {
+	apiReport := &api.ScrubReport{
+		DryRun:         dryRun,
+		CommitterName:  report.Rules.CommitterName,
+		CommitterEmail: report.Rules.CommitterEmail,
+		Refs:           make([]*api.ScrubRefReport, len(report.Refs)),
+		DroppedFiles:   report.DroppedFiles,
+	}
+	for i, ref := range report.Refs {
+		apiRef := &api.ScrubRefReport{
+			Ref:       ref.RefName,
+			Name:      ref.Name(),
+			CommitSHA: ref.CommitID,
+			Files:     make([]*api.ScrubFileReport, len(ref.Files)),
+		}
+		for j, file := range ref.Files {
+			apiRef.Files[j] = &api.ScrubFileReport{
+				Path:   file.Path,
+				Fields: file.Fields,
+			}
+		}
+		apiReport.Refs[i] = apiRef
+	}
+	return apiReport
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,109 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - API for translating a catalog entry into a new repo ***/
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// Translate creates a repository to translate a catalog entry of a repository into a language
+func Translate(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/translate repository repoTranslate
+	// ---
+	// summary: Create a repository named {language}_{subject} to translate a catalog entry of a repository into a language
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/TranslateRepoOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     description: The repository with the same name already exists.
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	form := web.GetForm(ctx).(*api.TranslateRepoOption)
+
+	if ctx.User.IsOrganization() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "not allowed creating repository for organization")
+		return
+	}
+	if !dcs.IsValidLanguage(form.Language) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "language `"+form.Language+"` is not a known language")
+		return
+	}
+
+	dm, err := repo_service.GetCatalogEntryToTranslate(ctx.Repo.Repository, form.Ref)
+	if err != nil {
+		if models.IsErrDoor43MetadataNotExist(err) {
+			ctx.NotFound("GetCatalogEntryToTranslate", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCatalogEntryToTranslate", err)
+		}
+		return
+	}
+
+	ctxUser := getOwnerToCreateRepo(ctx, form.Owner)
+	if ctx.Written() {
+		return
+	}
+
+	repo, err := repo_service.TranslateCatalogEntry(ctx.User, ctxUser, dm, repo_service.TranslateOptions{
+		Language:          form.Language,
+		LanguageTitle:     form.LanguageTitle,
+		LanguageDirection: form.LanguageDirection,
+		Description:       form.Description,
+		Private:           form.Private || setting.Repository.ForcePrivate,
+	})
+	if err != nil {
+		if models.IsErrRepoAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", "The repository with the same name already exists.")
+		} else if models.IsErrReachLimitOfRepo(err) {
+			ctx.Error(http.StatusForbidden, "", err)
+		} else if models.IsErrNameReserved(err) ||
+			models.IsErrNamePatternNotAllowed(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "TranslateCatalogEntry", err)
+		}
+		return
+	}
+	log.Trace("Repository created to translate %s [%d]: %s/%s", dm.Repo.FullName(), repo.ID, ctxUser.Name, repo.Name)
+
+	ctx.JSON(http.StatusCreated, convert.ToRepo(repo, models.AccessModeOwner))
+}
+
+/*** END DCS Customizations ***/
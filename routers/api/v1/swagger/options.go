@@ -166,4 +166,21 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	UserSettingsOptions api.UserSettingsOptions
+
+	/*** DCS Customizations ***/
+	// in:body
+	GenerateManifestOptions api.GenerateManifestOptions
+
+	// in:body
+	ScrubOptions api.ScrubOptions
+
+	// in:body
+	EditScrubRulesOption api.EditScrubRulesOption
+
+	// in:body
+	YamlOption api.YamlOption
+
+	// in:body
+	TranslateRepoOption api.TranslateRepoOption
+	/*** END DCS Customizations ***/
 }
@@ -35,3 +35,14 @@ type swaggerResponseTeamList struct {
 	// in:body
 	Body []api.Team `json:"body"`
 }
+
+/*** DCS Customizations ***/
+
+// ScrubRules
+// swagger:response ScrubRules
+type swaggerScrubRules struct {
+	// in: body
+	Body api.ScrubRules `json:"body"`
+}
+
+/*** END DCS Customizations ***/
@@ -316,3 +316,42 @@ type swaggerCombinedStatus struct {
 	// in: body
 	Body api.CombinedStatus `json:"body"`
 }
+
+/*** DCS Customizations ***/
+
+// GeneratedManifest
+// swagger:response GeneratedManifest
+type swaggerGeneratedManifest struct {
+	// in: body
+	Body api.GeneratedManifest `json:"body"`
+}
+
+// ScrubReport
+// swagger:response ScrubReport
+type swaggerScrubReport struct {
+	// in: body
+	Body api.ScrubReport `json:"body"`
+}
+
+// ScrubLogList
+// swagger:response ScrubLogList
+type swaggerScrubLogList struct {
+	// in: body
+	Body []api.ScrubLog `json:"body"`
+}
+
+// ReferenceSearchResult
+// swagger:response ReferenceSearchResult
+type swaggerReferenceSearchResult struct {
+	// in: body
+	Body api.ReferenceSearchResult `json:"body"`
+}
+
+// RepoArchiveImport
+// swagger:response RepoArchiveImport
+type swaggerRepoArchiveImport struct {
+	// in: body
+	Body api.RepoArchiveImport `json:"body"`
+}
+
+/*** END DCS Customizations ***/
@@ -30,6 +30,13 @@ func listUserRepos(ctx *context.APIContext, u *models.User, private bool) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	if err := models.RepositoryList(repos).LoadCatalogMetadata(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadCatalogMetadata", err)
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	apiRepos := make([]*api.Repository, 0, len(repos))
 	for i := range repos {
 		access, err := models.AccessLevel(ctx.User, repos[i])
@@ -116,6 +123,13 @@ func ListMyRepos(ctx *context.APIContext) {
 		return
 	}
 
+	/*** DCS Customizations ***/
+	if err := models.RepositoryList(repos).LoadCatalogMetadata(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadCatalogMetadata", err)
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	results := make([]*api.Repository, len(repos))
 	for i, repo := range repos {
 		if err = repo.GetOwner(); err != nil {
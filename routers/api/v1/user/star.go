@@ -23,6 +23,12 @@ func getStarredRepos(user *models.User, private bool, listOptions models.ListOpt
 		return nil, err
 	}
 
+	/*** DCS Customizations ***/
+	if err := models.RepositoryList(starredRepos).LoadCatalogMetadata(); err != nil {
+		return nil, err
+	}
+	/*** END DCS Customizations ***/
+
 	repos := make([]*api.Repository, len(starredRepos))
 	for i, starred := range starredRepos {
 		access, err := models.AccessLevel(user, starred)
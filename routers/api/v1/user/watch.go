@@ -22,6 +22,12 @@ func getWatchedRepos(user *models.User, private bool, listOptions models.ListOpt
 		return nil, err
 	}
 
+	/*** DCS Customizations ***/
+	if err := models.RepositoryList(watchedRepos).LoadCatalogMetadata(); err != nil {
+		return nil, err
+	}
+	/*** END DCS Customizations ***/
+
 	repos := make([]*api.Repository, len(watchedRepos))
 	for i, watched := range watchedRepos {
 		access, err := models.AccessLevel(user, watched)
@@ -35,6 +35,7 @@ import (
 	"code.gitea.io/gitea/routers/private"
 	web_routers "code.gitea.io/gitea/routers/web"
 	"code.gitea.io/gitea/services/archiver"
+	"code.gitea.io/gitea/services/artifact" // DCS Customizations
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/mailer"
 	mirror_service "code.gitea.io/gitea/services/mirror"
@@ -60,6 +61,11 @@ func NewServices() {
 	if err := archiver.Init(); err != nil {
 		log.Fatal("archiver init failed: %v", err)
 	}
+	/*** DCS Customizations ***/
+	if err := artifact.Init(); err != nil {
+		log.Fatal("release artifacts init failed: %v", err)
+	}
+	/*** END DCS Customizations ***/
 }
 
 // GlobalInit is for global configuration reload-able.
@@ -401,6 +401,23 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	/*** DCS Customizations ***/
+	// Checked last so that pushes rejected for other reasons are not checked
+	if ctx.Written() {
+		return
+	}
+	warning, ok := checkSensitiveData(ctx, repo, opts, env)
+	if !ok {
+		return
+	}
+	if warning != "" {
+		ctx.JSON(http.StatusOK, private.Response{
+			UserMsg: warning,
+		})
+		return
+	}
+	/*** END DCS Customizations ***/
+
 	ctx.PlainText(http.StatusOK, []byte("ok"))
 }
 
@@ -483,6 +500,12 @@ func HookPostReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	/*** DCS Customizations ***/
+	if repo != nil {
+		recordSensitiveData(ctx, repo, opts)
+	}
+	/*** END DCS Customizations ***/
+
 	results := make([]private.HookPostReceiveBranchResult, 0, len(opts.OldCommitIDs))
 
 	// We have to reload the repo in case its state is changed above
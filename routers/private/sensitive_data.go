@@ -0,0 +1,154 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Checking pushes for sensitive data ***/
+
+package private
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	gitea_context "code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/scrubber"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// checkResultTimeout is the number of seconds the results of the check of a push are kept for the post-receive hook
+const checkResultTimeout = 3600
+
+// checkResultCacheKey returns the key of the cache the result of the check of a push of a ref is kept under
+func checkResultCacheKey(repoID int64, refName, newCommitID string) string {
+	return fmt.Sprintf("sensitive_data_check_%d_%s_%s", repoID, refName, newCommitID)
+}
+
+// checkSensitiveData checks the files added or changed by the pushed refs for the sensitive data that the repo's
+// scrub rules would scrub. Depending on the repo's setting, a push with sensitive data is rejected, or accepted
+// with a warning. It returns the warning for the pusher and false if it has responded. Nothing is recorded as the
+// push may yet be rejected, so the results are kept for recordSensitiveData to record once it is received.
+func checkSensitiveData(ctx *gitea_context.PrivateContext, repo *models.Repository, opts *private.HookOptions, env []string) (string, bool) {
+	check := repo.GetSensitiveDataCheck()
+	if check == models.OffSensitiveDataCheck {
+		return "", true
+	}
+
+	rules, err := scrubber.GetRules(repo)
+	if err != nil {
+		log.Error("Unable to get the scrub rules of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to get the scrub rules: %v", err),
+		})
+		return "", false
+	}
+
+	var messages []string
+	results := make([]*scrubber.CheckResult, 0, len(opts.RefFullNames))
+	for i, refFullName := range opts.RefFullNames {
+		if !strings.HasPrefix(refFullName, git.BranchPrefix) && !strings.HasPrefix(refFullName, git.TagPrefix) {
+			continue
+		}
+		result, err := scrubber.CheckPush(ctx.Req.Context(), repo.RepoPath(), env, rules, refFullName, opts.OldCommitIDs[i], opts.NewCommitIDs[i])
+		if err != nil {
+			log.Error("Unable to check %s of %-v for sensitive data: %v", refFullName, repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to check %s for sensitive data: %v", git.RefEndName(refFullName), err),
+			})
+			return "", false
+		}
+		if len(result.Found) > 0 {
+			messages = append(messages, result.Message())
+		}
+		results = append(results, result)
+	}
+
+	if len(messages) > 0 && check == models.RejectSensitiveDataCheck {
+		log.Warn("Forbidden: Push to %-v has sensitive data", repo)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			Err: strings.Join(messages, "") + "Remove it and push again, or ask an owner of the repository to have it scrubbed.",
+		})
+		return "", false
+	}
+
+	if c := cache.GetCache(); c != nil {
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		for _, result := range results {
+			content, err := json.Marshal(result)
+			if err != nil {
+				log.Error("Unable to marshal the sensitive data check of %s of %-v: %v", result.RefName, repo, err)
+				continue
+			}
+			if err := c.Put(checkResultCacheKey(repo.ID, result.RefName, result.CommitID), string(content), checkResultTimeout); err != nil {
+				log.Error("Unable to cache the sensitive data check of %s of %-v: %v", result.RefName, repo, err)
+			}
+		}
+	}
+
+	if len(messages) == 0 {
+		return "", true
+	}
+	return "Warning: " + strings.Join(messages, "") + "It will be removed if the repository is scrubbed.", true
+}
+
+// recordSensitiveData records the sensitive data found in the received refs by checkSensitiveData for the admins'
+// report, checking them again only if the results were not kept. The push itself is fine, so errors only leave the
+// report out of date.
+func recordSensitiveData(ctx *gitea_context.PrivateContext, repo *models.Repository, opts *private.HookOptions) {
+	if repo.GetSensitiveDataCheck() == models.OffSensitiveDataCheck {
+		return
+	}
+	rules, err := scrubber.GetRules(repo)
+	if err != nil {
+		log.Error("Unable to get the scrub rules of %-v: %v", repo, err)
+		return
+	}
+	results := make([]*scrubber.CheckResult, 0, len(opts.RefFullNames))
+	for i, refFullName := range opts.RefFullNames {
+		if !strings.HasPrefix(refFullName, git.BranchPrefix) && !strings.HasPrefix(refFullName, git.TagPrefix) {
+			continue
+		}
+		if result := getCheckResult(repo.ID, refFullName, opts.NewCommitIDs[i]); result != nil {
+			results = append(results, result)
+			continue
+		}
+		result, err := scrubber.CheckPush(ctx.Req.Context(), repo.RepoPath(), nil, rules, refFullName, opts.OldCommitIDs[i], opts.NewCommitIDs[i])
+		if err != nil {
+			log.Error("Unable to check %s of %-v for sensitive data: %v", refFullName, repo, err)
+			continue
+		}
+		results = append(results, result)
+	}
+	if err := scrubber.RecordCheckResults(repo.ID, results); err != nil {
+		log.Error("Unable to update the sensitive data of %-v: %v", repo, err)
+	}
+}
+
+// getCheckResult returns the result of the check of a push of a ref kept by checkSensitiveData, nil if there is none
+func getCheckResult(repoID int64, refName, newCommitID string) *scrubber.CheckResult {
+	c := cache.GetCache()
+	if c == nil {
+		return nil
+	}
+	key := checkResultCacheKey(repoID, refName, newCommitID)
+	content, ok := c.Get(key).(string)
+	if !ok {
+		return nil
+	}
+	_ = c.Delete(key)
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	result := &scrubber.CheckResult{}
+	if err := json.Unmarshal([]byte(content), result); err != nil {
+		log.Error("Unable to unmarshal the sensitive data check of %s: %v", refName, err)
+		return nil
+	}
+	return result
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,48 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the report of sensitive data found in pushes ***/
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplSensitiveData base.TplName = "admin/repo/sensitive_data"
+
+// SensitiveData lists the branches and tags of repositories with sensitive data found in pushes
+func SensitiveData(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repos.sensitive_data")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	list, count, err := models.FindSensitiveData(models.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.RepoPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("FindSensitiveData", err)
+		return
+	}
+	ctx.Data["SensitiveData"] = list
+	ctx.Data["Total"] = count
+
+	pager := context.NewPagination(int(count), setting.UI.Admin.RepoPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplSensitiveData)
+}
+
+/*** END DCS Customizations ***/
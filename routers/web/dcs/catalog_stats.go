@@ -0,0 +1,65 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for Catalog statistics page ***/
+
+package dcs
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	// tplCatalogStats catalog statistics page template.
+	tplCatalogStats base.TplName = "catalog/stats"
+)
+
+// catalogStatsBar is a month of the bar chart of the releases per month
+type catalogStatsBar struct {
+	Month   string
+	Count   int64
+	Percent int64
+}
+
+// CatalogStats render catalog statistics page
+func CatalogStats(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("explore.catalog_stats")
+	ctx.Data["PageIsCatalog"] = true
+
+	stage := models.StageProd
+	if s, ok := models.StageMap[ctx.Query("stage")]; ok {
+		stage = s
+	}
+	stats, err := models.GetCatalogStats(&models.CatalogStatsOptions{
+		Stage:         stage,
+		StaleAfter:    setting.DCS.CatalogStaleAfter,
+		StalePageSize: setting.UI.ExplorePagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("GetCatalogStats", err)
+		return
+	}
+
+	var maxReleases int64
+	for _, month := range stats.ReleasesPerMonth {
+		if month.Count > maxReleases {
+			maxReleases = month.Count
+		}
+	}
+	bars := make([]*catalogStatsBar, len(stats.ReleasesPerMonth))
+	for i, month := range stats.ReleasesPerMonth {
+		bars[i] = &catalogStatsBar{Month: month.Value, Count: month.Count}
+		if maxReleases > 0 {
+			bars[i].Percent = month.Count * 100 / maxReleases
+		}
+	}
+	ctx.Data["Stage"] = stage.String()
+	ctx.Data["Stages"] = []string{"prod", "preprod", "draft", "latest"}
+	ctx.Data["Stats"] = stats
+	ctx.Data["ReleaseBars"] = bars
+	ctx.HTML(200, tplCatalogStats)
+}
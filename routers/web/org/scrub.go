@@ -0,0 +1,82 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the scrub rules of an organization ***/
+
+package org
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/scrubber"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const tplSettingsScrubRules base.TplName = "org/settings/scrub_rules"
+
+func prepareScrubRules(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsScrubRules"] = true
+	ctx.Data["DefaultScrubRules"] = scrubber.DefaultRules()
+}
+
+// ScrubRules renders the form for the rules for scrubbing the repos of an organization
+func ScrubRules(ctx *context.Context) {
+	prepareScrubRules(ctx)
+
+	rules, err := models.GetScrubRulesByOrgID(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetScrubRulesByOrgID", err)
+		return
+	}
+	ctx.Data["files"] = strings.Join(rules.Files, "\n")
+	ctx.Data["fields"] = strings.Join(rules.Fields, "\n")
+	ctx.Data["committer_name"] = rules.CommitterName
+	ctx.Data["committer_email"] = rules.CommitterEmail
+
+	ctx.HTML(http.StatusOK, tplSettingsScrubRules)
+}
+
+// ScrubRulesPost updates the rules for scrubbing the repos of an organization
+func ScrubRulesPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ScrubRulesForm)
+	prepareScrubRules(ctx)
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsScrubRules)
+		return
+	}
+
+	if err := models.UpdateScrubRules(&models.ScrubRules{
+		OrgID:          ctx.Org.Organization.ID,
+		Files:          splitLines(form.Files),
+		Fields:         splitLines(form.Fields),
+		CommitterName:  strings.TrimSpace(form.CommitterName),
+		CommitterEmail: strings.TrimSpace(form.CommitterEmail),
+	}); err != nil {
+		ctx.ServerError("UpdateScrubRules", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.update_scrub_rules_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/scrub_rules")
+}
+
+// splitLines splits the lines of a textarea, dropping the empty ones
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+/*** END DCS Customizations ***/
@@ -6,10 +6,13 @@ package repo
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"html"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"path"
 	"path/filepath"
@@ -52,6 +55,9 @@ func setCompareContext(ctx *context.Context, base *git.Commit, head *git.Commit,
 	setPathsCompareContext(ctx, base, head, headTarget)
 	setImageCompareContext(ctx)
 	setCsvCompareContext(ctx)
+	/*** DCS Customizations ***/
+	setUsfmCompareContext(ctx)
+	/*** END DCS Customizations ***/
 }
 
 // setPathsCompareContext sets context data for source and raw paths
@@ -144,6 +150,73 @@ func setCsvCompareContext(ctx *context.Context) {
 	}
 }
 
+/*** DCS Customizations ***/
+
+// setUsfmCompareContext sets context data that is required by the text-only USFM compare template
+func setUsfmCompareContext(ctx *context.Context) {
+	ctx.Data["IsUsfmFile"] = func(diffFile *gitdiff.DiffFile) bool {
+		extension := strings.ToLower(filepath.Ext(diffFile.Name))
+		return extension == ".usfm" || extension == ".usfm3" || extension == ".sfm"
+	}
+
+	type UsfmDiffResult struct {
+		Verses []*gitdiff.UsfmVerseDiff
+		Error  string
+	}
+
+	ctx.Data["CreateUsfmDiff"] = func(diffFile *gitdiff.DiffFile, baseCommit *git.Commit, headCommit *git.Commit) UsfmDiffResult {
+		if diffFile == nil || headCommit == nil {
+			return UsfmDiffResult{nil, ""}
+		}
+
+		errTooLarge := errors.New(ctx.Locale.Tr("repo.error.usfm.too_large"))
+
+		readerFromCommit := func(c *git.Commit, treePath string) (io.Reader, error) {
+			if c == nil || treePath == "" {
+				return nil, nil
+			}
+			blob, err := c.GetBlobByPath(treePath)
+			if err != nil {
+				if git.IsErrNotExist(err) {
+					return nil, nil
+				}
+				return nil, err
+			}
+			if setting.UI.MaxDisplayFileSize != 0 && setting.UI.MaxDisplayFileSize < blob.Size() {
+				return nil, errTooLarge
+			}
+			reader, err := blob.DataAsync()
+			if err != nil {
+				return nil, err
+			}
+			defer reader.Close()
+			content, err := ioutil.ReadAll(charset.ToUTF8WithFallbackReader(reader))
+			if err != nil {
+				return nil, err
+			}
+			return bytes.NewReader(content), nil
+		}
+
+		baseReader, err := readerFromCommit(baseCommit, diffFile.OldName)
+		if err != nil {
+			return UsfmDiffResult{nil, err.Error()}
+		}
+		headReader, err := readerFromCommit(headCommit, diffFile.Name)
+		if err != nil {
+			return UsfmDiffResult{nil, err.Error()}
+		}
+
+		verses, err := gitdiff.CreateUsfmDiff(baseReader, headReader)
+		if err != nil {
+			log.Error("CreateUsfmDiff failed: %v", err)
+			return UsfmDiffResult{nil, ""}
+		}
+		return UsfmDiffResult{verses, ""}
+	}
+}
+
+/*** END DCS Customizations ***/
+
 // ParseCompareInfo parse compare info between two commit for preparing comparing references
 func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *git.Repository, *git.CompareInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
@@ -0,0 +1,158 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for importing repositories from zip and translationStudio archives ***/
+
+package repo
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const (
+	tplImportRepo    base.TplName = "repo/import"
+	tplImportArchive base.TplName = "repo/import_archive"
+)
+
+// openArchive opens the uploaded archive of a form, which must be closed
+func openArchive(ctx *context.Context, header *multipart.FileHeader, tpl base.TplName, form interface{}) multipart.File {
+	if header == nil {
+		ctx.Data["Err_Archive"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.import_archive.invalid", "no archive was uploaded"), tpl, form)
+		return nil
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return nil
+	}
+	return file
+}
+
+// ImportRepo renders the page to create a repository from an archive
+func ImportRepo(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.import_archive")
+	ctx.Data["private"] = getRepoPrivate(ctx)
+	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
+
+	ctxUser := checkContextUser(ctx, ctx.QueryInt64("org"))
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ContextUser"] = ctxUser
+
+	ctx.HTML(http.StatusOK, tplImportRepo)
+}
+
+// ImportRepoPost creates a repository from an uploaded zip or translationStudio archive
+func ImportRepoPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ImportArchiveRepoForm)
+	ctx.Data["Title"] = ctx.Tr("repo.import_archive")
+	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
+
+	ctxUser := checkContextUser(ctx, form.UID)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ContextUser"] = ctxUser
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplImportRepo)
+		return
+	}
+
+	file := openArchive(ctx, form.Archive, tplImportRepo, form)
+	if ctx.Written() {
+		return
+	}
+	defer file.Close()
+
+	repo, result, err := repo_service.CreateRepositoryFromArchive(ctx.User, ctxUser, file, form.Archive.Size, repo_service.CreateRepositoryFromArchiveOptions{
+		Name:        form.RepoName,
+		Description: form.Description,
+		Private:     form.Private || setting.Repository.ForcePrivate,
+		Message:     form.Message,
+	})
+	if err != nil {
+		if repo_service.IsErrInvalidArchive(err) {
+			ctx.Data["Err_Archive"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.import_archive.invalid", err.(repo_service.ErrInvalidArchive).Reason), tplImportRepo, form)
+			return
+		}
+		handleCreateError(ctx, ctxUser, err, "ImportRepoPost", tplImportRepo, form)
+		return
+	}
+
+	log.Trace("Repository created from %s archive [%d]: %s/%s", result.Layout, repo.ID, ctxUser.Name, repo.Name)
+	ctx.Redirect(ctxUser.HomeLink() + "/" + repo.Name)
+}
+
+// ImportArchive renders the page to upload an archive to a branch of the repository
+func ImportArchive(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.import_archive.upload")
+	ctx.Data["branch"] = ctx.Repo.Repository.DefaultBranch
+	if branch := ctx.QueryTrim("branch"); branch != "" {
+		ctx.Data["branch"] = branch
+	}
+
+	ctx.HTML(http.StatusOK, tplImportArchive)
+}
+
+// ImportArchivePost commits the content of an uploaded zip or translationStudio archive to a branch of the repository
+func ImportArchivePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ImportArchiveForm)
+	ctx.Data["Title"] = ctx.Tr("repo.import_archive.upload")
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplImportArchive)
+		return
+	}
+
+	file := openArchive(ctx, form.Archive, tplImportArchive, form)
+	if ctx.Written() {
+		return
+	}
+	defer file.Close()
+
+	result, err := repo_service.ImportArchive(ctx.User, ctx.Repo.Repository, file, form.Archive.Size, repo_service.ImportArchiveOptions{
+		Branch:  form.Branch,
+		Message: form.Message,
+	})
+	if err != nil {
+		switch {
+		case repo_service.IsErrInvalidArchive(err):
+			ctx.Data["Err_Archive"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.import_archive.invalid", err.(repo_service.ErrInvalidArchive).Reason), tplImportArchive, form)
+		case git.IsErrBranchNotExist(err):
+			ctx.Data["Err_Branch"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_does_not_exist", form.Branch), tplImportArchive, form)
+		case models.IsErrUserCannotCommit(err):
+			ctx.Data["Err_Branch"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", form.Branch), tplImportArchive, form)
+		case models.IsErrFilenameInvalid(err), models.IsErrFilePathInvalid(err):
+			ctx.Data["Err_Archive"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.import_archive.invalid", err.Error()), tplImportArchive, form)
+		default:
+			ctx.ServerError("ImportArchive", err)
+		}
+		return
+	}
+
+	log.Trace("Archive imported into %s: %s", ctx.Repo.Repository.FullName(), result.CommitID)
+	ctx.Flash.Success(ctx.Tr("repo.import_archive.success", result.Layout, base.ShortSha(result.CommitID)))
+	ctx.Redirect(ctx.Repo.RepoLink + "/commit/" + result.CommitID)
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,268 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the manifest.yaml editor form ***/
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/door43metadata"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplEditManifest base.TplName = "repo/editor/manifest"
+
+	manifestTreePath = "manifest.yaml"
+)
+
+// readManifest reads the manifest.yaml file of the current commit, returning nil if it does not exist
+func readManifest(ctx *context.Context) (map[string]interface{}, error) {
+	manifest, _, err := readManifestAndContent(ctx)
+	return manifest, err
+}
+
+// readManifestAndContent reads the manifest.yaml file of the current commit and returns it with its content,
+// returning nil if it does not exist
+func readManifestAndContent(ctx *context.Context) (map[string]interface{}, []byte, error) {
+	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(manifestTreePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	manifest, content, err := base.ReadYAMLAndContentFromBlob(entry.Blob())
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest == nil || *manifest == nil {
+		return map[string]interface{}{}, content, nil
+	}
+	return *manifest, content, nil
+}
+
+func prepareEditManifest(ctx *context.Context) {
+	ctx.Data["PageIsEdit"] = true
+	ctx.Data["PageIsEditManifest"] = true
+	ctx.Data["TreePath"] = manifestTreePath
+	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
+	ctx.Data["EditRawLink"] = ctx.Repo.RepoLink + "/_edit/" + util.PathEscapeSegments(ctx.Repo.BranchName) + "/" + manifestTreePath
+	ctx.Data["ManifestLanguages"] = door43metadata.GetManifestLanguageOptions()
+}
+
+// EditManifest renders the form for editing the manifest.yaml file, generated from the RC schema
+func EditManifest(ctx *context.Context) {
+	prepareEditManifest(ctx)
+	canCommit := renderCommitRights(ctx)
+
+	manifest, err := readManifest(ctx)
+	if err != nil {
+		// The form can't edit a manifest that doesn't parse, so send the user to the text editor
+		ctx.Flash.Error(ctx.Tr("repo.editor.manifest_unreadable", err.Error()))
+		ctx.Redirect(ctx.Data["EditRawLink"].(string))
+		return
+	}
+	if manifest == nil {
+		// Propose a manifest generated from the files of the repo
+		ctx.Data["IsNewFile"] = true
+		ctx.Data["IsGeneratedManifest"] = true
+		manifest, err = door43metadata.GenerateManifest(ctx.Repo.Repository, ctx.Repo.Commit)
+		if err != nil {
+			ctx.ServerError("GenerateManifest", err)
+			return
+		}
+	}
+
+	fields, err := door43metadata.GetManifestFormFields(&manifest)
+	if err != nil {
+		ctx.ServerError("GetManifestFormFields", err)
+		return
+	}
+	result, err := base.ValidateBlobByRC020Schema(&manifest)
+	if err != nil {
+		log.Error("ValidateBlobByRC020Schema: %v", err)
+	} else {
+		ctx.Data["ManifestErrors"] = door43metadata.SetManifestFormErrors(fields, result)
+	}
+	ctx.Data["ManifestFields"] = fields
+
+	ctx.Data["commit_summary"] = ""
+	ctx.Data["commit_message"] = ""
+	if canCommit {
+		ctx.Data["commit_choice"] = frmCommitChoiceDirect
+	} else {
+		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
+	}
+	ctx.Data["new_branch_name"] = GetUniquePatchBranchName(ctx)
+	ctx.Data["last_commit"] = ctx.Repo.CommitID
+
+	ctx.HTML(http.StatusOK, tplEditManifest)
+}
+
+// EditManifestPost validates the submitted manifest form and commits the manifest.yaml file
+func EditManifestPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditManifestForm)
+	prepareEditManifest(ctx)
+	canCommit := renderCommitRights(ctx)
+	branchName := ctx.Repo.BranchName
+	if form.CommitChoice == frmCommitChoiceNewBranch {
+		branchName = form.NewBranchName
+	}
+
+	ctx.Data["PageHasPosted"] = true
+	ctx.Data["commit_summary"] = form.CommitSummary
+	ctx.Data["commit_message"] = form.CommitMessage
+	ctx.Data["commit_choice"] = form.CommitChoice
+	ctx.Data["new_branch_name"] = form.NewBranchName
+	ctx.Data["last_commit"] = ctx.Repo.CommitID
+
+	manifest, original, err := readManifestAndContent(ctx)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("repo.editor.manifest_unreadable", err.Error()))
+		ctx.Redirect(ctx.Data["EditRawLink"].(string))
+		return
+	}
+	isNewFile := manifest == nil
+	if isNewFile {
+		manifest = map[string]interface{}{}
+	}
+	ctx.Data["IsNewFile"] = isNewFile
+
+	fields, err := door43metadata.GetManifestFormFields(&manifest)
+	if err != nil {
+		ctx.ServerError("GetManifestFormFields", err)
+		return
+	}
+	ctx.Data["ManifestFields"] = fields
+
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if _, ok := ctx.Req.Form[field.FormName()]; ok {
+			values[field.FormName()] = ctx.Req.FormValue(field.FormName())
+		}
+	}
+	valid := door43metadata.ApplyManifestFormValues(manifest, fields, values)
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplEditManifest)
+		return
+	}
+
+	if valid {
+		result, err := base.ValidateBlobByRC020Schema(&manifest)
+		if err != nil {
+			ctx.ServerError("ValidateBlobByRC020Schema", err)
+			return
+		}
+		if !result.Valid() {
+			ctx.Data["ManifestErrors"] = door43metadata.SetManifestFormErrors(fields, result)
+			valid = false
+		}
+	}
+	if !valid {
+		ctx.RenderWithErr(ctx.Tr("repo.editor.manifest_invalid"), tplEditManifest, form)
+		return
+	}
+
+	// Cannot commit to a an existing branch if user doesn't have rights
+	if branchName == ctx.Repo.BranchName && !canCommit {
+		ctx.Data["Err_NewBranchName"] = true
+		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
+		ctx.RenderWithErr(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", branchName), tplEditManifest, form)
+		return
+	}
+
+	content, err := door43metadata.MarshalManifest(original, manifest)
+	if err != nil {
+		ctx.ServerError("MarshalManifest", err)
+		return
+	}
+
+	message := strings.TrimSpace(form.CommitSummary)
+	if len(message) == 0 {
+		if isNewFile {
+			message = ctx.Tr("repo.editor.add", manifestTreePath)
+		} else {
+			message = ctx.Tr("repo.editor.update", manifestTreePath)
+		}
+	}
+	form.CommitMessage = strings.TrimSpace(form.CommitMessage)
+	if len(form.CommitMessage) > 0 {
+		message += "\n\n" + form.CommitMessage
+	}
+
+	if _, err := repofiles.CreateOrUpdateRepoFile(ctx.Repo.Repository, ctx.User, &repofiles.UpdateRepoFileOptions{
+		LastCommitID: form.LastCommit,
+		OldBranch:    ctx.Repo.BranchName,
+		NewBranch:    branchName,
+		FromTreePath: manifestTreePath,
+		TreePath:     manifestTreePath,
+		Message:      message,
+		Content:      string(content),
+		IsNewFile:    isNewFile,
+		Signoff:      form.Signoff,
+	}); err != nil {
+		if models.IsErrLFSFileLocked(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.upload_file_is_locked", err.(models.ErrLFSFileLocked).Path, err.(models.ErrLFSFileLocked).UserName), tplEditManifest, form)
+		} else if git.IsErrBranchNotExist(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_does_not_exist", err.(git.ErrBranchNotExist).Name), tplEditManifest, form)
+		} else if models.IsErrBranchAlreadyExists(err) {
+			ctx.Data["Err_NewBranchName"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_already_exists", err.(models.ErrBranchAlreadyExists).BranchName), tplEditManifest, form)
+		} else if models.IsErrCommitIDDoesNotMatch(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.file_changed_while_editing", ctx.Repo.RepoLink+"/compare/"+form.LastCommit+"..."+ctx.Repo.CommitID), tplEditManifest, form)
+		} else if git.IsErrPushOutOfDate(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.editor.file_changed_while_editing", ctx.Repo.RepoLink+"/compare/"+form.LastCommit+"..."+util.PathEscapeSegments(form.NewBranchName)), tplEditManifest, form)
+		} else if git.IsErrPushRejected(err) {
+			errPushRej := err.(*git.ErrPushRejected)
+			if len(errPushRej.Message) == 0 {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.push_rejected_no_message"), tplEditManifest, form)
+			} else {
+				flashError, err := ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
+					"Message": ctx.Tr("repo.editor.push_rejected"),
+					"Summary": ctx.Tr("repo.editor.push_rejected_summary"),
+					"Details": utils.SanitizeFlashErrorString(errPushRej.Message),
+				})
+				if err != nil {
+					ctx.ServerError("EditManifestPost.HTMLString", err)
+					return
+				}
+				ctx.RenderWithErr(flashError, tplEditManifest, form)
+			}
+		} else {
+			flashError, err := ctx.HTMLString(string(tplAlertDetails), map[string]interface{}{
+				"Message": ctx.Tr("repo.editor.fail_to_update_file", manifestTreePath),
+				"Summary": ctx.Tr("repo.editor.fail_to_update_file_summary"),
+				"Details": utils.SanitizeFlashErrorString(err.Error()),
+			})
+			if err != nil {
+				ctx.ServerError("EditManifestPost.HTMLString", err)
+				return
+			}
+			ctx.RenderWithErr(flashError, tplEditManifest, form)
+		}
+		return
+	}
+
+	if form.CommitChoice == frmCommitChoiceNewBranch && ctx.Repo.Repository.UnitEnabled(models.UnitTypePullRequests) {
+		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(ctx.Repo.BranchName) + "..." + util.PathEscapeSegments(form.NewBranchName))
+	} else {
+		ctx.Redirect(ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(branchName) + "/" + manifestTreePath)
+	}
+}
+
+/*** END DCS Customizations ***/
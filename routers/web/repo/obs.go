@@ -0,0 +1,304 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the reader of Open Bible Stories ***/
+
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const (
+	tplOBSReader base.TplName = "repo/obs_reader"
+	tplOBSExport base.TplName = "repo/obs_export"
+)
+
+// obsStorySet is the set of stories of an Open Bible Stories repo, given by the obs project of its manifest
+type obsStorySet struct {
+	Title    string
+	Language string
+	Path     string
+}
+
+// obsStoryLink is the link to a story in the reader
+type obsStoryLink struct {
+	Number string
+	Link   string
+}
+
+// obsExportStory is a story as rendered in the single HTML document of a story set
+type obsExportStory struct {
+	Number  string
+	Title   string
+	Content string
+}
+
+// getOBSStorySet returns the story set of the current commit, or nil if the repo isn't an Open Bible Stories repo
+func getOBSStorySet(ctx *context.Context) (*obsStorySet, error) {
+	manifest, err := readManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(strings.ToLower(ctx.Repo.Repository.Name), "_obs") &&
+		dcs.GetDublinCoreString(&manifest, "subject") != dcs.Subjects["obs"] {
+		return nil, nil
+	}
+
+	set := &obsStorySet{
+		Title:    dcs.GetDublinCoreString(&manifest, "title"),
+		Language: dcs.GetDublinCoreString(&manifest, "language", "identifier"),
+		Path:     "content",
+	}
+	if projects, ok := manifest["projects"].([]interface{}); ok {
+		for _, p := range projects {
+			project, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if identifier, _ := project["identifier"].(string); identifier != "obs" && len(projects) > 1 {
+				continue
+			}
+			if projectPath, _ := project["path"].(string); path.Clean(projectPath) != "." {
+				set.Path = path.Clean(projectPath)
+			}
+			if title, _ := project["title"].(string); title != "" && set.Title == "" {
+				set.Title = title
+			}
+			break
+		}
+	}
+	if set.Title == "" {
+		set.Title = ctx.Repo.Repository.Name
+	}
+	return set, nil
+}
+
+// listOBSStories returns the numbers of the stories of a story set, in order
+func listOBSStories(ctx *context.Context, set *obsStorySet) ([]string, error) {
+	tree, err := ctx.Repo.Commit.SubTree(set.Path)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	var numbers []string
+	for _, entry := range entries {
+		if matches := dcs.OBSStoryFileRegex.FindStringSubmatch(entry.Name()); matches != nil && !entry.IsDir() {
+			numbers = append(numbers, matches[1])
+		}
+	}
+	sort.Strings(numbers)
+	return numbers, nil
+}
+
+// readOBSStory reads a story of a story set, returning nil if it does not exist
+func readOBSStory(ctx *context.Context, set *obsStorySet, number string) (*dcs.OBSStory, error) {
+	data, err := readTreeFile(ctx, path.Join(set.Path, number+".md"))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return dcs.ParseOBSStory(data), nil
+}
+
+func obsReaderLink(ctx *context.Context, number string, frame int) string {
+	link := ctx.Repo.RepoLink + "/obs/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL()) + "/" + number
+	if frame > 1 {
+		link += fmt.Sprintf("?frame=%d", frame)
+	}
+	return link
+}
+
+// obsImageLink returns the link to the image of a frame, which is usually a URL but can be a file of the repo
+func obsImageLink(ctx *context.Context, set *obsStorySet, image string) string {
+	if image == "" || strings.Contains(image, "://") || strings.HasPrefix(image, "/") {
+		return image
+	}
+	return ctx.Repo.RepoLink + "/raw/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL()) + "/" + util.PathEscapeSegments(path.Join(set.Path, image))
+}
+
+// renderOBSMarkdown renders the markdown of a story, with links relative to the folder of the stories
+func renderOBSMarkdown(ctx *context.Context, urlPrefix, text string) (string, error) {
+	var result strings.Builder
+	if err := markup.Render(&markup.RenderContext{
+		Ctx:       ctx,
+		Filename:  "01.md",
+		URLPrefix: urlPrefix,
+		Metas:     ctx.Repo.Repository.ComposeDocumentMetas(),
+		GitRepo:   ctx.Repo.GitRepo,
+	}, strings.NewReader(text), &result); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// setOBSReaderLink gives the link to the reader if the repo is an Open Bible Stories repo
+func setOBSReaderLink(ctx *context.Context) {
+	set, err := getOBSStorySet(ctx)
+	if err != nil {
+		log.Debug("Unable to read the manifest of %-v: %v", ctx.Repo.Repository, err)
+		return
+	}
+	if set != nil && (ctx.Repo.TreePath == "" || ctx.Repo.TreePath == set.Path) {
+		ctx.Data["OBSReaderLink"] = ctx.Repo.RepoLink + "/obs/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL())
+	}
+}
+
+// OBSReader steps through the stories of an Open Bible Stories repo frame by frame
+func OBSReader(ctx *context.Context) {
+	set, err := getOBSStorySet(ctx)
+	if err != nil {
+		ctx.ServerError("getOBSStorySet", err)
+		return
+	}
+	if set == nil {
+		ctx.NotFound("OBSReader", nil)
+		return
+	}
+	numbers, err := listOBSStories(ctx, set)
+	if err != nil {
+		ctx.ServerError("listOBSStories", err)
+		return
+	}
+	if len(numbers) == 0 {
+		ctx.NotFound("OBSReader", nil)
+		return
+	}
+
+	number := strings.TrimSuffix(ctx.Repo.TreePath, ".md")
+	if number == "" {
+		number = numbers[0]
+	}
+	index := sort.SearchStrings(numbers, number)
+	if index == len(numbers) || numbers[index] != number {
+		ctx.NotFound("OBSReader", nil)
+		return
+	}
+	story, err := readOBSStory(ctx, set, number)
+	if err != nil {
+		ctx.ServerError("readOBSStory", err)
+		return
+	}
+
+	frameCount := len(story.Frames)
+	frameNumber := ctx.QueryInt("frame")
+	if frameNumber < 1 {
+		frameNumber = 1
+	}
+	if frameNumber > frameCount {
+		frameNumber = frameCount
+	}
+	if frameNumber > 0 {
+		frame := story.Frames[frameNumber-1]
+		text, err := renderOBSMarkdown(ctx, refSrcLink(ctx, set.Path), frame.Text)
+		if err != nil {
+			ctx.ServerError("Render", err)
+			return
+		}
+		ctx.Data["OBSFrameImage"] = obsImageLink(ctx, set, frame.Image)
+		ctx.Data["OBSFrameText"] = text
+	}
+
+	// The previous and next frames may be in the previous and next stories
+	switch {
+	case frameNumber > 1:
+		ctx.Data["OBSPrevLink"] = obsReaderLink(ctx, number, frameNumber-1)
+	case index > 0:
+		prevStory, err := readOBSStory(ctx, set, numbers[index-1])
+		if err != nil {
+			ctx.ServerError("readOBSStory", err)
+			return
+		}
+		ctx.Data["OBSPrevLink"] = obsReaderLink(ctx, numbers[index-1], len(prevStory.Frames))
+	}
+	switch {
+	case frameNumber < frameCount:
+		ctx.Data["OBSNextLink"] = obsReaderLink(ctx, number, frameNumber+1)
+	case index < len(numbers)-1:
+		ctx.Data["OBSNextLink"] = obsReaderLink(ctx, numbers[index+1], 1)
+	}
+
+	storyLinks := make([]*obsStoryLink, 0, len(numbers))
+	for _, n := range numbers {
+		storyLinks = append(storyLinks, &obsStoryLink{Number: n, Link: obsReaderLink(ctx, n, 1)})
+	}
+
+	ctx.Data["Title"] = story.Title + " - " + set.Title
+	ctx.Data["PageIsViewCode"] = true
+	ctx.Data["OBSTitle"] = set.Title
+	ctx.Data["OBSStory"] = story
+	ctx.Data["OBSStoryNumber"] = number
+	ctx.Data["OBSStoryLinks"] = storyLinks
+	ctx.Data["OBSFrameNumber"] = frameNumber
+	ctx.Data["OBSFrameCount"] = frameCount
+	ctx.Data["OBSSourceLink"] = refSrcLink(ctx, path.Join(set.Path, number+".md"))
+	ctx.Data["OBSExportLink"] = ctx.Repo.RepoLink + "/obs/export/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL())
+	ctx.HTML(http.StatusOK, tplOBSReader)
+}
+
+// OBSExport exports the stories of an Open Bible Stories repo as a single HTML document
+func OBSExport(ctx *context.Context) {
+	set, err := getOBSStorySet(ctx)
+	if err != nil {
+		ctx.ServerError("getOBSStorySet", err)
+		return
+	}
+	if set == nil {
+		ctx.NotFound("OBSExport", nil)
+		return
+	}
+	numbers, err := listOBSStories(ctx, set)
+	if err != nil {
+		ctx.ServerError("listOBSStories", err)
+		return
+	}
+
+	// The links of the document are absolute so that it can be read anywhere
+	urlPrefix := ctx.Repo.Repository.HTMLURL() + "/src/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL()) + "/" + util.PathEscapeSegments(set.Path)
+	stories := make([]*obsExportStory, 0, len(numbers))
+	for _, number := range numbers {
+		data, err := readTreeFile(ctx, path.Join(set.Path, number+".md"))
+		if err != nil {
+			ctx.ServerError("readTreeFile", err)
+			return
+		}
+		content, err := renderOBSMarkdown(ctx, urlPrefix, string(bytes.TrimSpace(data)))
+		if err != nil {
+			ctx.ServerError("Render", err)
+			return
+		}
+		title := dcs.ParseOBSStory(data).Title
+		if title == "" {
+			title = number
+		}
+		stories = append(stories, &obsExportStory{Number: number, Title: title, Content: content})
+	}
+
+	ctx.Data["OBSTitle"] = set.Title
+	ctx.Data["OBSLanguage"] = set.Language
+	ctx.Data["OBSStories"] = stories
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%s.html"`,
+		ctx.Repo.Repository.Name, strings.ReplaceAll(ctx.Repo.BranchName, "/", "-")))
+	ctx.HTML(http.StatusOK, tplOBSExport)
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,51 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the side by side view of a translation and its source ***/
+
+package repo
+
+import (
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/scripture"
+)
+
+const tplParallel base.TplName = "repo/parallel"
+
+// setParallelViewLink gives the link to the side by side view of a USFM file if the manifest has a source
+func setParallelViewLink(ctx *context.Context) {
+	if ctx.Repo.Commit == nil || !scripture.HasParallelSource(ctx.Repo.Commit, ctx.Repo.TreePath) {
+		return
+	}
+	ctx.Data["ParallelViewLink"] = ctx.Repo.RepoLink + "/parallel/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL()) + "/" + util.PathEscapeSegments(ctx.Repo.TreePath)
+}
+
+// ParallelView shows a USFM file of a translation next to the same book in its source, verse by verse
+func ParallelView(ctx *context.Context) {
+	treePath := ctx.Repo.TreePath
+	ctx.Data["Title"] = treePath + " - " + ctx.Repo.Repository.FullName()
+	ctx.Data["PageIsViewCode"] = true
+	ctx.Data["TreeLink"] = refSrcLink(ctx, treePath)
+
+	view, err := scripture.GetParallelView(ctx.Repo.Repository, ctx.Repo.Commit, ctx.Repo.BranchName, ctx.Repo.BranchNameSubURL(), treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetParallelView", err)
+		} else if scripture.IsErrNoParallelSource(err) {
+			ctx.Data["ParallelError"] = err.(scripture.ErrNoParallelSource)
+			ctx.HTML(200, tplParallel)
+		} else {
+			ctx.ServerError("GetParallelView", err)
+		}
+		return
+	}
+	ctx.Data["Title"] = view.Book.Name + " - " + ctx.Repo.Repository.FullName()
+	ctx.Data["ParallelView"] = view
+	ctx.HTML(200, tplParallel)
+}
+
+/*** END DCS Customizations ***/
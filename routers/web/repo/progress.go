@@ -0,0 +1,43 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the translation progress of the books of a repo ***/
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// setBookProgresses gives the translation progress of the books of the default branch or of the release
+// of the tag being viewed, if it was counted when processing the metadata of the repo
+func setBookProgresses(ctx *context.Context) {
+	var releaseID int64
+	switch {
+	case ctx.Repo.IsViewBranch && ctx.Repo.BranchName == ctx.Repo.Repository.DefaultBranch:
+	case ctx.Repo.IsViewTag:
+		release, err := models.GetRelease(ctx.Repo.Repository.ID, ctx.Repo.TagName)
+		if err != nil || release.IsTag {
+			return
+		}
+		releaseID = release.ID
+	default:
+		return
+	}
+
+	bps, err := models.GetBookProgresses(ctx.Repo.Repository.ID, releaseID)
+	if err != nil {
+		log.Error("GetBookProgresses: %v", err)
+		return
+	}
+	if len(bps) == 0 {
+		return
+	}
+	ctx.Data["BookProgresses"] = bps
+	ctx.Data["BookProgressTotal"] = bps.Total()
+}
+
+/*** END DCS Customizations ***/
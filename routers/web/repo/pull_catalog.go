@@ -0,0 +1,74 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Preview of the catalog entry of the head of a pull request ***/
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/door43metadata"
+	"code.gitea.io/gitea/modules/git"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const tplPullCatalog base.TplName = "repo/pulls/catalog"
+
+// ViewPullCatalog shows the catalog entry the head of a pull request would have, without saving it,
+// with the changes of its metadata from the production entry
+func ViewPullCatalog(ctx *context.Context) {
+	ctx.Data["PageIsPullList"] = true
+	ctx.Data["PageIsPullCatalog"] = true
+
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+
+	var prInfo *git.CompareInfo
+	if pull.HasMerged {
+		prInfo = PrepareMergedViewPullInfo(ctx, issue)
+	} else {
+		prInfo = PrepareViewPullInfo(ctx, issue)
+	}
+	if ctx.Written() {
+		return
+	} else if prInfo == nil {
+		ctx.NotFound("ViewPullCatalog", nil)
+		return
+	}
+
+	preview, err := door43metadata.PreviewDoor43MetadataForPullRequest(pull, ctx.Repo.GitRepo)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("PreviewDoor43MetadataForPullRequest", err)
+		} else {
+			ctx.ServerError("PreviewDoor43MetadataForPullRequest", err)
+		}
+		return
+	}
+	ctx.Data["CatalogPreview"] = preview
+
+	if preview.Valid() {
+		entry := convert.ToDoor43MetadataV5(preview.Door43Metadata, ctx.Repo.AccessMode)
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		content, err := json.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			ctx.ServerError("MarshalIndent", err)
+			return
+		}
+		ctx.Data["CatalogPreviewEntry"] = string(content)
+	}
+
+	getBranchData(ctx, issue)
+	ctx.HTML(http.StatusOK, tplPullCatalog)
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,80 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for searching by Bible reference ***/
+
+package repo
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/services/scripture"
+)
+
+const tplReference base.TplName = "repo/reference"
+
+// setReferenceSearchLink gives the link to the search by Bible reference if the manifest has USFM or TSV projects
+func setReferenceSearchLink(ctx *context.Context) {
+	manifest, err := readManifest(ctx)
+	if err != nil {
+		log.Debug("Unable to read the manifest of %-v: %v", ctx.Repo.Repository, err)
+		return
+	}
+	projects, _ := manifest["projects"].([]interface{})
+	for _, p := range projects {
+		project, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projectPath, _ := project["path"].(string)
+		switch strings.ToLower(path.Ext(projectPath)) {
+		case ".usfm", ".usfm3", ".sfm", ".tsv":
+			ctx.Data["ReferenceSearchLink"] = ctx.Repo.RepoLink + "/reference?ref=" + url.QueryEscape(ctx.Repo.BranchName)
+			return
+		}
+	}
+}
+
+// SearchReference searches the USFM and TSV files of a repo and its related resources by Bible reference
+func SearchReference(ctx *context.Context) {
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	ref := strings.TrimSpace(ctx.Query("ref"))
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.reference_search") + " - " + ctx.Repo.Repository.FullName()
+	ctx.Data["PageIsViewCode"] = true
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["ReferenceRef"] = ref
+
+	if keyword != "" {
+		reference, err := dcs.ParseReference(keyword)
+		if err != nil {
+			ctx.Data["ReferenceError"] = err.Error()
+			ctx.HTML(200, tplReference)
+			return
+		}
+		result, err := scripture.Search(ctx.User, ctx.Repo.Repository, ctx.Repo.GitRepo, ref, reference, true)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				ctx.NotFound("Search", err)
+			} else {
+				ctx.ServerError("Search", err)
+			}
+			return
+		}
+		ctx.Data["ReferenceResult"] = result
+	}
+	ctx.HTML(200, tplReference)
+}
+
+/*** END DCS Customizations ***/
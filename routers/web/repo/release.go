@@ -380,6 +380,10 @@ func NewReleasePost(ctx *context.Context) {
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_invalid"), tplReleaseNew, &form)
 			case models.IsErrProtectedTagName(err):
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_protected"), tplReleaseNew, &form)
+			/*** DCS Customizations ***/
+			case models.IsErrInconsistentManifest(err):
+				ctx.RenderWithErr(ctx.Tr("repo.release.manifest_inconsistent", strings.Join(err.(models.ErrInconsistentManifest).Issues, "; ")), tplReleaseNew, &form)
+			/*** END DCS Customizations ***/
 			default:
 				ctx.ServerError("CreateRelease", err)
 			}
@@ -402,6 +406,12 @@ func NewReleasePost(ctx *context.Context) {
 
 		if err = releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, attachmentUUIDs, nil, nil); err != nil {
 			ctx.Data["Err_TagName"] = true
+			/*** DCS Customizations ***/
+			if models.IsErrInconsistentManifest(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.release.manifest_inconsistent", strings.Join(err.(models.ErrInconsistentManifest).Issues, "; ")), tplReleaseNew, &form)
+				return
+			}
+			/*** END DCS Customizations ***/
 			ctx.ServerError("UpdateRelease", err)
 			return
 		}
@@ -504,6 +514,12 @@ func EditReleasePost(ctx *context.Context) {
 	rel.IsPrerelease = form.Prerelease
 	if err = releaseservice.UpdateRelease(ctx.User, ctx.Repo.GitRepo,
 		rel, addAttachmentUUIDs, delAttachmentUUIDs, editAttachments); err != nil {
+		/*** DCS Customizations ***/
+		if models.IsErrInconsistentManifest(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.release.manifest_inconsistent", strings.Join(err.(models.ErrInconsistentManifest).Issues, "; ")), tplReleaseNew, &form)
+			return
+		}
+		/*** END DCS Customizations ***/
 		ctx.ServerError("UpdateRelease", err)
 		return
 	}
@@ -0,0 +1,45 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for regenerating the HTML and EPUB artifacts of catalog releases ***/
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/artifact"
+)
+
+// RegenerateReleaseArtifacts queues the generation of the HTML and EPUB artifacts of a catalog release
+func RegenerateReleaseArtifacts(ctx *context.Context) {
+	release, err := models.GetReleaseByID(ctx.QueryInt64("id"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound("GetReleaseByID", err)
+		} else {
+			ctx.ServerError("GetReleaseByID", err)
+		}
+		return
+	}
+	if release.IsTag || release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("GetReleaseByID", nil)
+		return
+	}
+
+	if exist, err := models.IsDoor43MetadataExist(release.RepoID, release.ID); err != nil {
+		ctx.ServerError("IsDoor43MetadataExist", err)
+		return
+	} else if !exist || release.IsDraft {
+		ctx.Flash.Error(ctx.Tr("repo.release.artifacts_not_catalog"))
+	} else if err := artifact.StartGeneration(release.ID, true); err != nil {
+		ctx.ServerError("StartGeneration", err)
+		return
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.release.artifacts_queued", release.TagName))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/releases")
+}
+
+/*** END DCS Customizations ***/
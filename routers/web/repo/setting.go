@@ -60,6 +60,24 @@ func Settings(ctx *context.Context) {
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
 
+	/*** DCS Customizations ***/
+	if ctx.Repo.IsOwner() {
+		rules, err := scrubber.GetRules(ctx.Repo.Repository)
+		if err != nil {
+			ctx.ServerError("GetRules", err)
+			return
+		}
+		ctx.Data["ScrubRules"] = rules
+	}
+	sensitiveData, err := models.GetSensitiveDataByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetSensitiveDataByRepoID", err)
+		return
+	}
+	ctx.Data["SensitiveData"] = sensitiveData
+	ctx.Data["DefaultSensitiveDataCheck"] = models.ToSensitiveDataCheck(setting.DCS.Scrubber.PushCheck).String()
+	/*** END DCS Customizations ***/
+
 	ctx.HTML(http.StatusOK, tplSettingsOptions)
 }
 
@@ -469,6 +487,22 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	/*** DCS Customizations ***/
+	case "sensitive_data":
+		check := models.ToSensitiveDataCheck(form.SensitiveDataCheck)
+		if check != repo.SensitiveDataCheck {
+			repo.SensitiveDataCheck = check
+			if err := models.UpdateRepositoryCols(repo, "sensitive_data_check"); err != nil {
+				ctx.ServerError("UpdateRepositoryCols", err)
+				return
+			}
+		}
+		log.Trace("Repository sensitive data settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+	/*** END DCS Customizations ***/
+
 	case "admin":
 		if !ctx.User.IsAdmin {
 			ctx.Error(http.StatusForbidden)
@@ -741,8 +775,7 @@ func SettingsPost(ctx *context.Context) {
 			}
 		}
 
-		if err := scrubber.ScrubSensitiveData(repo, ctx.User, scrubber.ScrubSensitiveDataOptions{
-			LastCommitID:  ctx.Repo.CommitID,
+		if _, err := scrubber.ScrubSensitiveData(repo, ctx.User, scrubber.ScrubSensitiveDataOptions{
 			CommitMessage: ctx.Tr("repo.settings.scrub_commit_message")}); err != nil {
 			log.Error("%v", err)
 			ctx.Flash.Error(ctx.Tr("repo.settings.scrub_error"))
@@ -0,0 +1,265 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for the navigation of Translation Academy manuals ***/
+
+package repo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const tplTaBook base.TplName = "repo/ta_book"
+
+// taManual is a manual of a Translation Academy repo, i.e. a folder with a toc.yaml file
+type taManual struct {
+	Path  string
+	Title string
+	Toc   *dcs.Toc
+}
+
+// taTocItem is a section of the table of contents of a manual as listed in its navigation tree
+type taTocItem struct {
+	Title string
+	Link  string // empty for a section that isn't an article
+	Depth int
+}
+
+// taBreadcrumb is a level of the breadcrumbs from a manual down to one of its articles
+type taBreadcrumb struct {
+	Title string
+	Link  string // empty for the current article and the sections that aren't articles
+}
+
+// taArticle is a section of a manual as shown when the manual is read as a book
+type taArticle struct {
+	ID       string
+	Title    string
+	SubTitle string
+	Depth    int
+	Content  string
+}
+
+// readTreeFile reads a file of the current commit, returning nil if it does not exist
+func readTreeFile(ctx *context.Context, treePath string) ([]byte, error) {
+	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if entry.IsDir() {
+		return nil, nil
+	}
+	dataRc, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer dataRc.Close()
+	return ioutil.ReadAll(dataRc)
+}
+
+// getTaManual returns the manual of the folder of the current commit, or nil if the repo isn't a
+// Translation Academy repo or the folder has no toc.yaml file
+func getTaManual(ctx *context.Context, manualPath string) (*taManual, error) {
+	data, err := readTreeFile(ctx, path.Join(manualPath, dcs.TocFileName))
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(ctx)
+	if err != nil {
+		log.Debug("Unable to read the manifest of %-v: %v", ctx.Repo.Repository, err)
+		manifest = nil
+	}
+	if !strings.HasSuffix(strings.ToLower(ctx.Repo.Repository.Name), "_ta") &&
+		dcs.GetDublinCoreString(&manifest, "subject") != dcs.Subjects["ta"] {
+		return nil, nil
+	}
+
+	toc, err := dcs.ParseToc(data)
+	if err != nil {
+		return nil, err
+	}
+	manual := &taManual{Path: manualPath, Title: strings.Title(path.Base(manualPath)), Toc: toc}
+	// The manifest has the title of each manual
+	if projects, ok := manifest["projects"].([]interface{}); ok {
+		for _, p := range projects {
+			project, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if projectPath, _ := project["path"].(string); path.Clean(projectPath) == path.Clean(manualPath) {
+				if title, _ := project["title"].(string); title != "" {
+					manual.Title = title
+				}
+				break
+			}
+		}
+	}
+	return manual, nil
+}
+
+// refSrcLink returns the link to a file or folder of the current ref
+func refSrcLink(ctx *context.Context, treePath string) string {
+	return ctx.Repo.RepoLink + "/src/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL()) + "/" + util.PathEscapeSegments(treePath)
+}
+
+// taArticleLink returns the link to the text of an article of a manual
+func taArticleLink(ctx *context.Context, manual *taManual, link string) string {
+	return refSrcLink(ctx, path.Join(manual.Path, link, "01.md"))
+}
+
+// renderTaToc prepares the navigation tree of a manual from its toc.yaml file,
+// returning false if the file isn't the toc.yaml of a Translation Academy manual
+func renderTaToc(ctx *context.Context) bool {
+	manualPath := path.Dir(ctx.Repo.TreePath)
+	manual, err := getTaManual(ctx, manualPath)
+	if err != nil {
+		// It is shown as any other YAML file, with the error
+		log.Debug("Unable to read the manual %s of %-v: %v", manualPath, ctx.Repo.Repository, err)
+		return false
+	}
+	if manual == nil {
+		return false
+	}
+
+	entries := manual.Toc.Entries()
+	items := make([]*taTocItem, 0, len(entries))
+	for _, entry := range entries {
+		item := &taTocItem{Title: entry.Title, Depth: entry.Depth}
+		if entry.Link != "" {
+			item.Link = taArticleLink(ctx, manual, entry.Link)
+		}
+		items = append(items, item)
+	}
+	ctx.Data["IsTaToc"] = true
+	ctx.Data["TaManualTitle"] = manual.Title
+	ctx.Data["TaTocItems"] = items
+	ctx.Data["TaBookLink"] = ctx.Repo.RepoLink + "/book/" + util.PathEscapeSegments(ctx.Repo.BranchNameSubURL()) + "/" + util.PathEscapeSegments(manualPath)
+	return true
+}
+
+// renderTaBreadcrumbs prepares the breadcrumbs from a manual down to the article of the current file
+// if it is a file of an article of a Translation Academy manual, e.g. translate/figs-metaphor/01.md
+func renderTaBreadcrumbs(ctx *context.Context) {
+	parts := strings.Split(ctx.Repo.TreePath, "/")
+	if len(parts) < 3 || !util.IsStringInSlice(parts[len(parts)-1], dcs.TaArticleFiles) {
+		return
+	}
+	manualPath := path.Join(parts[:len(parts)-2]...)
+	link := parts[len(parts)-2]
+	manual, err := getTaManual(ctx, manualPath)
+	if err != nil {
+		log.Debug("Unable to read the manual %s of %-v: %v", manualPath, ctx.Repo.Repository, err)
+		return
+	}
+	if manual == nil {
+		return
+	}
+	sections := manual.Toc.Path(link)
+	if sections == nil {
+		return
+	}
+
+	breadcrumbs := make([]*taBreadcrumb, 0, len(sections)+1)
+	breadcrumbs = append(breadcrumbs, &taBreadcrumb{Title: manual.Title, Link: refSrcLink(ctx, path.Join(manualPath, dcs.TocFileName))})
+	for i, section := range sections {
+		breadcrumb := &taBreadcrumb{Title: section.Title}
+		if section.Link != "" && (i < len(sections)-1 || parts[len(parts)-1] != "01.md") {
+			breadcrumb.Link = taArticleLink(ctx, manual, section.Link)
+		}
+		breadcrumbs = append(breadcrumbs, breadcrumb)
+	}
+	ctx.Data["TaBreadcrumbs"] = breadcrumbs
+}
+
+// TaBook renders all the articles of a Translation Academy manual in the order of its table of contents as one page
+func TaBook(ctx *context.Context) {
+	manualPath := ctx.Repo.TreePath
+	manual, err := getTaManual(ctx, manualPath)
+	if err != nil {
+		ctx.ServerError("getTaManual", err)
+		return
+	}
+	if manual == nil {
+		ctx.NotFound("TaBook", nil)
+		return
+	}
+
+	entries := manual.Toc.Entries()
+	items := make([]*taTocItem, 0, len(entries))
+	articles := make([]*taArticle, 0, len(entries))
+	for _, entry := range entries {
+		item := &taTocItem{Title: entry.Title, Depth: entry.Depth}
+		article := &taArticle{Title: entry.Title, Depth: entry.Depth}
+		if entry.Link != "" {
+			item.Link = "#" + entry.Link
+			article.ID = entry.Link
+			if err := readTaArticle(ctx, manual, entry.Link, article); err != nil {
+				ctx.ServerError("readTaArticle", err)
+				return
+			}
+		}
+		items = append(items, item)
+		articles = append(articles, article)
+	}
+
+	ctx.Data["Title"] = manual.Title + " - " + ctx.Repo.Repository.FullName()
+	ctx.Data["PageIsViewCode"] = true
+	ctx.Data["TaManualTitle"] = manual.Title
+	ctx.Data["TaTocLink"] = refSrcLink(ctx, path.Join(manualPath, dcs.TocFileName))
+	ctx.Data["TaTocItems"] = items
+	ctx.Data["TaArticles"] = articles
+	ctx.HTML(http.StatusOK, tplTaBook)
+}
+
+// readTaArticle reads the title, sub-title and rendered text of an article of a manual
+func readTaArticle(ctx *context.Context, manual *taManual, link string, article *taArticle) error {
+	articlePath := path.Join(manual.Path, link)
+	title, err := readTreeFile(ctx, path.Join(articlePath, "title.md"))
+	if err != nil {
+		return err
+	}
+	if title := strings.TrimSpace(string(title)); title != "" {
+		article.Title = title
+	}
+	subTitle, err := readTreeFile(ctx, path.Join(articlePath, "sub-title.md"))
+	if err != nil {
+		return err
+	}
+	article.SubTitle = strings.TrimSpace(string(subTitle))
+
+	content, err := readTreeFile(ctx, path.Join(articlePath, "01.md"))
+	if err != nil || content == nil {
+		return err
+	}
+	var result strings.Builder
+	if err := markup.Render(&markup.RenderContext{
+		Ctx:       ctx,
+		Filename:  "01.md",
+		URLPrefix: refSrcLink(ctx, articlePath),
+		Metas:     ctx.Repo.Repository.ComposeDocumentMetas(),
+		GitRepo:   ctx.Repo.GitRepo,
+	}, bytes.NewReader(content), &result); err != nil {
+		return err
+	}
+	article.Content = result.String()
+	return nil
+}
+
+/*** END DCS Customizations ***/
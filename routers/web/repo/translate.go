@@ -0,0 +1,105 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Router for translating a catalog entry into a new repo ***/
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const tplTranslate base.TplName = "repo/translate"
+
+// getCatalogEntryToTranslate gets the catalog entry of the repo at the ref of the query, or its latest one
+func getCatalogEntryToTranslate(ctx *context.Context, ref string) *models.Door43Metadata {
+	dm, err := repo_service.GetCatalogEntryToTranslate(ctx.Repo.Repository, ref)
+	if err != nil {
+		if models.IsErrDoor43MetadataNotExist(err) {
+			ctx.NotFound("GetCatalogEntryToTranslate", err)
+		} else {
+			ctx.ServerError("GetCatalogEntryToTranslate", err)
+		}
+		return nil
+	}
+	ctx.Data["TranslateEntry"] = dm
+	ctx.Data["TranslateFrom"] = ctx.Repo.Repository.FullName() + " " + dm.BranchOrTag
+	return dm
+}
+
+// Translate renders the page to create a repo to translate a catalog entry of the repo into a language
+func Translate(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.translate")
+	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
+
+	ctxUser := checkContextUser(ctx, ctx.QueryInt64("uid"))
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ContextUser"] = ctxUser
+
+	dm := getCatalogEntryToTranslate(ctx, ctx.Query("ref"))
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ref"] = dm.BranchOrTag
+	ctx.Data["description"] = ctx.Repo.Repository.Description
+
+	ctx.HTML(http.StatusOK, tplTranslate)
+}
+
+// TranslatePost creates a repo to translate a catalog entry of the repo into a language
+func TranslatePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.TranslateRepoForm)
+	ctx.Data["Title"] = ctx.Tr("repo.translate")
+	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
+
+	ctxUser := checkContextUser(ctx, form.UID)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ContextUser"] = ctxUser
+
+	dm := getCatalogEntryToTranslate(ctx, form.Ref)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplTranslate)
+		return
+	}
+	language := strings.ToLower(strings.TrimSpace(form.Language))
+	if !dcs.IsValidLanguage(language) {
+		ctx.Data["Err_Language"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.translate.unknown_language", language), tplTranslate, form)
+		return
+	}
+
+	repo, err := repo_service.TranslateCatalogEntry(ctx.User, ctxUser, dm, repo_service.TranslateOptions{
+		Language:    language,
+		Description: form.Description,
+		Private:     form.Private || setting.Repository.ForcePrivate,
+	})
+	if err != nil {
+		handleCreateError(ctx, ctxUser, err, "TranslatePost", tplTranslate, form)
+		return
+	}
+
+	log.Trace("Repository created to translate %s [%d]: %s/%s", ctx.Repo.Repository.FullName(), repo.ID, ctxUser.Name, repo.Name)
+	ctx.Redirect(ctxUser.HomeLink() + "/" + repo.Name)
+}
+
+/*** END DCS Customizations ***/
@@ -23,15 +23,16 @@ import (
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/dcs" // DCS Customizations
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/highlight"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
+	yaml_markup "code.gitea.io/gitea/modules/markup/yaml" // DCS Customizations
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/typesniffer"
-	"code.gitea.io/gitea/modules/yaml" // DCS Customizations
 )
 
 const (
@@ -392,14 +393,24 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 	/*** DCS Customizations ***/
 	if ctx.Repo.TreePath == "" {
 		if entry, _ := tree.GetTreeEntryByPath("manifest.yaml"); entry != nil {
+			ctx.Data["HasManifest"] = true
 			if result, err := base.ValidateManifestTreeEntry(entry); err != nil {
 				fmt.Printf("ValidateManifestTreeEntry: %v\n", err)
 			} else {
 				ctx.Data["ValidateManifestResult"] = result
 				ctx.Data["ValidateManifestResultErrors"] = base.StringifyValidationErrors(result)
 			}
+		} else if ctx.Data["CanAddFile"] == true {
+			ctx.Data["CanGenerateManifest"] = true
 		}
 	}
+	if !strings.Contains(ctx.Repo.TreePath, "/") {
+		setOBSReaderLink(ctx)
+	}
+	if ctx.Repo.TreePath == "" {
+		setReferenceSearchLink(ctx)
+		setBookProgresses(ctx)
+	}
 	/*** END DCS Customizations ***/
 	ctx.Data["SSHDomain"] = setting.SSH.Domain
 }
@@ -420,6 +431,10 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 	ctx.Data["FileIsSymlink"] = entry.IsLink()
 	ctx.Data["FileName"] = blob.Name()
 	ctx.Data["RawFileLink"] = rawLink + "/" + ctx.Repo.TreePath
+	/*** DCS Customizations ***/
+	renderTaBreadcrumbs(ctx)
+	setParallelViewLink(ctx)
+	/*** END DCS Customizations ***/
 
 	buf := make([]byte, 1024)
 	n, _ := dataRc.Read(buf)
@@ -521,10 +536,21 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 		readmeExist := markup.IsReadmeFile(blob.Name())
 		ctx.Data["ReadmeExist"] = readmeExist
 		/*** DCS Customizations ***/
-		isTocYaml := blob.Name() == "toc.yaml"
-		ctx.Data["IsTocreaYaml"] = isTocYaml
-		/*** END DCS Customizations ***/
-		if markupType := markup.Type(blob.Name()); markupType != "" {
+		markupType := markup.Type(blob.Name())
+		isTaToc := false
+		if markupType == yaml_markup.MarkupName {
+			// YAML files are rendered as tables, but can be viewed as source too
+			ctx.Data["HasSourceRenderedToggle"] = true
+			if isDisplayingSource {
+				markupType = ""
+			} else if blob.Name() == dcs.TocFileName && renderTaToc(ctx) {
+				// The toc.yaml of a Translation Academy manual is rendered as its navigation tree
+				isTaToc = true
+				markupType = ""
+			}
+		}
+		if markupType != "" {
+			/*** END DCS Customizations ***/
 			ctx.Data["IsMarkup"] = true
 			ctx.Data["MarkupType"] = markupType
 			var result strings.Builder
@@ -541,16 +567,8 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 			}
 			ctx.Data["FileContent"] = result.String()
 			/*** DCS Customizations ***/
-		} else if isTocYaml {
-			ctx.Data["IsRenderedHTML"] = true
-			if rendered, err := yaml.Render(buf); err != nil {
-				log.Error("RenderYaml: %v", err)
-				ctx.Flash.ErrorMsg = fmt.Sprintf("Unable to parse %v", err)
-				ctx.Data["Flash"] = ctx.Flash
-				ctx.Data["FileContent"] = string(buf)
-			} else {
-				ctx.Data["FileContent"] = string(rendered)
-			}
+		} else if isTaToc {
+			// Nothing more to read, the navigation tree is ready
 			/*** END DCS Customizations ***/
 		} else if readmeExist {
 			buf, _ := ioutil.ReadAll(rd)
@@ -414,6 +414,9 @@ func RegisterRoutes(m *web.Route) {
 		m.Group("/repos", func() {
 			m.Get("", admin.Repos)
 			m.Combo("/unadopted").Get(admin.UnadoptedRepos).Post(admin.AdoptOrDeleteRepository)
+			/*** DCS Customizations ***/
+			m.Get("/sensitive_data", admin.SensitiveData)
+			/*** END DCS Customizations ***/
 			m.Post("/delete", admin.DeleteRepo)
 		})
 
@@ -562,6 +565,11 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/initialize", bindIgnErr(forms.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				/*** DCS Customizations ***/
+				m.Combo("/scrub_rules").Get(org.ScrubRules).
+					Post(bindIgnErr(forms.ScrubRulesForm{}), org.ScrubRulesPost)
+				/*** END DCS Customizations ***/
+
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -574,6 +582,10 @@ func RegisterRoutes(m *web.Route) {
 		m.Post("/create", bindIgnErr(forms.CreateRepoForm{}), repo.CreatePost)
 		m.Get("/migrate", repo.Migrate)
 		m.Post("/migrate", bindIgnErr(forms.MigrateRepoForm{}), repo.MigratePost)
+		/*** DCS Customizations ***/
+		m.Get("/import", repo.ImportRepo)
+		m.Post("/import", bindIgnErr(forms.ImportArchiveRepoForm{}), repo.ImportRepoPost)
+		/*** END DCS Customizations ***/
 		m.Group("/fork", func() {
 			m.Combo("/{repoid}").Get(repo.Fork).
 				Post(bindIgnErr(forms.CreateRepoForm{}), repo.ForkPost)
@@ -772,6 +784,10 @@ func RegisterRoutes(m *web.Route) {
 				m.Combo("/_upload/*", repo.MustBeAbleToUpload).
 					Get(repo.UploadFile).
 					Post(bindIgnErr(forms.UploadRepoFileForm{}), repo.UploadFilePost)
+				/*** DCS Customizations ***/
+				m.Combo("/_edit_manifest/*").Get(repo.EditManifest).
+					Post(bindIgnErr(forms.EditManifestForm{}), repo.EditManifestPost)
+				/*** END DCS Customizations ***/
 			}, context.RepoRefByType(context.RepoRefBranch), repo.MustBeEditable)
 			m.Group("", func() {
 				m.Post("/upload-file", repo.UploadFileToServer)
@@ -807,6 +823,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/delete", repo.DeleteRelease)
 			m.Post("/attachments", repo.UploadReleaseAttachment)
 			m.Post("/attachments/remove", repo.DeleteAttachment)
+			m.Post("/artifacts", adminReq, repo.RegenerateReleaseArtifacts) // DCS Customizations
 		}, reqSignIn, repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoReleaseWriter, context.RepoRef())
 		m.Post("/tags/delete", repo.DeleteTag, reqSignIn,
 			repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoCodeWriter, context.RepoRef())
@@ -917,6 +934,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Get(".diff", repo.DownloadPullDiff)
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
+			m.Get("/catalog", context.RepoRef(), repo.ViewPullCatalog) // DCS Customizations
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(forms.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
@@ -962,6 +980,29 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.RefBlame)
 		}, repo.MustBeNotEmpty, reqRepoCodeReader)
 
+		/*** DCS Customizations ***/
+		m.Group("/book", func() {
+			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.TaBook)
+			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.TaBook)
+			m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.TaBook)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+		m.Group("/parallel", func() {
+			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.ParallelView)
+			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.ParallelView)
+			m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.ParallelView)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+		m.Group("/obs", func() {
+			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.OBSReader)
+			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.OBSReader)
+			m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.OBSReader)
+			m.Group("/export", func() {
+				m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.OBSExport)
+				m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.OBSExport)
+				m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.OBSExport)
+			})
+		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+		/*** END DCS Customizations ***/
+
 		m.Group("", func() {
 			m.Get("/graph", repo.Graph)
 			m.Get("/commit/{sha:([a-f0-9]{7,40})$}", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.Diff)
@@ -985,6 +1026,13 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/stars", repo.Stars)
 		m.Get("/watchers", repo.Watchers)
 		m.Get("/search", reqRepoCodeReader, repo.Search)
+		/*** DCS Customizations ***/
+		m.Get("/reference", repo.MustBeNotEmpty, reqRepoCodeReader, repo.SearchReference)
+		m.Combo("/translate", reqSignIn, repo.MustBeNotEmpty, reqRepoCodeReader).Get(repo.Translate).
+			Post(bindIgnErr(forms.TranslateRepoForm{}), repo.TranslatePost)
+		m.Combo("/import", reqSignIn, reqRepoCodeWriter, repo.MustBeEditable, context.RepoMustNotBeArchived()).Get(repo.ImportArchive).
+			Post(bindIgnErr(forms.ImportArchiveForm{}), repo.ImportArchivePost)
+		/*** END DCS Customizations ***/
 	}, ignSignIn, context.RepoAssignment, context.RepoRef(), context.UnitTypes())
 
 	m.Group("/{username}", func() {
@@ -1044,6 +1092,7 @@ func RegisterRoutes(m *web.Route) {
 	m.Get("/about", dcs.About)
 	m.Group("/catalog", func() {
 		m.Get("", dcs.Catalog)
+		m.Get("/stats", dcs.CatalogStats)
 	}, ignSignIn)
 	/*** END DCS Customizations ***/
 }
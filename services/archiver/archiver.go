@@ -48,6 +48,11 @@ func NewRequest(repoID int64, repo *git.Repository, uri string) (*ArchiveRequest
 
 	var ext string
 	switch {
+	/*** DCS Customizations ***/
+	case strings.HasSuffix(uri, ".rc.zip"):
+		ext = ".rc.zip"
+		r.Type = git.RCZIP
+	/*** END DCS Customizations ***/
 	case strings.HasSuffix(uri, ".zip"):
 		ext = ".zip"
 		r.Type = git.ZIP
@@ -84,6 +89,22 @@ func NewRequest(repoID int64, repo *git.Repository, uri string) (*ArchiveRequest
 		return nil, fmt.Errorf("Unknow ref %s type", r.refName)
 	}
 
+	/*** DCS Customizations ***/
+	if r.Type == git.RCZIP {
+		commit, err := repo.GetCommit(r.CommitID)
+		if err != nil {
+			return nil, err
+		}
+		// Only a resource container can be exported, which a ref without a manifest.yaml is not
+		if _, err := commit.GetBlobByPath("manifest.yaml"); err != nil {
+			if git.IsErrNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+	/*** END DCS Customizations ***/
+
 	return r, nil
 }
 
@@ -165,13 +186,19 @@ func doArchive(r *ArchiveRequest) (*models.RepoArchiver, error) {
 			}
 		}()
 
-		err = gitRepo.CreateArchive(
-			graceful.GetManager().ShutdownContext(),
-			archiver.Type,
-			w,
-			setting.Repository.PrefixArchiveFiles,
-			archiver.CommitID,
-		)
+		/*** DCS Customizations ***/
+		if archiver.Type == git.RCZIP {
+			err = CreateRCArchive(gitRepo, w, setting.Repository.PrefixArchiveFiles, archiver.CommitID)
+		} else {
+			/*** END DCS Customizations ***/
+			err = gitRepo.CreateArchive(
+				graceful.GetManager().ShutdownContext(),
+				archiver.Type,
+				w,
+				setting.Repository.PrefixArchiveFiles,
+				archiver.CommitID,
+			)
+		} // DCS Customizations
 		_ = w.CloseWithError(err)
 		done <- err
 	}(done, w, archiver, gitRepo)
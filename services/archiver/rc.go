@@ -0,0 +1,184 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Normalized resource container archives ***/
+
+package archiver
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/ghodss/yaml"
+)
+
+// RCChecksumsFile is the name of the file of an RC archive listing the SHA-256 checksums of its files
+const RCChecksumsFile = dcs.ChecksumsFile
+
+// rcRootFiles are the files at the root of a resource container that are always exported
+var rcRootFiles = []string{"manifest.yaml", "LICENSE.md", "media.yaml"}
+
+// rcRootDirs are the directories at the root of a resource container that are always exported
+var rcRootDirs = []string{"media"}
+
+// readRCManifest reads the manifest.yaml file of a commit
+func readRCManifest(commit *git.Commit) (map[string]interface{}, error) {
+	blob, err := commit.GetBlobByPath("manifest.yaml")
+	if err != nil {
+		return nil, err
+	}
+	reader, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	manifest := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest.yaml is invalid: %v", err)
+	}
+	return manifest, nil
+}
+
+// rcProjectPaths returns the cleaned paths of the projects of a manifest
+func rcProjectPaths(manifest map[string]interface{}) []string {
+	projects, ok := manifest["projects"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, p := range projects {
+		project, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projectPath, ok := project["path"].(string)
+		if !ok || projectPath == "" {
+			continue
+		}
+		projectPath = strings.TrimPrefix(path.Clean("/"+projectPath), "/")
+		if projectPath == "" {
+			projectPath = "."
+		}
+		paths = append(paths, projectPath)
+	}
+	return paths
+}
+
+// isRCExportPath returns whether a file of a tree is exported in the RC archive of the given project paths.
+// Hidden files and directories are never exported.
+func isRCExportPath(treePath string, projectPaths []string) bool {
+	for _, part := range strings.Split(treePath, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	for _, name := range rcRootFiles {
+		if strings.EqualFold(treePath, name) {
+			return true
+		}
+	}
+	for _, dir := range rcRootDirs {
+		if strings.HasPrefix(treePath, dir+"/") {
+			return true
+		}
+	}
+	for _, projectPath := range projectPaths {
+		if projectPath == "." || treePath == projectPath || strings.HasPrefix(treePath, projectPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateRCArchive writes a zip of the resource container of a commit to the target: only the files of
+// the projects of its manifest, the manifest, the license and the media, and a checksums file.
+func CreateRCArchive(gitRepo *git.Repository, target io.Writer, usePrefix bool, commitID string) error {
+	commit, err := gitRepo.GetCommit(commitID)
+	if err != nil {
+		return err
+	}
+	manifest, err := readRCManifest(commit)
+	if err != nil {
+		return err
+	}
+	projectPaths := rcProjectPaths(manifest)
+
+	entries, err := commit.Tree.ListEntriesRecursive()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	prefix := ""
+	if usePrefix {
+		prefix = filepath.Base(strings.TrimSuffix(gitRepo.Path, ".git")) + "/"
+	}
+
+	zipWriter := zip.NewWriter(target)
+	checksums := new(strings.Builder)
+	for _, entry := range entries {
+		if !entry.IsRegular() && !entry.IsExecutable() {
+			continue
+		}
+		if !isRCExportPath(entry.Name(), projectPaths) {
+			continue
+		}
+		header := &zip.FileHeader{
+			Name:     prefix + entry.Name(),
+			Method:   zip.Deflate,
+			Modified: commit.Committer.When,
+		}
+		header.SetMode(0644)
+		if entry.IsExecutable() {
+			header.SetMode(0755)
+		}
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		_, err = io.Copy(io.MultiWriter(writer, hash), reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(checksums, "%s  %s\n", hex.EncodeToString(hash.Sum(nil)), entry.Name())
+	}
+
+	writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     prefix + RCChecksumsFile,
+		Method:   zip.Deflate,
+		Modified: commit.Committer.When,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, checksums.String()); err != nil {
+		return err
+	}
+	return zipWriter.Close()
+}
+
+/*** END DCS Customizations ***/
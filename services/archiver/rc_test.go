@@ -0,0 +1,129 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package archiver
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRCProjectPaths(t *testing.T) {
+	manifest := map[string]interface{}{
+		"projects": []interface{}{
+			map[string]interface{}{"identifier": "gen", "path": "./01-GEN.usfm"},
+			map[string]interface{}{"identifier": "intro", "path": "intro/"},
+			map[string]interface{}{"identifier": "escape", "path": "../../etc"},
+			map[string]interface{}{"identifier": "nopath"},
+		},
+	}
+	assert.Equal(t, []string{"01-GEN.usfm", "intro", "etc"}, rcProjectPaths(manifest))
+	assert.Nil(t, rcProjectPaths(map[string]interface{}{}))
+}
+
+func TestIsRCExportPath(t *testing.T) {
+	projectPaths := []string{"01-GEN.usfm", "intro"}
+	for treePath, expected := range map[string]bool{
+		"manifest.yaml":         true,
+		"LICENSE.md":            true,
+		"media.yaml":            true,
+		"media/cover.jpg":       true,
+		"01-GEN.usfm":           true,
+		"intro/01/01.md":        true,
+		"intro/.DS_Store":       false,
+		"introduction.md":       false,
+		"02-EXO.usfm":           false,
+		"README.md":             false,
+		".gitignore":            false,
+		".github/workflows/a.y": false,
+	} {
+		assert.Equal(t, expected, isRCExportPath(treePath, projectPaths), treePath)
+	}
+	assert.True(t, isRCExportPath("content/01.md", []string{"."}))
+	assert.False(t, isRCExportPath(".gitea/template", []string{"."}))
+}
+
+func TestNewRequest_RCZip(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	ctx := test.MockContext(t, "user27/repo49")
+	test.LoadRepo(t, ctx, 49)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	// The repository has no manifest.yaml file to export a resource container from, so no request to be found
+	req, err := NewRequest(ctx.Repo.Repository.ID, ctx.Repo.GitRepo, "master.rc.zip")
+	assert.NoError(t, err)
+	assert.Nil(t, req)
+}
+
+func TestCreateRCArchive(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "rc-archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"manifest.yaml":      "dublin_core:\n  identifier: ult\nprojects:\n  - identifier: tit\n    path: ./57-TIT.usfm\n",
+		"LICENSE.md":         "CC BY-SA 4.0",
+		"57-TIT.usfm":        "\\id TIT",
+		"58-PHM.usfm":        "\\id PHM",
+		".gitignore":         "*.bak",
+		".github/ci.yml":     "on: push",
+		"media/cover.png":    "png",
+		"tools/build.sh":     "make",
+		"README.md":          "# ULT",
+		"nested/LICENSE.md":  "nested",
+		"nested/manifest.md": "nested",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, name)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+	assert.NoError(t, git.InitRepository(tmpDir, false))
+	_, err = git.NewCommand("add", "--all").RunInDir(tmpDir)
+	assert.NoError(t, err)
+	_, err = git.NewCommand("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "init").RunInDir(tmpDir)
+	assert.NoError(t, err)
+
+	gitRepo, err := git.OpenRepository(tmpDir)
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	commitID, err := gitRepo.GetRefCommitID("HEAD")
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, CreateRCArchive(gitRepo, buf, false, commitID))
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	var names []string
+	var checksums string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		if file.Name == RCChecksumsFile {
+			rc, err := file.Open()
+			assert.NoError(t, err)
+			content, err := ioutil.ReadAll(rc)
+			assert.NoError(t, err)
+			rc.Close()
+			checksums = string(content)
+		}
+	}
+	assert.Equal(t, []string{"57-TIT.usfm", "LICENSE.md", "manifest.yaml", "media/cover.png", RCChecksumsFile}, names)
+	lines := strings.Split(strings.TrimSpace(checksums), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "57-TIT.usfm", strings.Fields(lines[0])[1])
+		assert.Len(t, strings.Fields(lines[0])[0], 64)
+	}
+}
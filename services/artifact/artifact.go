@@ -0,0 +1,232 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - HTML and EPUB artifacts of catalog releases ***/
+
+package artifact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrNotCatalogRelease is returned when the artifacts of a release that isn't in the catalog are requested
+type ErrNotCatalogRelease struct {
+	ReleaseID int64
+}
+
+// IsErrNotCatalogRelease checks if an error is a ErrNotCatalogRelease
+func IsErrNotCatalogRelease(err error) bool {
+	_, ok := err.(ErrNotCatalogRelease)
+	return ok
+}
+
+func (err ErrNotCatalogRelease) Error() string {
+	return fmt.Sprintf("release is not in the catalog [id: %d]", err.ReleaseID)
+}
+
+// Request is a request to generate the artifacts of a release
+type Request struct {
+	ReleaseID int64
+	// Force regenerates the artifacts even if they were generated from the commit of the release
+	Force bool
+}
+
+// ArtifactNamePrefix returns the prefix of the names of the artifacts of a release, e.g. en_ult_v5
+func ArtifactNamePrefix(rel *models.Release) string {
+	return strings.ReplaceAll(rel.Repo.Name+"_"+rel.TagName, "/", "-")
+}
+
+// BookArtifactName returns the name of the HTML artifact of a book of a release, e.g. en_ult_v5_tit.html
+func BookArtifactName(rel *models.Release, book *Book) string {
+	return ArtifactNamePrefix(rel) + "_" + strings.ReplaceAll(book.Identifier, "/", "-") + ".html"
+}
+
+// EPUBArtifactName returns the name of the EPUB artifact of a release, e.g. en_ult_v5.epub
+func EPUBArtifactName(rel *models.Release) string {
+	return ArtifactNamePrefix(rel) + ".epub"
+}
+
+// GenerateReleaseArtifacts renders a single page HTML of each book of a catalog release and an EPUB of the whole
+// resource, and attaches them to the release, replacing the artifacts that were generated before once they are
+// created. Unless forced, nothing is done if the artifacts were already generated from the commit of the release.
+// The attachments uploaded by users are never touched.
+func GenerateReleaseArtifacts(rel *models.Release, force bool) ([]*models.Attachment, error) {
+	if err := rel.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	if rel.IsTag || rel.IsDraft || rel.Door43Metadata == nil {
+		return nil, ErrNotCatalogRelease{ReleaseID: rel.ID}
+	}
+	dm := rel.Door43Metadata
+	dm.Repo = rel.Repo
+	dm.Release = rel
+
+	gitRepo, err := git.OpenRepository(rel.Repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetTagCommit(rel.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	commitID := commit.ID.String()
+
+	var oldArtifacts []*models.Attachment
+	upToDate := true
+	for _, attach := range rel.Attachments {
+		if attach.GeneratedFrom != "" {
+			oldArtifacts = append(oldArtifacts, attach)
+			upToDate = upToDate && attach.GeneratedFrom == commitID
+		}
+	}
+	if !force && upToDate && len(oldArtifacts) > 0 {
+		return oldArtifacts, nil
+	}
+
+	resource, err := RenderResource(dm, gitRepo, commit)
+	if err != nil {
+		return nil, fmt.Errorf("RenderResource: %v", err)
+	}
+
+	attachments, err := createArtifacts(rel, resource, commitID)
+	if err != nil {
+		// The artifacts generated before are kept rather than leaving the release without any
+		if _, delErr := models.DeleteAttachments(models.DefaultDBContext(), attachments, true); delErr != nil {
+			log.Error("DeleteAttachments: %v", delErr)
+		}
+		return nil, err
+	}
+	if _, err := models.DeleteAttachments(models.DefaultDBContext(), oldArtifacts, true); err != nil {
+		return nil, fmt.Errorf("DeleteAttachments: %v", err)
+	}
+	return attachments, nil
+}
+
+// createArtifacts attaches the HTML of each book and the EPUB of a rendered resource to its release, marking them
+// as generated from the commit. It returns the attachments created, even if it fails, so they can be removed.
+func createArtifacts(rel *models.Release, resource *Resource, commitID string) ([]*models.Attachment, error) {
+	if len(resource.Books) == 0 {
+		return nil, nil
+	}
+
+	attachments := make([]*models.Attachment, 0, len(resource.Books)+1)
+	addAttachment := func(name string, content []byte) error {
+		attach, err := models.NewAttachment(&models.Attachment{
+			Name:          name,
+			ReleaseID:     rel.ID,
+			UploaderID:    rel.PublisherID,
+			GeneratedFrom: commitID,
+		}, nil, bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("NewAttachment: %v", err)
+		}
+		attachments = append(attachments, attach)
+		return nil
+	}
+	for _, book := range resource.Books {
+		content, err := renderBookDocument(resource, book, "")
+		if err != nil {
+			return attachments, err
+		}
+		if err := addAttachment(BookArtifactName(rel, book), content); err != nil {
+			return attachments, err
+		}
+	}
+	var epub bytes.Buffer
+	if err := WriteEPUB(&epub, resource, rel.CreatedUnix.AsTime()); err != nil {
+		return attachments, fmt.Errorf("WriteEPUB: %v", err)
+	}
+	if err := addAttachment(EPUBArtifactName(rel), epub.Bytes()); err != nil {
+		return attachments, err
+	}
+	return attachments, nil
+}
+
+var artifactQueue queue.UniqueQueue
+
+// Init starts the queue generating the artifacts of the releases
+func Init() error {
+	handler := func(data ...queue.Data) {
+		for _, datum := range data {
+			req, ok := datum.(*Request)
+			if !ok {
+				log.Error("Unable to process provided datum: %v - not possible to cast to Request", datum)
+				continue
+			}
+			rel, err := models.GetReleaseByID(req.ReleaseID)
+			if err != nil {
+				log.Error("GetReleaseByID [%d]: %v", req.ReleaseID, err)
+				continue
+			}
+			attachments, err := GenerateReleaseArtifacts(rel, req.Force)
+			if err != nil {
+				if !IsErrNotCatalogRelease(err) {
+					log.Error("GenerateReleaseArtifacts [%d]: %v", req.ReleaseID, err)
+				}
+				continue
+			}
+			log.Trace("Generated %d artifacts for release %s of %s", len(attachments), rel.TagName, rel.Repo.FullName())
+		}
+	}
+
+	artifactQueue = queue.CreateUniqueQueue("release-artifacts", handler, new(Request))
+	if artifactQueue == nil {
+		return errors.New("unable to create release artifacts queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(artifactQueue.Run)
+
+	notification.RegisterNotifier(NewNotifier())
+
+	return nil
+}
+
+// StartGeneration pushes the generation of the artifacts of a release to the queue,
+// forcing it to regenerate artifacts that are up to date
+func StartGeneration(releaseID int64, force bool) error {
+	if artifactQueue == nil {
+		return errors.New("release artifacts queue is not initialized")
+	}
+	request := &Request{ReleaseID: releaseID, Force: force}
+	has, err := artifactQueue.Has(request)
+	if err != nil {
+		return err
+	}
+	if has {
+		return nil
+	}
+	return artifactQueue.Push(request)
+}
+
+// NotifyCatalogRelease starts the generation of the artifacts of a release published in the catalog,
+// if they are enabled
+func NotifyCatalogRelease(rel *models.Release) {
+	if !setting.DCS.ReleaseArtifacts || rel.IsTag || rel.IsDraft {
+		return
+	}
+	if exist, err := models.IsDoor43MetadataExist(rel.RepoID, rel.ID); err != nil {
+		log.Error("IsDoor43MetadataExist: %v", err)
+		return
+	} else if !exist {
+		return
+	}
+	if err := StartGeneration(rel.ID, false); err != nil {
+		log.Error("StartGeneration [%d]: %v", rel.ID, err)
+	}
+}
+
+/*** END DCS Customizations ***/
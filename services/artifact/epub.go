@@ -0,0 +1,146 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - HTML and EPUB artifacts of catalog releases ***/
+
+package artifact
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"io"
+	"text/template"
+	"time"
+)
+
+// epubItem is a book of an EPUB with the name of its XHTML document
+type epubItem struct {
+	ID   string
+	Href string
+	Book *Book
+}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+var epubFuncs = template.FuncMap{
+	"xml": html.EscapeString,
+}
+
+var epubPackageTemplate = template.Must(template.New("opf").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id" xml:lang="{{xml .Resource.Language}}" dir="{{.Resource.Direction}}">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="pub-id">{{xml .Resource.URL}}</dc:identifier>
+<dc:title>{{xml .Resource.Title}}</dc:title>
+<dc:language>{{xml .Resource.Language}}</dc:language>
+<dc:publisher>{{xml .Resource.Publisher}}</dc:publisher>
+{{if .Resource.Rights}}<dc:rights>{{xml .Resource.Rights}}</dc:rights>
+{{end}}<meta property="dcterms:modified">{{.Modified}}</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="style" href="style.css" media-type="text/css"/>
+{{range .Items}}<item id="{{.ID}}" href="{{.Href}}" media-type="application/xhtml+xml"/>
+{{end}}</manifest>
+<spine>
+{{range .Items}}<itemref idref="{{.ID}}"/>
+{{end}}</spine>
+</package>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{xml .Resource.Language}}" xml:lang="{{xml .Resource.Language}}" dir="{{.Resource.Direction}}">
+<head>
+<meta charset="utf-8"/>
+<title>{{xml .Resource.Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>{{xml .Resource.Title}}</h1>
+<ol>
+{{range .Items}}<li><a href="{{.Href}}">{{xml .Book.Title}}</a></li>
+{{end}}</ol>
+</nav>
+</body>
+</html>
+`))
+
+// WriteEPUB writes an EPUB 3 of the books of a resource, each book being a document of its spine
+func WriteEPUB(w io.Writer, resource *Resource, modified time.Time) error {
+	items := make([]*epubItem, 0, len(resource.Books))
+	for i, book := range resource.Books {
+		items = append(items, &epubItem{
+			ID:   fmt.Sprintf("book-%02d", i+1),
+			Href: fmt.Sprintf("book-%02d.xhtml", i+1),
+			Book: book,
+		})
+	}
+	data := map[string]interface{}{
+		"Resource": resource,
+		"Items":    items,
+		"Modified": modified.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+
+	zipWriter := zip.NewWriter(w)
+	// The mimetype must be the first file of the archive, stored uncompressed and without extra fields
+	writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	writeFile := func(name string, write func(io.Writer) error) error {
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		return write(writer)
+	}
+	writeString := func(content string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		}
+	}
+	executeTemplate := func(tpl *template.Template) func(io.Writer) error {
+		return func(w io.Writer) error {
+			return tpl.Execute(w, data)
+		}
+	}
+
+	if err := writeFile("META-INF/container.xml", writeString(epubContainer)); err != nil {
+		return err
+	}
+	if err := writeFile("OEBPS/content.opf", executeTemplate(epubPackageTemplate)); err != nil {
+		return err
+	}
+	if err := writeFile("OEBPS/nav.xhtml", executeTemplate(epubNavTemplate)); err != nil {
+		return err
+	}
+	if err := writeFile("OEBPS/style.css", writeString(bookStyle)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		content, err := renderBookDocument(resource, item.Book, "style.css")
+		if err != nil {
+			return err
+		}
+		if err := writeFile("OEBPS/"+item.Href, writeString(string(content))); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+/*** END DCS Customizations ***/
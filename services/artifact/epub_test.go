@@ -0,0 +1,80 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package artifact
+
+import (
+	"archive/zip"
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteEPUB(t *testing.T) {
+	resource := &Resource{
+		Title:     "unfoldingWord Literal Text",
+		Language:  "en",
+		Direction: "ltr",
+		Version:   "5",
+		Publisher: "unfoldingWord",
+		URL:       "https://git.door43.org/unfoldingWord/en_ult/releases/tag/v5",
+		Books: []*Book{
+			{Identifier: "tit", Title: "Titus", Content: template.HTML(`<h1 class="mt1">Titus</h1>`)},
+			{Identifier: "phm", Title: "Philemon & Co", Content: template.HTML(`<h1 class="mt1">Philemon</h1>`)},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteEPUB(&buf, resource, time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Equal(t, "mimetype", reader.File[0].Name)
+	assert.Equal(t, zip.Store, reader.File[0].Method)
+
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	assert.Equal(t, "application/epub+zip", files["mimetype"])
+	assert.Contains(t, files["OEBPS/content.opf"], `<dc:title>unfoldingWord Literal Text</dc:title>`)
+	assert.Contains(t, files["OEBPS/content.opf"], `<meta property="dcterms:modified">2021-06-01T00:00:00Z</meta>`)
+	assert.Contains(t, files["OEBPS/content.opf"], `<itemref idref="book-02"/>`)
+	assert.Contains(t, files["OEBPS/nav.xhtml"], `<li><a href="book-02.xhtml">Philemon &amp; Co</a></li>`)
+	assert.Contains(t, files["OEBPS/book-01.xhtml"], `<link rel="stylesheet" type="text/css" href="style.css"/>`)
+	assert.Contains(t, files["OEBPS/book-01.xhtml"], `<h1 class="mt1">Titus</h1>`)
+}
+
+func TestMarkdownFileLess(t *testing.T) {
+	files := []string{"01/05.md", "front/intro.md", "01/title.md", "01/01.md", "01/intro.md"}
+	sort.Slice(files, func(i, j int) bool {
+		return markdownFileLess(files[i], files[j])
+	})
+	assert.Equal(t, []string{"01/title.md", "01/intro.md", "01/01.md", "01/05.md", "front/intro.md"}, files)
+}
+
+func TestGetManifestProjects(t *testing.T) {
+	manifest := map[string]interface{}{
+		"projects": []interface{}{
+			map[string]interface{}{"identifier": "phm", "title": "Philemon", "path": "./58-PHM.usfm", "sort": float64(58)},
+			map[string]interface{}{"identifier": "tit", "path": "./57-TIT.usfm", "sort": 57},
+			map[string]interface{}{"title": "No identifier"},
+		},
+	}
+	projects := getManifestProjects(&manifest)
+	if assert.Len(t, projects, 2) {
+		assert.Equal(t, &manifestProject{Identifier: "tit", Title: "tit", Path: "57-TIT.usfm", Sort: 57}, projects[0])
+		assert.Equal(t, &manifestProject{Identifier: "phm", Title: "Philemon", Path: "58-PHM.usfm", Sort: 58}, projects[1])
+	}
+	assert.Nil(t, getManifestProjects(nil))
+}
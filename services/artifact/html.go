@@ -0,0 +1,321 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - HTML and EPUB artifacts of catalog releases ***/
+
+package artifact
+
+import (
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/util"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Book is a project of the manifest of a resource rendered as an XHTML fragment
+type Book struct {
+	Identifier string
+	Title      string
+	Content    template.HTML
+}
+
+// Resource is the resource of a catalog release with its rendered books
+type Resource struct {
+	Identifier string
+	Title      string
+	Language   string
+	Direction  string
+	Version    string
+	Publisher  string
+	Rights     string
+	URL        string
+	Books      []*Book
+}
+
+// bookStyle is the style of the HTML of the books
+const bookStyle = `body { font-family: serif; line-height: 1.5; margin: 1em auto; max-width: 40em; padding: 0 1em; }
+h1, h2, h3 { font-family: sans-serif; }
+h2.c { margin-top: 1.5em; }
+sup.v { color: #777; font-size: 0.7em; margin-right: 0.2em; }
+p.q, p.q1 { margin: 0 0 0 2em; }
+p.q2 { margin: 0 0 0 3em; }
+span.nd { font-variant: small-caps; }
+span.wj { color: #900; }
+span.footnote { color: #555; font-size: 0.8em; font-style: italic; }
+span.footnote::before { content: " ["; }
+span.footnote::after { content: "] "; }
+img { max-width: 100%; }
+`
+
+var bookTemplate = template.Must(template.New("book").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Resource.Language}}" xml:lang="{{.Resource.Language}}" dir="{{.Resource.Direction}}">
+<head>
+<meta charset="utf-8"/>
+<title>{{.Book.Title}} - {{.Resource.Title}}</title>
+{{if .Stylesheet}}<link rel="stylesheet" type="text/css" href="{{.Stylesheet}}"/>{{else}}<style>{{.Style}}</style>{{end}}
+</head>
+<body>
+<section epub:type="chapter" id="book-{{.Book.Identifier}}">
+<p class="resource">{{.Resource.Title}}{{if .Resource.Version}} v{{.Resource.Version}}{{end}}</p>
+{{.Book.Content}}
+</section>
+</body>
+</html>
+`))
+
+// renderBookDocument renders a book as a standalone XHTML document, with its style inline or in the given stylesheet
+func renderBookDocument(resource *Resource, book *Book, stylesheet string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := bookTemplate.Execute(&buf, map[string]interface{}{
+		"Resource":   resource,
+		"Book":       book,
+		"Stylesheet": stylesheet,
+		"Style":      template.CSS(bookStyle),
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toXHTML serializes an HTML fragment as XHTML, closing its void elements and quoting its attributes
+func toXHTML(fragment string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		if err := html.Render(&buf, node); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+// markdownFileLess orders the markdown files of a directory as they are read: the title, the introduction,
+// then the other files such as the chunks of a chapter by their names
+func markdownFileLess(a, b string) bool {
+	rank := func(name string) int {
+		switch strings.TrimSuffix(path.Base(name), ".md") {
+		case "title":
+			return 0
+		case "sub-title":
+			return 1
+		case "intro":
+			return 2
+		}
+		return 3
+	}
+	dirA, dirB := path.Dir(a), path.Dir(b)
+	if dirA != dirB {
+		return dirA < dirB
+	}
+	if rankA, rankB := rank(a), rank(b); rankA != rankB {
+		return rankA < rankB
+	}
+	return a < b
+}
+
+// renderMarkdown renders a markdown file of a commit as XHTML, with links relative to its folder at the ref
+func renderMarkdown(repo *models.Repository, gitRepo *git.Repository, refSubURL, treePath, content string) (string, error) {
+	var result strings.Builder
+	if err := markup.Render(&markup.RenderContext{
+		Ctx:       graceful.GetManager().HammerContext(),
+		Filename:  path.Base(treePath),
+		URLPrefix: repo.HTMLURL() + "/src/" + refSubURL + "/" + util.PathEscapeSegments(path.Dir(treePath)),
+		Metas:     repo.ComposeDocumentMetas(),
+		GitRepo:   gitRepo,
+	}, strings.NewReader(content), &result); err != nil {
+		return "", err
+	}
+	return toXHTML(result.String())
+}
+
+// readBlob reads the whole content of a blob
+func readBlob(blob *git.Blob) (string, error) {
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return "", err
+	}
+	defer dataRc.Close()
+	content, err := ioutil.ReadAll(dataRc)
+	return string(content), err
+}
+
+// renderProject renders a project of a manifest, a USFM file, a markdown file or a folder of markdown files,
+// returning an empty string for other projects such as TSV files
+func renderProject(repo *models.Repository, gitRepo *git.Repository, commit *git.Commit, refSubURL, projectPath string) (string, error) {
+	entry, err := commit.GetTreeEntryByPath(projectPath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if !entry.IsDir() {
+		if !entry.IsRegular() {
+			return "", nil
+		}
+		switch strings.ToLower(path.Ext(projectPath)) {
+		case ".usfm", ".usfm3", ".sfm":
+			content, err := readBlob(entry.Blob())
+			if err != nil {
+				return "", err
+			}
+			return dcs.UsfmToHTML(content), nil
+		case ".md":
+			content, err := readBlob(entry.Blob())
+			if err != nil {
+				return "", err
+			}
+			return renderMarkdown(repo, gitRepo, refSubURL, projectPath, content)
+		}
+		return "", nil
+	}
+
+	tree, err := commit.SubTree(projectPath)
+	if err != nil {
+		return "", err
+	}
+	entries, err := tree.ListEntriesRecursive()
+	if err != nil {
+		return "", err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsRegular() && strings.EqualFold(path.Ext(e.Name()), ".md") {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return markdownFileLess(files[i], files[j])
+	})
+
+	var result strings.Builder
+	for _, file := range files {
+		treePath := path.Join(projectPath, file)
+		blob, err := commit.GetBlobByPath(treePath)
+		if err != nil {
+			return "", err
+		}
+		content, err := readBlob(blob)
+		if err != nil {
+			return "", err
+		}
+		rendered, err := renderMarkdown(repo, gitRepo, refSubURL, treePath, content)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(rendered + "\n")
+	}
+	return result.String(), nil
+}
+
+// manifestProject is a project of a manifest
+type manifestProject struct {
+	Identifier string
+	Title      string
+	Path       string
+	Sort       int
+}
+
+// getManifestProjects returns the projects of a manifest in their order
+func getManifestProjects(manifest *map[string]interface{}) []*manifestProject {
+	if manifest == nil {
+		return nil
+	}
+	items, _ := (*manifest)["projects"].([]interface{})
+	var projects []*manifestProject
+	for _, item := range items {
+		p, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		project := &manifestProject{}
+		project.Identifier, _ = p["identifier"].(string)
+		project.Title, _ = p["title"].(string)
+		project.Path, _ = p["path"].(string)
+		switch s := p["sort"].(type) {
+		case float64:
+			project.Sort = int(s)
+		case int:
+			project.Sort = s
+		}
+		project.Path = strings.TrimPrefix(path.Clean("/"+project.Path), "/")
+		if project.Identifier == "" {
+			continue
+		}
+		if project.Title == "" {
+			project.Title = project.Identifier
+		}
+		projects = append(projects, project)
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Sort < projects[j].Sort
+	})
+	return projects
+}
+
+// RenderResource renders the books of the resource of a catalog entry at its commit
+func RenderResource(dm *models.Door43Metadata, gitRepo *git.Repository, commit *git.Commit) (*Resource, error) {
+	if err := dm.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	resource := &Resource{
+		Identifier: dcs.GetDublinCoreString(dm.Metadata, "identifier"),
+		Title:      dcs.GetDublinCoreString(dm.Metadata, "title"),
+		Language:   dcs.GetDublinCoreString(dm.Metadata, "language", "identifier"),
+		Direction:  dcs.GetDublinCoreString(dm.Metadata, "language", "direction"),
+		Version:    dcs.GetDublinCoreString(dm.Metadata, "version"),
+		Publisher:  dcs.GetDublinCoreString(dm.Metadata, "publisher"),
+		Rights:     dcs.GetDublinCoreString(dm.Metadata, "rights"),
+		URL:        dm.HTMLURL(),
+	}
+	if resource.Title == "" {
+		resource.Title = dm.Repo.Name
+	}
+	if resource.Direction != "rtl" {
+		resource.Direction = "ltr"
+	}
+	if resource.Publisher == "" {
+		resource.Publisher = dm.Repo.OwnerName
+	}
+
+	refSubURL := dm.GetBranchOrTagType() + "/" + util.PathEscapeSegments(dm.BranchOrTag)
+	for _, project := range getManifestProjects(dm.Metadata) {
+		content, err := renderProject(dm.Repo, gitRepo, commit, refSubURL, project.Path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		resource.Books = append(resource.Books, &Book{
+			Identifier: project.Identifier,
+			Title:      project.Title,
+			Content:    template.HTML(content),
+		})
+	}
+	return resource, nil
+}
+
+/*** END DCS Customizations ***/
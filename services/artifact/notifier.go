@@ -0,0 +1,36 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Notifier generating the artifacts of catalog releases ***/
+
+package artifact
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type artifactNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &artifactNotifier{}
+)
+
+// NewNotifier creates a notifier that generates the artifacts of the releases published in the catalog.
+// It must be registered after the door43metadata notifier, which adds the releases to the catalog.
+func NewNotifier() base.Notifier {
+	return &artifactNotifier{}
+}
+
+func (n *artifactNotifier) NotifyNewRelease(rel *models.Release) {
+	NotifyCatalogRelease(rel)
+}
+
+func (n *artifactNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release) {
+	NotifyCatalogRelease(rel)
+}
+
+/*** END DCS Customizations ***/
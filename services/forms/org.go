@@ -46,6 +46,7 @@ type UpdateOrgSettingForm struct {
 	Visibility                structs.VisibleType
 	MaxRepoCreation           int
 	RepoAdminChangeTeamAccess bool
+	BlockInconsistentReleases bool // DCS Customizations
 }
 
 // Validate validates the fields
@@ -54,6 +55,24 @@ func (f *UpdateOrgSettingForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+/*** DCS Customizations ***/
+
+// ScrubRulesForm form for editing the rules for scrubbing the repos of an organization
+type ScrubRulesForm struct {
+	Files          string
+	Fields         string
+	CommitterName  string `binding:"MaxSize(255)"`
+	CommitterEmail string `binding:"MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *ScrubRulesForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+/*** END DCS Customizations ***/
+
 // ___________
 // \__    ___/___ _____    _____
 //   |    |_/ __ \\__  \  /     \
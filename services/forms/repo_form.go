@@ -6,6 +6,7 @@
 package forms
 
 import (
+	"mime/multipart" // DCS Customizations
 	"net/http"
 	"net/url"
 	"strings"
@@ -57,6 +58,54 @@ func (f *CreateRepoForm) Validate(req *http.Request, errs binding.Errors) bindin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+/*** DCS Customizations ***/
+
+// TranslateRepoForm form for creating a repository to translate a catalog entry into a language
+type TranslateRepoForm struct {
+	UID         int64  `binding:"Required"`
+	Ref         string `binding:"MaxSize(255)"`
+	Language    string `binding:"Required;MaxSize(50)"`
+	Description string `binding:"MaxSize(255)"`
+	Private     bool
+}
+
+// Validate validates the fields
+func (f *TranslateRepoForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ImportArchiveRepoForm form for creating a repository from a zip or translationStudio archive
+type ImportArchiveRepoForm struct {
+	UID         int64  `binding:"Required"`
+	RepoName    string `binding:"Required;AlphaDashDot;MaxSize(100)"`
+	Description string `binding:"MaxSize(255)"`
+	Private     bool
+	Message     string
+	Archive     *multipart.FileHeader
+}
+
+// Validate validates the fields
+func (f *ImportArchiveRepoForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ImportArchiveForm form for committing the content of a zip or translationStudio archive to a branch
+type ImportArchiveForm struct {
+	Branch  string `binding:"MaxSize(255)"`
+	Message string
+	Archive *multipart.FileHeader
+}
+
+// Validate validates the fields
+func (f *ImportArchiveForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+/*** END DCS Customizations ***/
+
 // MigrateRepoForm form for migrating repository
 // this is used to interact with web ui
 type MigrateRepoForm struct {
@@ -160,6 +209,11 @@ type RepoSettingForm struct {
 	// Signing Settings
 	TrustModel string
 
+	/*** DCS Customizations ***/
+	// Sensitive data settings
+	SensitiveDataCheck string
+	/*** END DCS Customizations ***/
+
 	// Admin settings
 	EnableHealthCheck bool
 }
@@ -842,3 +896,20 @@ func (f *EditDoor43MetadataForm) Validate(req *http.Request, errs binding.Errors
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// EditManifestForm form for editing the manifest.yaml file with the schema generated form.
+// The manifest fields themselves are read from the request as they come from the schema.
+type EditManifestForm struct {
+	CommitSummary string `binding:"MaxSize(100)"`
+	CommitMessage string
+	CommitChoice  string `binding:"Required;MaxSize(50)"`
+	NewBranchName string `binding:"GitRefName;MaxSize(100)"`
+	LastCommit    string
+	Signoff       bool
+}
+
+// Validate validates the fields
+func (f *EditManifestForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Text-only diffs of USFM files ***/
+
+package gitdiff
+
+import (
+	"html"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/dcs"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// UsfmVerseDiff is a verse that differs between two versions of a USFM file, compared without its markup
+type UsfmVerseDiff struct {
+	// Ref is chapter:verse, the verse being 0 for the text of a chapter before its first verse
+	// and the chapter being 0 for the text before the first chapter
+	Ref   string
+	Type  TableDiffCellType
+	Left  string
+	Right string
+	// HTML is the changes from Left to Right, word by word
+	HTML template.HTML
+}
+
+// parseUsfmVerses reads a USFM file and returns the refs of its verses in their order and the text of each verse
+func parseUsfmVerses(reader io.Reader) ([]string, map[string]string, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	refs, verses := dcs.ParseUsfmVerses(string(data))
+	return refs, verses, nil
+}
+
+// usfmRefNumbers returns the chapter and first verse of a ref such as 3:16 or 3:16-17, for sorting
+func usfmRefNumbers(ref string) (int, int) {
+	parts := strings.SplitN(ref, ":", 2)
+	number := func(s string) int {
+		end := 0
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		n, _ := strconv.Atoi(s[:end])
+		return n
+	}
+	if len(parts) < 2 {
+		return number(parts[0]), 0
+	}
+	return number(parts[0]), number(parts[1])
+}
+
+// usfmDiffToHTML renders the changes of a verse word by word
+func usfmDiffToHTML(left, right string) template.HTML {
+	diffs := diffMatchPatch.DiffMain(left, right, false)
+	diffs = diffMatchPatch.DiffCleanupSemantic(diffs)
+	var sb strings.Builder
+	for _, diff := range diffs {
+		text := html.EscapeString(diff.Text)
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			sb.WriteString(`<span class="added-code">` + text + `</span>`)
+		case diffmatchpatch.DiffDelete:
+			sb.WriteString(`<span class="removed-code">` + text + `</span>`)
+		default:
+			sb.WriteString(text)
+		}
+	}
+	return template.HTML(sb.String())
+}
+
+// CreateUsfmDiff compares two versions of a USFM file verse by verse, without the markup of their alignments,
+// and returns the verses that differ. Either reader can be nil for an added or deleted file.
+func CreateUsfmDiff(baseReader, headReader io.Reader) ([]*UsfmVerseDiff, error) {
+	var baseRefs, headRefs []string
+	baseVerses, headVerses := map[string]string{}, map[string]string{}
+	var err error
+	if baseReader != nil {
+		if baseRefs, baseVerses, err = parseUsfmVerses(baseReader); err != nil {
+			return nil, err
+		}
+	}
+	if headReader != nil {
+		if headRefs, headVerses, err = parseUsfmVerses(headReader); err != nil {
+			return nil, err
+		}
+	}
+
+	// The verses of the head, with the deleted verses of the base
+	refs := headRefs
+	for _, ref := range baseRefs {
+		if _, ok := headVerses[ref]; !ok {
+			refs = append(refs, ref)
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		ci, vi := usfmRefNumbers(refs[i])
+		cj, vj := usfmRefNumbers(refs[j])
+		return ci < cj || (ci == cj && vi < vj)
+	})
+
+	var diffs []*UsfmVerseDiff
+	for _, ref := range refs {
+		left, inBase := baseVerses[ref]
+		right, inHead := headVerses[ref]
+		switch {
+		case !inBase:
+			diffs = append(diffs, &UsfmVerseDiff{Ref: ref, Type: TableDiffCellAdd, Right: right,
+				HTML: template.HTML(`<span class="added-code">` + html.EscapeString(right) + `</span>`)})
+		case !inHead:
+			diffs = append(diffs, &UsfmVerseDiff{Ref: ref, Type: TableDiffCellDel, Left: left,
+				HTML: template.HTML(`<span class="removed-code">` + html.EscapeString(left) + `</span>`)})
+		case left != right:
+			diffs = append(diffs, &UsfmVerseDiff{Ref: ref, Type: TableDiffCellChanged, Left: left, Right: right,
+				HTML: usfmDiffToHTML(left, right)})
+		}
+	}
+	return diffs, nil
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,57 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUsfmDiff(t *testing.T) {
+	base := `\id MAT
+\c 1
+\p
+\v 1 \zaln-s |x-strong="G0976"\*\w The|x-occurrence="1"\w* \w book|x-occurrence="1"\w*\zaln-e\*
+\v 2 \w Abraham|x-occurrence="1"\w* \w fathered|x-occurrence="1"\w* \w Isaac|x-occurrence="1"\w*
+\v 3 \w Judah|x-occurrence="1"\w*
+\c 2
+\v 1 \w Now|x-occurrence="1"\w*
+`
+	head := `\id MAT
+\c 1
+\p
+\v 1 \zaln-s |x-strong="G0976" x-lemma="βίβλος"\*\w The|x-occurrence="1" x-occurrences="1"\w* \w book|x-occurrence="1"\w*\zaln-e\*
+\v 2 \w Abraham|x-occurrence="1"\w* \w was|x-occurrence="1"\w* \w the|x-occurrence="1"\w* \w father|x-occurrence="1"\w* \w of|x-occurrence="1"\w* \w Isaac|x-occurrence="1"\w*
+\c 2
+\v 1 \w Now|x-occurrence="1"\w*
+\v 2 \w Behold|x-occurrence="1"\w*
+`
+	diffs, err := CreateUsfmDiff(strings.NewReader(base), strings.NewReader(head))
+	assert.NoError(t, err)
+	if assert.Len(t, diffs, 3) {
+		assert.Equal(t, "1:2", diffs[0].Ref)
+		assert.Equal(t, TableDiffCellChanged, diffs[0].Type)
+		assert.Equal(t, "Abraham fathered Isaac", diffs[0].Left)
+		assert.Equal(t, "Abraham was the father of Isaac", diffs[0].Right)
+		assert.Contains(t, string(diffs[0].HTML), `<span class="added-code">`)
+		assert.Contains(t, string(diffs[0].HTML), `<span class="removed-code">`)
+
+		assert.Equal(t, "1:3", diffs[1].Ref)
+		assert.Equal(t, TableDiffCellDel, diffs[1].Type)
+		assert.Equal(t, "Judah", diffs[1].Left)
+
+		assert.Equal(t, "2:2", diffs[2].Ref)
+		assert.Equal(t, TableDiffCellAdd, diffs[2].Type)
+		assert.Equal(t, "Behold", diffs[2].Right)
+	}
+
+	diffs, err = CreateUsfmDiff(nil, strings.NewReader(head))
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 5)
+	assert.Equal(t, "0:0", diffs[0].Ref)
+	assert.Equal(t, "MAT", diffs[0].Right)
+}
@@ -114,6 +114,12 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 		}
 	}
 
+	/*** DCS Customizations ***/
+	if err = door43metadata.CheckReleaseManifestConsistency(gitRepo, rel); err != nil {
+		return err
+	}
+	/*** END DCS Customizations ***/
+
 	if _, err = createTag(gitRepo, rel, msg); err != nil {
 		return err
 	}
@@ -193,6 +199,18 @@ func UpdateRelease(doer *models.User, gitRepo *git.Repository, rel *models.Relea
 	if rel.ID == 0 {
 		return errors.New("UpdateRelease only accepts an exist release")
 	}
+	/*** DCS Customizations ***/
+	oldRel, err := models.GetReleaseByID(rel.ID)
+	if err != nil {
+		return err
+	}
+	if oldRel.IsTag || !gitRepo.IsTagExist(rel.TagName) {
+		// The release is being published, or created from an existing tag
+		if err = door43metadata.CheckReleaseManifestConsistency(gitRepo, rel); err != nil {
+			return err
+		}
+	}
+	/*** END DCS Customizations ***/
 	isCreated, err := createTag(gitRepo, rel, "")
 	if err != nil {
 		return err
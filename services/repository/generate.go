@@ -28,7 +28,7 @@ func GenerateRepository(doer, owner *models.User, templateRepo *models.Repositor
 
 		// Git Content
 		if opts.GitContent && !templateRepo.IsEmpty {
-			if err = repo_module.GenerateGitContent(ctx, templateRepo, generateRepo); err != nil {
+			if err = repo_module.GenerateGitContent(ctx, templateRepo, generateRepo, opts); err != nil { // DCS Customizations
 				return err
 			}
 		}
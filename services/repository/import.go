@@ -0,0 +1,498 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Import of repos from zip and translationStudio archives ***/
+
+package repository
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/door43metadata"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Layouts of imported archives
+const (
+	// ArchiveLayoutRC is a resource container, with a manifest.yaml file
+	ArchiveLayoutRC = "rc"
+	// ArchiveLayoutTS is one or more translationStudio projects, with manifest.json files, converted to a resource container
+	ArchiveLayoutTS = "ts"
+	// ArchiveLayoutFiles is any other files, imported as they are
+	ArchiveLayoutFiles = "files"
+)
+
+var (
+	// MaxImportArchiveFiles is the maximum number of files of an imported archive
+	MaxImportArchiveFiles = 10000
+	// MaxImportArchiveSize is the maximum total size in bytes of the files of an imported archive once unpacked
+	MaxImportArchiveSize int64 = 500 << 20
+)
+
+// ErrInvalidArchive represents an archive that can't be imported
+type ErrInvalidArchive struct {
+	Reason string
+}
+
+// IsErrInvalidArchive checks if an error is a ErrInvalidArchive
+func IsErrInvalidArchive(err error) bool {
+	_, ok := err.(ErrInvalidArchive)
+	return ok
+}
+
+func (err ErrInvalidArchive) Error() string {
+	return fmt.Sprintf("invalid archive: %s", err.Reason)
+}
+
+// ImportArchiveOptions are the options of the import of an archive into a repo
+type ImportArchiveOptions struct {
+	// Branch defaults to the default branch of the repo
+	Branch  string
+	Message string
+}
+
+// ImportArchiveResult is the result of the import of an archive into a repo
+type ImportArchiveResult struct {
+	Layout   string
+	CommitID string
+}
+
+// CreateRepositoryFromArchiveOptions are the options of the creation of a repo from an archive
+type CreateRepositoryFromArchiveOptions struct {
+	Name        string
+	Description string
+	Private     bool
+	Message     string
+}
+
+// CreateRepositoryFromArchive creates a repo of the owner with the content of a zip or translationStudio archive
+// as its initial commit. The repo is deleted again if the archive can't be imported.
+func CreateRepositoryFromArchive(doer, owner *models.User, archive io.ReaderAt, size int64, opts CreateRepositoryFromArchiveOptions) (*models.Repository, *ImportArchiveResult, error) {
+	// Unpacking first rejects invalid archives before the repo is created
+	tmpDir, err := unpackArchive(archive, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer removeTemporaryPath(tmpDir)
+
+	repo, err := CreateRepository(doer, owner, models.CreateRepoOptions{
+		Name:        opts.Name,
+		Description: opts.Description,
+		IsPrivate:   opts.Private,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := importDirectory(doer, repo, tmpDir, ImportArchiveOptions{Message: opts.Message})
+	if err != nil {
+		if errDelete := models.DeleteRepository(doer, owner.ID, repo.ID); errDelete != nil {
+			log.Error("Rollback deleteRepository: %v", errDelete)
+		}
+		return nil, nil, err
+	}
+	return repo, result, nil
+}
+
+// ImportArchive commits the content of a zip or translationStudio archive to a branch of a repo as its whole tree,
+// translationStudio projects being converted to a resource container
+func ImportArchive(doer *models.User, repo *models.Repository, archive io.ReaderAt, size int64, opts ImportArchiveOptions) (*ImportArchiveResult, error) {
+	tmpDir, err := unpackArchive(archive, size)
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemporaryPath(tmpDir)
+	return importDirectory(doer, repo, tmpDir, opts)
+}
+
+func removeTemporaryPath(tmpDir string) {
+	if err := models.RemoveTemporaryPath(tmpDir); err != nil {
+		log.Error("Failed to remove temporary path %s: %v", tmpDir, err)
+	}
+}
+
+// importDirectory converts an unpacked archive to a resource container if need be and commits it
+func importDirectory(doer *models.User, repo *models.Repository, tmpDir string, opts ImportArchiveOptions) (*ImportArchiveResult, error) {
+	if repo.IsEmpty && repo.DefaultBranch == "" {
+		repo.DefaultBranch = setting.Repository.DefaultBranch
+	}
+	if opts.Branch == "" || repo.IsEmpty {
+		opts.Branch = repo.DefaultBranch
+	}
+	root, err := archiveRoot(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportArchiveResult{Layout: ArchiveLayoutFiles}
+	tsProjects, err := findTsProjects(root)
+	if err != nil {
+		return nil, err
+	}
+	if isExist(filepath.Join(root, "manifest.yaml")) {
+		result.Layout = ArchiveLayoutRC
+	} else if len(tsProjects) > 0 {
+		result.Layout = ArchiveLayoutTS
+		if root, err = convertTsProjects(repo, opts.Branch, tmpDir, tsProjects); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Message == "" {
+		opts.Message = fmt.Sprintf("Import %s archive", strings.ToUpper(result.Layout))
+	}
+	result.CommitID, err = repofiles.ImportRepoFiles(repo, doer, &repofiles.ImportRepoFilesOptions{
+		Branch:  opts.Branch,
+		Message: opts.Message,
+		Dir:     root,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The push hooks update the repo asynchronously, but it is shown right after the import
+	if repo.IsEmpty {
+		repo.IsEmpty = false
+		if err := setDefaultBranch(repo); err != nil {
+			return nil, err
+		}
+		if err := models.UpdateRepositoryCols(repo, "default_branch", "is_empty"); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// setDefaultBranch sets the HEAD of the git repo of a repo to its default branch
+func setDefaultBranch(repo *models.Repository) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+	if err := gitRepo.SetDefaultBranch(repo.DefaultBranch); err != nil && !git.IsErrUnsupportedVersion(err) {
+		return err
+	}
+	return nil
+}
+
+func isExist(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
+
+// isIgnoredArchivePath returns true if a path of an archive is an artifact of the system that made it
+// or of git, rather than a file of the resource
+func isIgnoredArchivePath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		switch strings.ToLower(part) {
+		case "__macosx", ".ds_store", "thumbs.db", ".git":
+			return true
+		}
+	}
+	return false
+}
+
+// unpackArchive unpacks a zip archive, such as a .tstudio file, into a temporary directory. Archives with paths
+// outside of the directory, too many files or too large files once unpacked are rejected.
+func unpackArchive(archive io.ReaderAt, size int64) (string, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return "", ErrInvalidArchive{Reason: "not a zip archive"}
+	}
+
+	tmpDir, err := models.CreateTemporaryPath("import")
+	if err != nil {
+		return "", err
+	}
+	if err := unpackZipFiles(reader.File, tmpDir); err != nil {
+		removeTemporaryPath(tmpDir)
+		return "", err
+	}
+	return tmpDir, nil
+}
+
+func unpackZipFiles(files []*zip.File, tmpDir string) error {
+	count := 0
+	remaining := MaxImportArchiveSize
+	for _, file := range files {
+		name := strings.ReplaceAll(file.Name, "\\", "/")
+		if path.IsAbs(name) || filepath.IsAbs(file.Name) || strings.Contains(name, ":") {
+			return ErrInvalidArchive{Reason: fmt.Sprintf("absolute path %q", file.Name)}
+		}
+		for _, part := range strings.Split(name, "/") {
+			if part == ".." {
+				return ErrInvalidArchive{Reason: fmt.Sprintf("path outside of the archive %q", file.Name)}
+			}
+		}
+		if file.FileInfo().IsDir() || isIgnoredArchivePath(name) {
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			// Symbolic links could point outside of the repo
+			return ErrInvalidArchive{Reason: fmt.Sprintf("not a regular file %q", file.Name)}
+		}
+		count++
+		if count > MaxImportArchiveFiles {
+			return ErrInvalidArchive{Reason: fmt.Sprintf("more than %d files", MaxImportArchiveFiles)}
+		}
+
+		target := filepath.Join(tmpDir, filepath.FromSlash(path.Clean(name)))
+		if !strings.HasPrefix(target, filepath.Clean(tmpDir)+string(filepath.Separator)) {
+			return ErrInvalidArchive{Reason: fmt.Sprintf("path outside of the archive %q", file.Name)}
+		}
+		written, err := unpackZipFile(file, target, remaining)
+		if err != nil {
+			return err
+		}
+		remaining -= written
+	}
+	if count == 0 {
+		return ErrInvalidArchive{Reason: "no files"}
+	}
+	return nil
+}
+
+// unpackZipFile unpacks a file of a zip archive, returning the number of bytes written. The declared size
+// of the file isn't trusted, its content being read up to the remaining size only.
+func unpackZipFile(file *zip.File, target string, remaining int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return 0, err
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return 0, ErrInvalidArchive{Reason: err.Error()}
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, io.LimitReader(rc, remaining+1))
+	if err != nil {
+		return written, ErrInvalidArchive{Reason: err.Error()}
+	}
+	if written > remaining {
+		return written, ErrInvalidArchive{Reason: fmt.Sprintf("larger than %d bytes once unpacked", MaxImportArchiveSize)}
+	}
+	return written, nil
+}
+
+// archiveRoot returns the directory of an unpacked archive that its files are in, descending into
+// the only directory of archives made of a folder, e.g. en_ult/manifest.yaml
+func archiveRoot(dir string) (string, error) {
+	for {
+		if isExist(filepath.Join(dir, "manifest.yaml")) || isExist(filepath.Join(dir, "manifest.json")) {
+			return dir, nil
+		}
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return "", err
+		}
+		if len(infos) != 1 || !infos[0].IsDir() {
+			return dir, nil
+		}
+		dir = filepath.Join(dir, infos[0].Name())
+	}
+}
+
+// tsProject is a translationStudio project of an unpacked archive
+type tsProject struct {
+	Dir      string
+	Manifest *door43metadata.TsManifest
+}
+
+// findTsProjects finds the translationStudio projects of books of the Bible in a directory or its sub-directories,
+// e.g. en_tit_text_ulb/manifest.json
+func findTsProjects(dir string) ([]*tsProject, error) {
+	readProject := func(projectDir string) *tsProject {
+		data, err := ioutil.ReadFile(filepath.Join(projectDir, "manifest.json"))
+		if err != nil {
+			return nil
+		}
+		manifest := door43metadata.ParseTsManifest(data)
+		if manifest == nil || manifest.Book() == nil {
+			return nil
+		}
+		return &tsProject{Dir: projectDir, Manifest: manifest}
+	}
+
+	if project := readProject(dir); project != nil {
+		return []*tsProject{project}, nil
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var projects []*tsProject
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		if project := readProject(filepath.Join(dir, info.Name())); project != nil {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+var (
+	tsChapterRegex = regexp.MustCompile(`^\d+$`)
+	tsChunkRegex   = regexp.MustCompile(`^(\d+)\.txt$`)
+	usfmChapterRe  = regexp.MustCompile(`\\c\s+\d+\s*`)
+)
+
+// convertTsProjects converts translationStudio projects to a resource container in a new directory of tmpDir,
+// returning the directory. The manifest.yaml is generated from the projects, or if the branch already has one,
+// its projects are updated.
+func convertTsProjects(repo *models.Repository, branch, tmpDir string, projects []*tsProject) (string, error) {
+	rcDir, err := ioutil.TempDir(tmpDir, "rc")
+	if err != nil {
+		return "", err
+	}
+
+	manifests := make([]*door43metadata.TsManifest, 0, len(projects))
+	for _, project := range projects {
+		usfm, err := tsProjectToUsfm(project)
+		if err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(filepath.Join(rcDir, project.Manifest.Book().UsfmFileName()), []byte(usfm), 0644); err != nil {
+			return "", err
+		}
+		if isExist(filepath.Join(project.Dir, "LICENSE.md")) && !isExist(filepath.Join(rcDir, "LICENSE.md")) {
+			if err := os.Rename(filepath.Join(project.Dir, "LICENSE.md"), filepath.Join(rcDir, "LICENSE.md")); err != nil {
+				return "", err
+			}
+		}
+		manifests = append(manifests, project.Manifest)
+	}
+
+	manifest := door43metadata.GenerateManifestFromTs(repo, manifests)
+	existing, original := readBranchManifest(repo, branch)
+	if existing != nil {
+		manifest = door43metadata.UpdateManifestProjects(existing, manifest)
+	}
+	data, err := door43metadata.MarshalManifest(original, manifest)
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(rcDir, "manifest.yaml"), data, 0644); err != nil {
+		return "", err
+	}
+	return rcDir, nil
+}
+
+// readBranchManifest reads the manifest.yaml of a branch of a repo and returns it with its content,
+// returning nil if it has none
+func readBranchManifest(repo *models.Repository, branch string) (map[string]interface{}, []byte) {
+	if repo.IsEmpty {
+		return nil, nil
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository: %v", err)
+		return nil, nil
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit(branch)
+	if err != nil {
+		return nil, nil
+	}
+	entry, err := commit.GetTreeEntryByPath("manifest.yaml")
+	if err != nil {
+		return nil, nil
+	}
+	manifest, content, err := base.ReadYAMLAndContentFromBlob(entry.Blob())
+	if err != nil || manifest == nil {
+		return nil, nil
+	}
+	return *manifest, content
+}
+
+// tsProjectToUsfm assembles the chunks of the chapters of a translationStudio project, e.g. 01/01.txt, into a USFM file,
+// its title being that of front/title.txt
+func tsProjectToUsfm(project *tsProject) (string, error) {
+	book := project.Manifest.Book()
+	title := book.Name
+	if project.Manifest.Project.Name != "" {
+		title = project.Manifest.Project.Name
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(project.Dir, "front", "title.txt")); err == nil && strings.TrimSpace(string(data)) != "" {
+		title = strings.TrimSpace(string(data))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\\id %s %s\n\\usfm 3.0\n\\ide UTF-8\n\\h %s\n\\toc1 %s\n\\toc2 %s\n\\toc3 %s\n\\mt %s\n",
+		strings.ToUpper(book.ID), project.Manifest.Resource.Name, title, title, title, strings.Title(book.ID), title)
+
+	infos, err := ioutil.ReadDir(project.Dir)
+	if err != nil {
+		return "", err
+	}
+	var chapters []string
+	for _, info := range infos {
+		// The chapter 00 of some projects is their front matter
+		if info.IsDir() && tsChapterRegex.MatchString(info.Name()) && strings.TrimLeft(info.Name(), "0") != "" {
+			chapters = append(chapters, info.Name())
+		}
+	}
+	sortNumerically(chapters)
+	for _, chapter := range chapters {
+		chapterInfos, err := ioutil.ReadDir(filepath.Join(project.Dir, chapter))
+		if err != nil {
+			return "", err
+		}
+		var chunks []string
+		for _, info := range chapterInfos {
+			if !info.IsDir() && tsChunkRegex.MatchString(info.Name()) {
+				chunks = append(chunks, info.Name())
+			}
+		}
+		sortNumerically(chunks)
+		fmt.Fprintf(&sb, "\n\\c %s\n\\p\n", strings.TrimLeft(chapter, "0"))
+		for _, chunk := range chunks {
+			data, err := ioutil.ReadFile(filepath.Join(project.Dir, chapter, chunk))
+			if err != nil {
+				return "", err
+			}
+			// Some versions of translationStudio keep the chapter marker in the first chunk
+			text := strings.TrimSpace(usfmChapterRe.ReplaceAllString(string(data), ""))
+			if text != "" {
+				sb.WriteString(text)
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// sortNumerically sorts names of chapters and chunks such as 01, 02.txt and 100.txt by their number
+func sortNumerically(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := strings.TrimLeft(names[i], "0"), strings.TrimLeft(names[j], "0")
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,138 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeZip(t *testing.T, files map[string]string) *bytes.Reader {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestUnpackArchive(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	archive := makeZip(t, map[string]string{
+		"en_ult/manifest.yaml":            "dublin_core: {}\n",
+		"en_ult/57-TIT.usfm":              "\\id TIT\n",
+		"__MACOSX/en_ult/._57-TIT.usfm":   "",
+		"en_ult/.git/config":              "",
+		"en_ult/content/.DS_Store":        "",
+		"en_ult/content/01/01.md":         "# 1",
+		"en_ult/content/01/../../02.md":   "",
+		"en_ult/content/../content/03.md": "",
+	})
+	_, err := unpackArchive(archive, archive.Size())
+	assert.True(t, IsErrInvalidArchive(err))
+
+	archive = makeZip(t, map[string]string{
+		"en_ult/manifest.yaml":          "dublin_core: {}\n",
+		"en_ult/57-TIT.usfm":            "\\id TIT\n",
+		"__MACOSX/en_ult/._57-TIT.usfm": "",
+		"en_ult/.git/config":            "",
+	})
+	tmpDir, err := unpackArchive(archive, archive.Size())
+	assert.NoError(t, err)
+	defer removeTemporaryPath(tmpDir)
+	root, err := archiveRoot(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "en_ult"), root)
+	assert.FileExists(t, filepath.Join(root, "57-TIT.usfm"))
+	assert.NoFileExists(t, filepath.Join(root, ".git", "config"))
+	assert.NoDirExists(t, filepath.Join(tmpDir, "__MACOSX"))
+
+	_, err = unpackArchive(bytes.NewReader([]byte("not a zip")), 9)
+	assert.True(t, IsErrInvalidArchive(err))
+
+	defer func(size int64) {
+		MaxImportArchiveSize = size
+	}(MaxImportArchiveSize)
+	MaxImportArchiveSize = 10
+	archive = makeZip(t, map[string]string{"bomb.txt": strings.Repeat("0", 1000)})
+	_, err = unpackArchive(archive, archive.Size())
+	assert.True(t, IsErrInvalidArchive(err))
+}
+
+func TestConvertTsProjects(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "tstudio")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"es_tit_text_reg/manifest.json": `{"project": {"id": "tit", "name": "Tito"},
+			"target_language": {"id": "es", "name": "español", "direction": "ltr"},
+			"resource": {"id": "reg", "name": "Regular"},
+			"source_translations": [{"language_id": "en", "resource_id": "ulb", "version": "12"}],
+			"translators": ["Juan"]}`,
+		"es_tit_text_reg/LICENSE.md":      "CC BY-SA 4.0",
+		"es_tit_text_reg/front/title.txt": "Tito",
+		"es_tit_text_reg/01/title.txt":    "Capítulo 1",
+		"es_tit_text_reg/01/01.txt":       "\\c 1 \\v 1 Pablo, siervo de Dios",
+		"es_tit_text_reg/01/05.txt":       "\\v 5 Por esta causa",
+		"es_tit_text_reg/02/01.txt":       "\\v 1 Pero tú",
+		"es_tit_text_reg/10/01.txt":       "",
+		"es_tit_text_reg/00/title.txt":    "Tito",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, name)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	projects, err := findTsProjects(tmpDir)
+	assert.NoError(t, err)
+	if !assert.Len(t, projects, 1) {
+		return
+	}
+	assert.Equal(t, "tit", projects[0].Manifest.Book().ID)
+
+	repo := &models.Repository{Name: "tito", OwnerName: "user2", IsEmpty: true}
+	rcDir, err := convertTsProjects(repo, "master", tmpDir, projects)
+	assert.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(rcDir, "57-TIT.usfm"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\\id TIT Regular\n\\usfm 3.0\n\\ide UTF-8\n\\h Tito\n\\toc1 Tito\n\\toc2 Tito\n\\toc3 Tit\n\\mt Tito\n"+
+		"\n\\c 1\n\\p\n\\v 1 Pablo, siervo de Dios\n\\v 5 Por esta causa\n"+
+		"\n\\c 2\n\\p\n\\v 1 Pero tú\n"+
+		"\n\\c 10\n\\p\n", string(content))
+	assert.FileExists(t, filepath.Join(rcDir, "LICENSE.md"))
+
+	content, err = ioutil.ReadFile(filepath.Join(rcDir, "manifest.yaml"))
+	assert.NoError(t, err)
+	manifest := map[string]interface{}{}
+	assert.NoError(t, yaml.Unmarshal(content, &manifest))
+	dc := manifest["dublin_core"].(map[string]interface{})
+	assert.Equal(t, "reg", dc["identifier"])
+	assert.Equal(t, "Regular", dc["title"])
+	assert.Equal(t, map[string]interface{}{"identifier": "es", "title": "español", "direction": "ltr"}, dc["language"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"identifier": "ulb", "language": "en", "version": "12"}}, dc["source"])
+	assert.Equal(t, []interface{}{"Juan"}, dc["contributor"])
+	if assert.Len(t, manifest["projects"], 1) {
+		project := manifest["projects"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "./57-TIT.usfm", project["path"])
+		assert.Equal(t, "Tito", project["title"])
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Translation of a catalog entry into a new repo ***/
+
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/door43metadata"
+
+	"github.com/ghodss/yaml"
+)
+
+// TranslateOptions are the options of the translation of a catalog entry into a new repo
+type TranslateOptions struct {
+	// Language is the identifier of the language to translate into, e.g. fr
+	Language string
+	// LanguageTitle and LanguageDirection are filled in from tD if empty
+	LanguageTitle     string
+	LanguageDirection string
+	Description       string
+	Private           bool
+}
+
+// GetCatalogEntryToTranslate returns the catalog entry of a repo at a release tag or its default branch,
+// or if no ref is given, its latest production entry, else that of its default branch
+func GetCatalogEntryToTranslate(repo *models.Repository, ref string) (*models.Door43Metadata, error) {
+	switch ref {
+	case "":
+		dm, err := models.GetLatestCatalogMetadataByRepoID(repo.ID, false)
+		if err == nil || !models.IsErrDoor43MetadataNotExist(err) {
+			return dm, err
+		}
+		return models.GetDoor43MetadataByRepoIDAndReleaseID(repo.ID, 0)
+	case repo.DefaultBranch:
+		return models.GetDoor43MetadataByRepoIDAndReleaseID(repo.ID, 0)
+	}
+	dm, err := models.GetDoor43MetadataByRepoIDAndTagName(repo.ID, ref)
+	if models.IsErrReleaseNotExist(err) {
+		return nil, models.ErrDoor43MetadataNotExist{RepoID: repo.ID}
+	}
+	return dm, err
+}
+
+// TranslateCatalogEntry creates a repo of the owner named {lang}_{subject} to translate a catalog entry into
+// a language. Its content is that of the entry, the USFM, markdown and TSV files of its projects being reduced
+// to their skeleton, and its manifest.yaml is rewritten for the language with the entry as its source.
+func TranslateCatalogEntry(doer, owner *models.User, dm *models.Door43Metadata, opts TranslateOptions) (*models.Repository, error) {
+	if err := dm.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	lang := strings.ToLower(strings.TrimSpace(opts.Language))
+	if lang == "" {
+		return nil, fmt.Errorf("no language to translate into")
+	}
+
+	return GenerateRepository(doer, owner, dm.Repo, models.GenerateRepoOptions{
+		Name:        door43metadata.GetTranslationRepoName(dm, lang),
+		Description: opts.Description,
+		Private:     opts.Private,
+		GitContent:  true,
+		Ref:         dm.BranchOrTag,
+		ContentTransform: func(tmpDir string) error {
+			return translateContent(tmpDir, dm, lang, opts)
+		},
+	})
+}
+
+// translateContent reduces the files of the projects of a catalog entry to their skeleton and rewrites its
+// manifest.yaml for the language. The other files, such as the LICENSE, are kept as they are.
+func translateContent(tmpDir string, dm *models.Door43Metadata, lang string, opts TranslateOptions) error {
+	manifestPath := filepath.Join(tmpDir, "manifest.yaml")
+	// The files of the repo may be symbolic links to files of the server, which must not be rewritten
+	info, err := os.Lstat(manifestPath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("manifest.yaml is not a regular file")
+	}
+	content, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	manifest := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return err
+	}
+
+	projects, _ := manifest["projects"].([]interface{})
+	for _, p := range projects {
+		project, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		projectPath, ok := project["path"].(string)
+		if !ok || projectPath == "" {
+			continue
+		}
+		// Cleaned as an absolute path so that it can't lead out of the repo, nor through a symbolic link
+		projectPath = path.Clean("/" + projectPath)
+		if projectPath == "/" {
+			continue
+		}
+		if linked, err := isSymlinkPath(tmpDir, projectPath); err != nil {
+			return err
+		} else if linked {
+			continue
+		}
+		if err := skeletonizeFiles(filepath.Join(tmpDir, filepath.FromSlash(projectPath))); err != nil {
+			return err
+		}
+	}
+
+	door43metadata.TranslateManifest(manifest, dm, lang, opts.LanguageTitle, opts.LanguageDirection)
+	if content, err = door43metadata.MarshalManifest(content, manifest); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(manifestPath, content, 0644)
+}
+
+// isSymlinkPath returns whether a slash separated path of a directory, or one of its parent directories, is
+// a symbolic link. A path that does not exist is not.
+func isSymlinkPath(dir, p string) (bool, error) {
+	current := dir
+	for _, part := range strings.Split(strings.Trim(p, "/"), "/") {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// skeletonizeFiles reduces the regular USFM, markdown and TSV files at root, a file or a directory, to their
+// skeleton. Symbolic links are not followed.
+func skeletonizeFiles(root string) error {
+	return filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && filePath == root {
+			// A project of the manifest that is not in the repo
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		var skeleton func(string) string
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".usfm", ".usfm3", ".sfm":
+			skeleton = dcs.UsfmSkeleton
+		case ".md", ".markdown":
+			skeleton = dcs.MarkdownSkeleton
+		case ".tsv":
+			skeleton = dcs.TSVSkeleton
+		default:
+			return nil
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filePath, []byte(skeleton(string(content))), info.Mode())
+	})
+}
+
+/*** END DCS Customizations ***/
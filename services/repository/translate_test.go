@@ -0,0 +1,127 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateContent(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "translate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "manifest.yaml"), []byte(`dublin_core:
+  identifier: ult
+  language:
+    identifier: en
+    title: English
+    direction: ltr
+  contributor:
+    - Someone
+  version: '85'
+checking:
+  checking_level: '3'
+projects:
+  - identifier: tit
+    path: ./57-TIT.usfm
+  - identifier: obs
+    path: ./content
+  - identifier: tit
+    path: ./tn_TIT.tsv
+  - identifier: phm
+    path: ./58-PHM.usfm
+  - identifier: outside
+    path: ../../etc
+  - identifier: gen
+    path: ./01-GEN.usfm
+  - identifier: linked
+    path: ./linked/01.md
+`), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(tmpDir, "content"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "content", "01.md"), []byte("# 1. The Creation\n\nThis is how God made everything.\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "tn_TIT.tsv"), []byte("Reference\tID\tNote\n1:1\tabcd\tPaul is speaking\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "57-TIT.usfm"), []byte(`\id TIT
+\c 1
+\p
+\v 1 Paul, a servant of God
+`), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "LICENSE.md"), []byte("CC BY-SA 4.0"), 0644))
+
+	// The symbolic links of the repo to files of the server are not followed
+	outsideDir, err := ioutil.TempDir("", "outside")
+	assert.NoError(t, err)
+	defer os.RemoveAll(outsideDir)
+	outsideFile := filepath.Join(outsideDir, "01.md")
+	assert.NoError(t, ioutil.WriteFile(outsideFile, []byte("# Server file\n\nNot to be rewritten\n"), 0644))
+	assert.NoError(t, os.Symlink(outsideFile, filepath.Join(tmpDir, "01-GEN.usfm")))
+	assert.NoError(t, os.Symlink(outsideFile, filepath.Join(tmpDir, "content", "02.md")))
+	assert.NoError(t, os.Symlink(outsideDir, filepath.Join(tmpDir, "linked")))
+
+	source := &models.Door43Metadata{Metadata: &map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"identifier": "ult",
+			"language":   map[string]interface{}{"identifier": "en"},
+			"version":    "85",
+		},
+	}}
+	assert.NoError(t, translateContent(tmpDir, source, "fr", TranslateOptions{LanguageTitle: "français", LanguageDirection: "ltr"}))
+
+	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "manifest.yaml"))
+	assert.NoError(t, err)
+	manifest := map[string]interface{}{}
+	assert.NoError(t, yaml.Unmarshal(content, &manifest))
+	dc := manifest["dublin_core"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"identifier": "fr", "title": "français", "direction": "ltr"}, dc["language"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"identifier": "ult", "language": "en", "version": "85"}}, dc["source"])
+	assert.Empty(t, dc["contributor"])
+	assert.Equal(t, "1", dc["version"])
+	assert.Equal(t, "1", manifest["checking"].(map[string]interface{})["checking_level"])
+	assert.Len(t, manifest["projects"], 7)
+
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "57-TIT.usfm"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\\id TIT\n\\h\n\\toc1\n\\toc2\n\\toc3\n\\mt\n\\c 1\n\\p\n\\v 1\n", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "content", "01.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# \n", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "tn_TIT.tsv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Reference\tID\tNote\n1:1\tabcd\t\n", string(content))
+
+	content, err = ioutil.ReadFile(outsideFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Server file\n\nNot to be rewritten\n", string(content))
+
+	// The files that are not in a project are kept as they are
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "LICENSE.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "CC BY-SA 4.0", string(content))
+}
+
+func TestTranslateContentSymlinkedManifest(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "translate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	outsideFile := filepath.Join(tmpDir, "outside.yaml")
+	assert.NoError(t, ioutil.WriteFile(outsideFile, []byte("dublin_core:\n  identifier: ult\n"), 0644))
+	repoDir := filepath.Join(tmpDir, "repo")
+	assert.NoError(t, os.Mkdir(repoDir, 0755))
+	assert.NoError(t, os.Symlink(outsideFile, filepath.Join(repoDir, "manifest.yaml")))
+
+	assert.Error(t, translateContent(repoDir, &models.Door43Metadata{}, "fr", TranslateOptions{}))
+	content, err := ioutil.ReadFile(outsideFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "dublin_core:\n  identifier: ult\n", string(content))
+}
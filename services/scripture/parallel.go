@@ -0,0 +1,389 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Side by side view of a translation and its source ***/
+
+package scripture
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrNoParallelSource is returned when the source of a translation can't be found in the catalog or has no file for the book
+type ErrNoParallelSource struct {
+	Source string
+	Book   string
+}
+
+// IsErrNoParallelSource checks if an error is a ErrNoParallelSource
+func IsErrNoParallelSource(err error) bool {
+	_, ok := err.(ErrNoParallelSource)
+	return ok
+}
+
+func (err ErrNoParallelSource) Error() string {
+	if err.Book != "" {
+		return fmt.Sprintf("source has no file for the book [source: %s, book: %s]", err.Source, err.Book)
+	}
+	return fmt.Sprintf("source does not exist in the catalog [source: %s]", err.Source)
+}
+
+// ParallelSource is the source of a translation as given by the dublin_core.source of its manifest,
+// e.g. {identifier: ult, language: en, version: 85}
+type ParallelSource struct {
+	Identifier string
+	Language   string
+	Version    string
+}
+
+// RepoName returns the name of the repo of the source, e.g. en_ult
+func (s *ParallelSource) RepoName() string {
+	return s.Language + "_" + s.Identifier
+}
+
+func (s *ParallelSource) String() string {
+	if s.Version != "" {
+		return s.RepoName() + " v" + s.Version
+	}
+	return s.RepoName()
+}
+
+// ParallelText is the USFM file of a book in one of the repos of a side by side view
+type ParallelText struct {
+	Repo      *models.Repository
+	Ref       string
+	Path      string
+	HTMLURL   string
+	Language  string
+	Direction string
+}
+
+// ParallelVerse is a verse, or a range of verses where the source and the translation bridge them differently,
+// with its text in the source and in the translation
+type ParallelVerse struct {
+	Ref    string
+	Source string
+	Target string
+}
+
+// ParallelView is a book of a translation aligned verse by verse with its source
+type ParallelView struct {
+	Book   *dcs.Book
+	Source *ParallelText
+	Target *ParallelText
+	Verses []*ParallelVerse
+}
+
+// GetParallelSource returns the first source of the dublin_core of a manifest, or nil if it has none
+func GetParallelSource(manifest *map[string]interface{}) *ParallelSource {
+	if manifest == nil {
+		return nil
+	}
+	dublinCore, _ := (*manifest)["dublin_core"].(map[string]interface{})
+	sources, _ := dublinCore["source"].([]interface{})
+	for _, s := range sources {
+		source, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value := func(key string) string {
+			if source[key] == nil {
+				return ""
+			}
+			// Versions in particular are often given as numbers in YAML
+			return strings.TrimSpace(fmt.Sprint(source[key]))
+		}
+		ps := &ParallelSource{
+			Identifier: value("identifier"),
+			Language:   value("language"),
+			Version:    strings.TrimPrefix(value("version"), "v"),
+		}
+		if ps.Identifier == "" || ps.Language == "" {
+			continue
+		}
+		return ps
+	}
+	return nil
+}
+
+// GetSourceEntry returns the catalog entry of a source, preferring the repo of the given owner to that of
+// any other owner, and the version of the source to its latest version
+func GetSourceEntry(source *ParallelSource, owner string) (*models.Door43Metadata, error) {
+	var versions dcs.VersionConstraints
+	if source.Version != "" {
+		var err error
+		if versions, err = dcs.ParseVersionConstraints("=" + source.Version); err != nil {
+			// Not a version that the catalog can compare, so fall back to the latest version
+			versions = nil
+		}
+	}
+
+	search := func(owners []string, versions dcs.VersionConstraints) (*models.Door43Metadata, error) {
+		dms, _, err := models.SearchCatalog(&models.SearchCatalogOptions{
+			ListOptions:    models.ListOptions{Page: 1, PageSize: 1},
+			Owners:         owners,
+			Repos:          []string{source.RepoName()},
+			Stage:          models.StageProd,
+			Versions:       versions,
+			IncludeHistory: len(versions) > 0,
+		})
+		if err != nil || len(dms) == 0 {
+			return nil, err
+		}
+		return dms[0], nil
+	}
+
+	// The source version may not have been released by every owner, so its latest version is next best
+	constraints := []dcs.VersionConstraints{versions}
+	if len(versions) > 0 {
+		constraints = append(constraints, nil)
+	}
+	for _, versions := range constraints {
+		for _, owners := range [][]string{{owner}, nil} {
+			dm, err := search(owners, versions)
+			if err != nil {
+				return nil, err
+			}
+			if dm != nil {
+				return dm, nil
+			}
+		}
+	}
+	return nil, ErrNoParallelSource{Source: source.String()}
+}
+
+// languageOfManifest returns the language of a manifest and its direction, given by the manifest
+// or else by the language data, defaulting to ltr
+func languageOfManifest(manifest *map[string]interface{}) (string, string) {
+	if manifest == nil {
+		return "", "ltr"
+	}
+	language := dcs.GetDublinCoreString(manifest, "language", "identifier")
+	direction := dcs.GetDublinCoreString(manifest, "language", "direction")
+	if direction == "" && language != "" {
+		if lang, ok := dcs.GetLangNames()[language].(map[string]interface{}); ok {
+			direction, _ = lang["ld"].(string)
+		}
+	}
+	if direction != "rtl" {
+		direction = "ltr"
+	}
+	return language, direction
+}
+
+// getBookOfFile returns the book of a USFM file, given by the project of the manifest for its path
+// or else by its file name
+func getBookOfFile(manifest *map[string]interface{}, treePath string) *dcs.Book {
+	if manifest != nil {
+		projects, _ := (*manifest)["projects"].([]interface{})
+		for _, p := range projects {
+			project, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			projectPath, _ := project["path"].(string)
+			identifier, _ := project["identifier"].(string)
+			if path.Clean(projectPath) == path.Clean(treePath) && dcs.IsValidBook(strings.ToLower(identifier)) {
+				return dcs.GetBook(strings.ToLower(identifier))
+			}
+		}
+	}
+	return dcs.GetBook(dcs.GetBookFromFileName(path.Base(treePath)))
+}
+
+// isUsfmFile returns true if a file name has an extension of USFM files
+func isUsfmFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".usfm", ".usfm3", ".sfm":
+		return true
+	}
+	return false
+}
+
+// HasParallelSource returns true if a USFM file of a commit is the book of a translation whose manifest has a source
+func HasParallelSource(commit *git.Commit, treePath string) bool {
+	if !isUsfmFile(treePath) {
+		return false
+	}
+	manifest := readManifest(commit)
+	return GetParallelSource(manifest) != nil && getBookOfFile(manifest, treePath) != nil
+}
+
+// GetParallelView aligns a USFM file of a commit of a repo with the same book in the catalog entry of its source.
+// The refSubURL is the ref of the commit in the URLs of the repo, such as branch/master.
+func GetParallelView(repo *models.Repository, commit *git.Commit, refName, refSubURL, treePath string) (*ParallelView, error) {
+	manifest := readManifest(commit)
+	source := GetParallelSource(manifest)
+	book := getBookOfFile(manifest, treePath)
+	if source == nil || book == nil || !isUsfmFile(treePath) {
+		return nil, git.ErrNotExist{RelPath: treePath}
+	}
+
+	targetContent, err := readFile(commit, treePath)
+	if err != nil {
+		return nil, err
+	}
+	view := &ParallelView{
+		Book: book,
+		Target: &ParallelText{
+			Repo:    repo,
+			Ref:     refName,
+			Path:    treePath,
+			HTMLURL: repo.HTMLURL() + "/src/" + util.PathEscapeSegments(refSubURL) + "/" + util.PathEscapeSegments(treePath),
+		},
+	}
+	view.Target.Language, view.Target.Direction = languageOfManifest(manifest)
+
+	dm, err := GetSourceEntry(source, repo.OwnerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := dm.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	sourceContent, sourceText, err := readSourceBook(dm, book)
+	if err != nil {
+		return nil, err
+	}
+	if sourceText == nil {
+		return nil, ErrNoParallelSource{Source: source.String(), Book: book.ID}
+	}
+	view.Source = sourceText
+
+	view.Verses = AlignVerses(sourceContent, targetContent)
+	return view, nil
+}
+
+// readSourceBook reads the USFM file of a book at the ref of a catalog entry, returning a nil text if it has none
+func readSourceBook(dm *models.Door43Metadata, book *dcs.Book) (string, *ParallelText, error) {
+	gitRepo, err := git.OpenRepository(dm.Repo.RepoPath())
+	if err != nil {
+		return "", nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, refSubURL, err := resolveRef(dm.Repo, gitRepo, dm.BranchOrTag)
+	if err != nil {
+		return "", nil, err
+	}
+	paths, err := bookFiles(commit, dm.Metadata, book)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, p := range paths {
+		if !isUsfmFile(p) {
+			continue
+		}
+		content, err := readFile(commit, p)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				// A project of the manifest without its file
+				continue
+			}
+			return "", nil, err
+		}
+		text := &ParallelText{
+			Repo:    dm.Repo,
+			Ref:     dm.BranchOrTag,
+			Path:    p,
+			HTMLURL: dm.Repo.HTMLURL() + "/src/" + util.PathEscapeSegments(refSubURL) + "/" + util.PathEscapeSegments(p),
+		}
+		text.Language, text.Direction = languageOfManifest(dm.Metadata)
+		return content, text, nil
+	}
+	return "", nil, nil
+}
+
+var verseRefRegex = regexp.MustCompile(`^(\d+)[a-z]?(?:-(\d+)[a-z]?)?$`)
+
+// alignedVerse is a verse or range of verses of the source or the translation
+type alignedVerse struct {
+	chapter, start, end int
+	text                string
+	isSource            bool
+}
+
+// AlignVerses aligns the verses of a source USFM file and those of its translation by chapter and verse.
+// Verses bridged in either of them, e.g. 1:1-2, are aligned with all of the verses they overlap.
+// The text of chapters and books before their first verse, such as their headings, is left out.
+func AlignVerses(source, target string) []*ParallelVerse {
+	var verses []*alignedVerse
+	add := func(usfm string, isSource bool) {
+		refs, texts := dcs.ParseUsfmVerses(usfm)
+		for _, ref := range refs {
+			parts := strings.SplitN(ref, ":", 2)
+			chapter, err := strconv.Atoi(parts[0])
+			if err != nil || len(parts) != 2 {
+				continue
+			}
+			matches := verseRefRegex.FindStringSubmatch(parts[1])
+			if matches == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(matches[1])
+			end := start
+			if matches[2] != "" {
+				end, _ = strconv.Atoi(matches[2])
+			}
+			if start == 0 || end < start {
+				continue
+			}
+			verses = append(verses, &alignedVerse{chapter: chapter, start: start, end: end, text: texts[ref], isSource: isSource})
+		}
+	}
+	add(source, true)
+	add(target, false)
+	sort.SliceStable(verses, func(i, j int) bool {
+		if verses[i].chapter != verses[j].chapter {
+			return verses[i].chapter < verses[j].chapter
+		}
+		return verses[i].start < verses[j].start
+	})
+
+	var aligned []*ParallelVerse
+	var chapter, start, end int
+	var sourceTexts, targetTexts []string
+	flush := func() {
+		if sourceTexts == nil && targetTexts == nil {
+			return
+		}
+		ref := fmt.Sprintf("%d:%d", chapter, start)
+		if end > start {
+			ref = fmt.Sprintf("%d:%d-%d", chapter, start, end)
+		}
+		aligned = append(aligned, &ParallelVerse{
+			Ref:    ref,
+			Source: strings.Join(sourceTexts, " "),
+			Target: strings.Join(targetTexts, " "),
+		})
+		sourceTexts, targetTexts = nil, nil
+	}
+	for _, verse := range verses {
+		if verse.chapter != chapter || verse.start > end {
+			flush()
+			chapter, start, end = verse.chapter, verse.start, verse.end
+		} else if verse.end > end {
+			end = verse.end
+		}
+		if verse.isSource {
+			sourceTexts = append(sourceTexts, verse.text)
+		} else {
+			targetTexts = append(targetTexts, verse.text)
+		}
+	}
+	flush()
+	return aligned
+}
+
+/*** END DCS Customizations ***/
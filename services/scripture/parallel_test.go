@@ -0,0 +1,74 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scripture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetParallelSource(t *testing.T) {
+	manifest := map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"source": []interface{}{
+				map[string]interface{}{"identifier": "ult", "language": "en", "version": 85},
+			},
+		},
+	}
+	source := GetParallelSource(&manifest)
+	if assert.NotNil(t, source) {
+		assert.Equal(t, "en_ult", source.RepoName())
+		assert.Equal(t, "85", source.Version)
+		assert.Equal(t, "en_ult v85", source.String())
+	}
+
+	manifest = map[string]interface{}{
+		"dublin_core": map[string]interface{}{
+			"source": []interface{}{
+				map[string]interface{}{"identifier": "ult", "version": "v85"},
+				map[string]interface{}{"identifier": "ugnt", "language": "el-x-koine", "version": "v0.9"},
+			},
+		},
+	}
+	source = GetParallelSource(&manifest)
+	if assert.NotNil(t, source) {
+		assert.Equal(t, "el-x-koine_ugnt", source.RepoName())
+		assert.Equal(t, "0.9", source.Version)
+	}
+
+	assert.Nil(t, GetParallelSource(&map[string]interface{}{"dublin_core": map[string]interface{}{"source": []interface{}{}}}))
+	assert.Nil(t, GetParallelSource(nil))
+}
+
+func TestGetBookOfFile(t *testing.T) {
+	manifest := map[string]interface{}{
+		"projects": []interface{}{
+			map[string]interface{}{"identifier": "tit", "path": "./titus.usfm"},
+		},
+	}
+	if book := getBookOfFile(&manifest, "titus.usfm"); assert.NotNil(t, book) {
+		assert.Equal(t, "tit", book.ID)
+	}
+	if book := getBookOfFile(&manifest, "57-TIT.usfm"); assert.NotNil(t, book) {
+		assert.Equal(t, "tit", book.ID)
+	}
+	assert.Nil(t, getBookOfFile(&manifest, "README.md"))
+}
+
+func TestAlignVerses(t *testing.T) {
+	source := "\\id TIT\n\\h Titus\n\\c 1\n\\s Greeting\n\\p\n\\v 1 Paul, a servant\n\\v 2 in hope\n\\v 3 at the right time\n\\c 2\n\\v 1 But you\n"
+	target := "\\id TIT\n\\h Tito\n\\c 1\n\\p\n\\v 1-2 Pablo, siervo, en esperanza\n\\v 3 a su debido tiempo\n\\v 4 a Tito\n"
+
+	verses := AlignVerses(source, target)
+	if assert.Len(t, verses, 4) {
+		assert.Equal(t, &ParallelVerse{Ref: "1:1-2", Source: "Paul, a servant in hope", Target: "Pablo, siervo, en esperanza"}, verses[0])
+		assert.Equal(t, &ParallelVerse{Ref: "1:3", Source: "at the right time", Target: "a su debido tiempo"}, verses[1])
+		assert.Equal(t, &ParallelVerse{Ref: "1:4", Target: "a Tito"}, verses[2])
+		assert.Equal(t, &ParallelVerse{Ref: "2:1", Source: "But you"}, verses[3])
+	}
+
+	assert.Empty(t, AlignVerses("", ""))
+}
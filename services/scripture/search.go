@@ -0,0 +1,341 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*** DCS Customizations - Search of repos by Bible reference ***/
+
+package scripture
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/dcs"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// RelatedRepo is a repo that the manifest of another repo relates to, e.g. en_tn by the relation en/tn
+type RelatedRepo struct {
+	Repo     *models.Repository
+	Relation string
+	// Version is the version of the relation, e.g. 5 for en/ult?v=5, if any
+	Version string
+}
+
+// resolveRef returns the commit of a branch, tag or commit ID of a repo, defaulting to its default branch,
+// and the sub-URL of the ref such as branch/master
+func resolveRef(repo *models.Repository, gitRepo *git.Repository, ref string) (*git.Commit, string, error) {
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+	if gitRepo.IsBranchExist(ref) {
+		commit, err := gitRepo.GetBranchCommit(ref)
+		return commit, "branch/" + ref, err
+	}
+	if gitRepo.IsTagExist(ref) {
+		commit, err := gitRepo.GetTagCommit(ref)
+		return commit, "tag/" + ref, err
+	}
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	return commit, "commit/" + commit.ID.String(), nil
+}
+
+// readManifest reads the manifest.yaml file of a commit, returning nil if it has none or it can't be parsed
+func readManifest(commit *git.Commit) *map[string]interface{} {
+	entry, err := commit.GetTreeEntryByPath("manifest.yaml")
+	if err != nil {
+		return nil
+	}
+	manifest, err := base.ReadYAMLFromBlob(entry.Blob())
+	if err != nil {
+		return nil
+	}
+	return manifest
+}
+
+// readFile reads a file of a commit
+func readFile(commit *git.Commit, treePath string) (string, error) {
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		return "", err
+	}
+	dataRc, err := entry.Blob().DataAsync()
+	if err != nil {
+		return "", err
+	}
+	defer dataRc.Close()
+	data, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// bookFiles returns the paths of the USFM and TSV files of a book in a commit: those of the projects of the
+// manifest for the book, else those of the files of the root folder named after the book
+func bookFiles(commit *git.Commit, manifest *map[string]interface{}, book *dcs.Book) ([]string, error) {
+	var paths []string
+	isBookFile := func(name string) bool {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".usfm", ".usfm3", ".sfm", ".tsv":
+			return true
+		}
+		return false
+	}
+
+	if manifest != nil {
+		projects, _ := (*manifest)["projects"].([]interface{})
+		for _, p := range projects {
+			project, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			identifier, _ := project["identifier"].(string)
+			projectPath, _ := project["path"].(string)
+			projectPath = path.Clean(projectPath)
+			if strings.EqualFold(identifier, book.ID) && isBookFile(projectPath) {
+				paths = append(paths, projectPath)
+			}
+		}
+		if len(paths) > 0 {
+			return paths, nil
+		}
+	}
+
+	entries, err := commit.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsRegular() && isBookFile(entry.Name()) && dcs.GetBookFromFileName(entry.Name()) == book.ID {
+			paths = append(paths, entry.Name())
+		}
+	}
+	return paths, nil
+}
+
+// searchUsfm returns the verses of a USFM file at the reference, without their markup
+func searchUsfm(content string, reference *dcs.Reference) []*api.ReferenceVerse {
+	refs, verses := dcs.ParseUsfmVerses(content)
+	var found []*api.ReferenceVerse
+	for _, ref := range refs {
+		if reference.MatchesRef(ref) {
+			found = append(found, &api.ReferenceVerse{Ref: ref, Text: verses[ref]})
+		}
+	}
+	return found
+}
+
+// searchTsv returns the rows of a TSV file at the reference. The rows have either a Reference column,
+// such as 1:3, or Chapter and Verse columns, and may have a Book column.
+func searchTsv(content string, reference *dcs.Reference) *api.ReferenceTable {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if len(lines) == 0 {
+		return nil
+	}
+	table := &api.ReferenceTable{Header: strings.Split(lines[0], "\t")}
+	column := func(name string) int {
+		for i, header := range table.Header {
+			if strings.EqualFold(strings.TrimSpace(header), name) {
+				return i
+			}
+		}
+		return -1
+	}
+	bookColumn, refColumn, chapterColumn, verseColumn := column("Book"), column("Reference"), column("Chapter"), column("Verse")
+	if refColumn < 0 && (chapterColumn < 0 || verseColumn < 0) {
+		return nil
+	}
+
+	for i, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		values := strings.Split(line, "\t")
+		value := func(column int) string {
+			if column < len(values) {
+				return strings.TrimSpace(values[column])
+			}
+			return ""
+		}
+		if bookColumn >= 0 && !strings.EqualFold(value(bookColumn), reference.Book.ID) {
+			continue
+		}
+		if (refColumn >= 0 && reference.MatchesRef(value(refColumn))) ||
+			(refColumn < 0 && reference.MatchesVerse(value(chapterColumn), value(verseColumn))) {
+			table.Rows = append(table.Rows, &api.ReferenceTableRow{Line: i + 2, Values: values})
+		}
+	}
+	return table
+}
+
+// SearchCommit searches the USFM and TSV files of the book of a reference in a commit of a repo.
+// The refSubURL is the ref of the commit in the URLs of the repo, such as branch/master.
+func SearchCommit(repo *models.Repository, commit *git.Commit, refName, refSubURL string, reference *dcs.Reference) (*api.ReferenceRepoMatches, error) {
+	matches := &api.ReferenceRepoMatches{
+		FullName: repo.FullName(),
+		Ref:      refName,
+		HTMLURL:  repo.HTMLURL() + "/src/" + util.PathEscapeSegments(refSubURL),
+		Verses:   []*api.ReferenceVerse{},
+		Tables:   []*api.ReferenceTable{},
+	}
+
+	paths, err := bookFiles(commit, readManifest(commit), reference.Book)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		content, err := readFile(commit, p)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				// A project of the manifest without its file
+				continue
+			}
+			return nil, err
+		}
+		fileURL := matches.HTMLURL + "/" + util.PathEscapeSegments(p)
+		if strings.ToLower(path.Ext(p)) == ".tsv" {
+			table := searchTsv(content, reference)
+			if table == nil || len(table.Rows) == 0 {
+				continue
+			}
+			table.Path = p
+			table.HTMLURL = fileURL
+			for _, row := range table.Rows {
+				row.HTMLURL = fmt.Sprintf("%s#L%d", fileURL, row.Line)
+			}
+			matches.Tables = append(matches.Tables, table)
+			continue
+		}
+		for _, verse := range searchUsfm(content, reference) {
+			verse.Path = p
+			verse.HTMLURL = fileURL
+			matches.Verses = append(matches.Verses, verse)
+		}
+	}
+	return matches, nil
+}
+
+// GetRelatedRepos returns the repos of the same owner that the relations of the manifest of a commit relate to,
+// e.g. en_tn for the relation en/tn
+func GetRelatedRepos(repo *models.Repository, commit *git.Commit) ([]*RelatedRepo, error) {
+	manifest := readManifest(commit)
+	if manifest == nil {
+		return nil, nil
+	}
+	dublinCore, _ := (*manifest)["dublin_core"].(map[string]interface{})
+	relations, _ := dublinCore["relation"].([]interface{})
+
+	var related []*RelatedRepo
+	seen := map[string]bool{strings.ToLower(repo.Name): true}
+	for _, r := range relations {
+		relation, _ := r.(string)
+		resource, query := relation, ""
+		if i := strings.Index(relation, "?"); i >= 0 {
+			resource, query = relation[:i], relation[i+1:]
+		}
+		parts := strings.Split(strings.TrimSpace(resource), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+		name := parts[0] + "_" + parts[1]
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		relatedRepo, err := models.GetRepositoryByOwnerAndName(repo.OwnerName, name)
+		if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		version := ""
+		for _, param := range strings.Split(query, "&") {
+			if strings.HasPrefix(param, "v=") {
+				version = strings.TrimPrefix(param, "v=")
+			}
+		}
+		related = append(related, &RelatedRepo{Repo: relatedRepo, Relation: relation, Version: version})
+	}
+	return related, nil
+}
+
+// Search searches a branch, tag or commit of a repo for a reference and, if withRelated, the related repos
+// of its manifest that the doer can read, at the tag of the version of their relation if it exists
+// or else at their default branch
+func Search(doer *models.User, repo *models.Repository, gitRepo *git.Repository, ref string, reference *dcs.Reference, withRelated bool) (*api.ReferenceSearchResult, error) {
+	commit, refSubURL, err := resolveRef(repo, gitRepo, ref)
+	if err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+	matches, err := SearchCommit(repo, commit, ref, refSubURL, reference)
+	if err != nil {
+		return nil, err
+	}
+	result := &api.ReferenceSearchResult{Reference: reference.String(), Repos: []*api.ReferenceRepoMatches{matches}}
+	if !withRelated {
+		return result, nil
+	}
+
+	related, err := GetRelatedRepos(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range related {
+		perm, err := models.GetUserRepoPermission(r.Repo, doer)
+		if err != nil {
+			return nil, err
+		}
+		if !perm.CanRead(models.UnitTypeCode) || r.Repo.IsEmpty {
+			continue
+		}
+		matches, err := searchRelatedRepo(r, reference)
+		if err != nil {
+			// The repo and its relations are fine, only the related resource is missing
+			log.Warn("Unable to search %s for %s: %v", r.Repo.FullName(), reference, err)
+			continue
+		}
+		result.Repos = append(result.Repos, matches)
+	}
+	return result, nil
+}
+
+func searchRelatedRepo(related *RelatedRepo, reference *dcs.Reference) (*api.ReferenceRepoMatches, error) {
+	gitRepo, err := git.OpenRepository(related.Repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	ref := related.Repo.DefaultBranch
+	if related.Version != "" && gitRepo.IsTagExist("v"+related.Version) {
+		ref = "v" + related.Version
+	}
+	commit, refSubURL, err := resolveRef(related.Repo, gitRepo, ref)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := SearchCommit(related.Repo, commit, ref, refSubURL, reference)
+	if err != nil {
+		return nil, err
+	}
+	matches.Relation = related.Relation
+	return matches, nil
+}
+
+/*** END DCS Customizations ***/
@@ -0,0 +1,44 @@
+// Copyright 2021 unfoldingWord. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scripture
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/dcs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTsv(t *testing.T) {
+	reference, err := dcs.ParseReference("TIT 1:3")
+	assert.NoError(t, err)
+
+	table := searchTsv("Reference\tID\tNote\n1:1\tabcd\tone\n1:2-3\tefgh\ttwo\n1:3\tijkl\tthree\n2:3\tmnop\tfour\n", reference)
+	if assert.NotNil(t, table) && assert.Len(t, table.Rows, 2) {
+		assert.Equal(t, 3, table.Rows[0].Line)
+		assert.Equal(t, "efgh", table.Rows[0].Values[1])
+		assert.Equal(t, 4, table.Rows[1].Line)
+	}
+
+	table = searchTsv("Book\tChapter\tVerse\tNote\nTIT\t1\t3\tone\nTIT\t1\t4\ttwo\nPHM\t1\t3\tthree\n", reference)
+	if assert.NotNil(t, table) && assert.Len(t, table.Rows, 1) {
+		assert.Equal(t, "one", table.Rows[0].Values[3])
+	}
+
+	assert.Nil(t, searchTsv("ID\tNote\nabcd\tone\n", reference))
+}
+
+func TestSearchUsfm(t *testing.T) {
+	reference, err := dcs.ParseReference("Titus 1:2-3")
+	assert.NoError(t, err)
+
+	verses := searchUsfm("\\id TIT\n\\c 1\n\\p\n\\v 1 Paul\n\\v 2 in hope\n\\v 3 at the right time\n\\v 4 to Titus\n", reference)
+	if assert.Len(t, verses, 2) {
+		assert.Equal(t, "1:2", verses[0].Ref)
+		assert.Equal(t, "in hope", verses[0].Text)
+		assert.Equal(t, "1:3", verses[1].Ref)
+	}
+}